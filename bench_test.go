@@ -46,6 +46,48 @@ func BenchmarkAddUnsafe(b *testing.B) {
 	benchAdd(b, NewThreadUnsafeSet())
 }
 
+func benchAddAll(b *testing.B, s Set) {
+	nums := toInterfaces(nrand(b.N))
+	b.ResetTimer()
+	s.AddAll(nums...)
+}
+
+func BenchmarkAddAllSafe(b *testing.B) {
+	benchAddAll(b, NewSet())
+}
+
+func BenchmarkAddAllUnsafe(b *testing.B) {
+	benchAddAll(b, NewThreadUnsafeSet())
+}
+
+func benchLoad(b *testing.B, newSet func(size int) Set) {
+	nums := nrand(100000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := newSet(len(nums))
+		for _, v := range nums {
+			s.Add(v)
+		}
+	}
+}
+
+func BenchmarkLoadSafe(b *testing.B) {
+	benchLoad(b, func(int) Set { return NewSet() })
+}
+
+func BenchmarkLoadSafeWithSize(b *testing.B) {
+	benchLoad(b, NewThreadSafeSetWithSize)
+}
+
+func BenchmarkLoadUnsafe(b *testing.B) {
+	benchLoad(b, func(int) Set { return NewThreadUnsafeSet() })
+}
+
+func BenchmarkLoadUnsafeWithSize(b *testing.B) {
+	benchLoad(b, NewThreadUnsafeSetWithSize)
+}
+
 func benchRemove(b *testing.B, s Set) {
 	nums := nrand(b.N)
 	for _, v := range nums {
@@ -131,6 +173,58 @@ func BenchmarkClone100Unsafe(b *testing.B) {
 	benchClone(b, 100, NewThreadUnsafeSet())
 }
 
+func benchSnapshot(b *testing.B, n int, s Set) {
+	nums := nrand(n)
+	for _, v := range nums {
+		s.Add(v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Snapshot()
+	}
+}
+
+func BenchmarkSnapshot100Safe(b *testing.B) {
+	benchSnapshot(b, 100, NewSet())
+}
+
+func BenchmarkSnapshot100Unsafe(b *testing.B) {
+	benchSnapshot(b, 100, NewThreadUnsafeSet())
+}
+
+func BenchmarkToSliceRebuild100Safe(b *testing.B) {
+	s := NewSet()
+	for _, v := range nrand(100) {
+		s.Add(v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewSetFromSlice(s.ToSlice())
+	}
+}
+
+func benchCopyTo(b *testing.B, n int, s, dest Set) {
+	nums := nrand(n)
+	for _, v := range nums {
+		s.Add(v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.CopyTo(dest)
+	}
+}
+
+func BenchmarkCopyTo100Safe(b *testing.B) {
+	benchCopyTo(b, 100, NewSet(), NewSet())
+}
+
+func BenchmarkCopyTo100Unsafe(b *testing.B) {
+	benchCopyTo(b, 100, NewThreadUnsafeSet(), NewThreadUnsafeSet())
+}
+
 func benchContains(b *testing.B, n int, s Set) {
 	nums := toInterfaces(nrand(n))
 	for _, v := range nums {
@@ -169,6 +263,43 @@ func BenchmarkContains100Unsafe(b *testing.B) {
 	benchContains(b, 100, NewThreadUnsafeSet())
 }
 
+// benchMembership checks every one of 100 elements in a tight loop, with
+// Has or with Contains on a single element.
+func benchMembership(b *testing.B, s Set, has bool) {
+	nums := toInterfaces(nrand(100))
+	for _, v := range nums {
+		s.Add(v)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, v := range nums {
+			if has {
+				s.Has(v)
+			} else {
+				s.Contains(v)
+			}
+		}
+	}
+}
+
+func BenchmarkHasSafe(b *testing.B) {
+	benchMembership(b, NewSet(), true)
+}
+
+func BenchmarkHasUnsafe(b *testing.B) {
+	benchMembership(b, NewThreadUnsafeSet(), true)
+}
+
+func BenchmarkContainsOneSafe(b *testing.B) {
+	benchMembership(b, NewSet(), false)
+}
+
+func BenchmarkContainsOneUnsafe(b *testing.B) {
+	benchMembership(b, NewThreadUnsafeSet(), false)
+}
+
 func benchEqual(b *testing.B, n int, s, t Set) {
 	nums := nrand(n)
 	for _, v := range nums {
@@ -587,6 +718,82 @@ func BenchmarkIter100Unsafe(b *testing.B) {
 	benchIter(b, 100, NewThreadUnsafeSet())
 }
 
+func benchElements(b *testing.B, n int, s Set) {
+	nums := nrand(n)
+	for _, v := range nums {
+		s.Add(v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range s.Elements() {
+
+		}
+	}
+}
+
+func BenchmarkElements1Safe(b *testing.B) {
+	benchElements(b, 1, NewSet())
+}
+
+func BenchmarkElements1Unsafe(b *testing.B) {
+	benchElements(b, 1, NewThreadUnsafeSet())
+}
+
+func BenchmarkElements10Safe(b *testing.B) {
+	benchElements(b, 10, NewSet())
+}
+
+func BenchmarkElements10Unsafe(b *testing.B) {
+	benchElements(b, 10, NewThreadUnsafeSet())
+}
+
+// iterUnbuffered reproduces Iter as it was before its channel was
+// buffered, to measure what the buffer saves.
+func iterUnbuffered(s Set) <-chan interface{} {
+	ch := make(chan interface{})
+	go func() {
+		s.Each(func(elem interface{}) bool {
+			ch <- elem
+			return false
+		})
+		close(ch)
+	}()
+
+	return ch
+}
+
+func benchIterUnbuffered(b *testing.B, n int, s Set) {
+	nums := nrand(n)
+	for _, v := range nums {
+		s.Add(v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := iterUnbuffered(s)
+		for range c {
+
+		}
+	}
+}
+
+func BenchmarkIter100kSafe(b *testing.B) {
+	benchIter(b, 100000, NewSet())
+}
+
+func BenchmarkIter100kUnbufferedSafe(b *testing.B) {
+	benchIterUnbuffered(b, 100000, NewSet())
+}
+
+func BenchmarkIter100kUnsafe(b *testing.B) {
+	benchIter(b, 100000, NewThreadUnsafeSet())
+}
+
+func BenchmarkIter100kUnbufferedUnsafe(b *testing.B) {
+	benchIterUnbuffered(b, 100000, NewThreadUnsafeSet())
+}
+
 func benchIterator(b *testing.B, n int, s Set) {
 	nums := nrand(n)
 	for _, v := range nums {
@@ -681,3 +888,117 @@ func BenchmarkToSliceSafe(b *testing.B) {
 func BenchmarkToSliceUnsafe(b *testing.B) {
 	benchToSlice(b, NewThreadUnsafeSet())
 }
+
+func benchContended(b *testing.B, s Set) {
+	// At least 16 goroutines on any machine.
+	b.SetParallelism(16)
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			v := r.Intn(1 << 16)
+			if v%4 == 0 {
+				s.Add(v)
+			} else {
+				s.Contains(v)
+			}
+		}
+	})
+}
+
+func BenchmarkContendedSafe(b *testing.B) {
+	benchContended(b, NewSet())
+}
+
+func BenchmarkContendedSharded(b *testing.B) {
+	benchContended(b, NewShardedSet(32))
+}
+
+func benchPowerSet(b *testing.B, n int, s Set) {
+	for _, v := range nrand(n) {
+		s.Add(v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.PowerSet()
+	}
+}
+
+func BenchmarkPowerSet16Safe(b *testing.B) {
+	benchPowerSet(b, 16, NewSet())
+}
+
+func BenchmarkPowerSet16Unsafe(b *testing.B) {
+	benchPowerSet(b, 16, NewThreadUnsafeSet())
+}
+
+func BenchmarkLoad1MSafe(b *testing.B) {
+	nums := nrand(1000000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := NewSet()
+		for _, v := range nums {
+			s.Add(v)
+		}
+	}
+}
+
+func BenchmarkLoad1MIntSet(b *testing.B) {
+	nums := nrand(1000000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := NewIntSet()
+		for _, v := range nums {
+			s.Add(v)
+		}
+	}
+}
+
+func BenchmarkLoad1MBitSet(b *testing.B) {
+	nums := rand.Perm(1000000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := NewBitSet(len(nums) - 1)
+		for _, v := range nums {
+			s.Add(v)
+		}
+	}
+}
+
+func BenchmarkLoad1MDenseIntSet(b *testing.B) {
+	nums := rand.Perm(1000000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := NewIntSet()
+		for _, v := range nums {
+			s.Add(v)
+		}
+	}
+}
+
+func benchDenseIntersect(b *testing.B, newSet func() IntSet) {
+	x, y := newSet(), newSet()
+	for _, v := range rand.Perm(100000)[:50000] {
+		x.Add(v)
+	}
+	for _, v := range rand.Perm(100000)[:50000] {
+		y.Add(v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Intersect(y)
+	}
+}
+
+func BenchmarkIntersect100kBitSet(b *testing.B) {
+	benchDenseIntersect(b, func() IntSet { return NewBitSet(99999) })
+}
+
+func BenchmarkIntersect100kIntSet(b *testing.B) {
+	benchDenseIntersect(b, func() IntSet { return NewIntSet() })
+}
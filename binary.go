@@ -0,0 +1,263 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// The binary form of a set, written by MarshalBinary, is the number of
+// elements as an unsigned varint followed by every element as a one byte
+// type tag and its value:
+//
+//   - nil has no value, a bool is one byte, 0 or 1;
+//   - signed integers are varints and unsigned integers unsigned varints,
+//     whatever their size;
+//   - float32 and float64 are their IEEE 754 bits as 4 and 8 little
+//     endian bytes;
+//   - a string is its length as an unsigned varint followed by its bytes;
+//   - any other element is gob encoded, prefixed by the length of the
+//     encoding as an unsigned varint. As with GobEncode, such types must
+//     be registered with gob.Register.
+//
+// Every builtin element type is preserved and encoded more compactly than
+// in the JSON form.
+const (
+	binaryNil byte = iota
+	binaryBool
+	binaryInt
+	binaryInt8
+	binaryInt16
+	binaryInt32
+	binaryInt64
+	binaryUint
+	binaryUint8
+	binaryUint16
+	binaryUint32
+	binaryUint64
+	binaryFloat32
+	binaryFloat64
+	binaryString
+	binaryGob
+)
+
+func marshalBinaryElements(items []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	scratch := make([]byte, binary.MaxVarintLen64)
+	putUvarint := func(v uint64) {
+		buf.Write(scratch[:binary.PutUvarint(scratch, v)])
+	}
+	putVarint := func(v int64) {
+		buf.Write(scratch[:binary.PutVarint(scratch, v)])
+	}
+
+	putUvarint(uint64(len(items)))
+	for _, item := range items {
+		switch v := item.(type) {
+		case nil:
+			buf.WriteByte(binaryNil)
+		case bool:
+			buf.WriteByte(binaryBool)
+			if v {
+				buf.WriteByte(1)
+			} else {
+				buf.WriteByte(0)
+			}
+		case int:
+			buf.WriteByte(binaryInt)
+			putVarint(int64(v))
+		case int8:
+			buf.WriteByte(binaryInt8)
+			putVarint(int64(v))
+		case int16:
+			buf.WriteByte(binaryInt16)
+			putVarint(int64(v))
+		case int32:
+			buf.WriteByte(binaryInt32)
+			putVarint(int64(v))
+		case int64:
+			buf.WriteByte(binaryInt64)
+			putVarint(v)
+		case uint:
+			buf.WriteByte(binaryUint)
+			putUvarint(uint64(v))
+		case uint8:
+			buf.WriteByte(binaryUint8)
+			putUvarint(uint64(v))
+		case uint16:
+			buf.WriteByte(binaryUint16)
+			putUvarint(uint64(v))
+		case uint32:
+			buf.WriteByte(binaryUint32)
+			putUvarint(uint64(v))
+		case uint64:
+			buf.WriteByte(binaryUint64)
+			putUvarint(v)
+		case float32:
+			buf.WriteByte(binaryFloat32)
+			binary.LittleEndian.PutUint32(scratch, math.Float32bits(v))
+			buf.Write(scratch[:4])
+		case float64:
+			buf.WriteByte(binaryFloat64)
+			binary.LittleEndian.PutUint64(scratch, math.Float64bits(v))
+			buf.Write(scratch[:8])
+		case string:
+			buf.WriteByte(binaryString)
+			putUvarint(uint64(len(v)))
+			buf.WriteString(v)
+		default:
+			b, err := gobEncodeElements([]interface{}{item})
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteByte(binaryGob)
+			putUvarint(uint64(len(b)))
+			buf.Write(b)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func unmarshalBinaryElements(data []byte) ([]interface{}, error) {
+	r := bytes.NewReader(data)
+	fail := func(err error) ([]interface{}, error) {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("mapset: invalid binary set at offset %d: %v", len(data)-r.Len(), err)
+	}
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fail(err)
+	}
+	if n > uint64(len(data)) {
+		return fail(fmt.Errorf("%d elements do not fit in %d bytes", n, len(data)))
+	}
+
+	items := make([]interface{}, 0, n)
+	for i := uint64(0); i < n; i++ {
+		item, err := readBinaryElement(r)
+		if err != nil {
+			return fail(err)
+		}
+		items = append(items, item)
+	}
+
+	if r.Len() != 0 {
+		return fail(fmt.Errorf("%d trailing bytes", r.Len()))
+	}
+
+	return items, nil
+}
+
+// readBinaryElement reads a type tag and the value following it.
+func readBinaryElement(r *bytes.Reader) (interface{}, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case binaryNil:
+		return nil, nil
+	case binaryBool:
+		b, err := r.ReadByte()
+		return b != 0, err
+	case binaryInt, binaryInt8, binaryInt16, binaryInt32, binaryInt64:
+		v, err := binary.ReadVarint(r)
+		switch tag {
+		case binaryInt:
+			return int(v), err
+		case binaryInt8:
+			return int8(v), err
+		case binaryInt16:
+			return int16(v), err
+		case binaryInt32:
+			return int32(v), err
+		}
+		return v, err
+	case binaryUint, binaryUint8, binaryUint16, binaryUint32, binaryUint64:
+		v, err := binary.ReadUvarint(r)
+		switch tag {
+		case binaryUint:
+			return uint(v), err
+		case binaryUint8:
+			return uint8(v), err
+		case binaryUint16:
+			return uint16(v), err
+		case binaryUint32:
+			return uint32(v), err
+		}
+		return v, err
+	case binaryFloat32:
+		var v uint32
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return math.Float32frombits(v), err
+	case binaryFloat64:
+		var v uint64
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return math.Float64frombits(v), err
+	case binaryString:
+		b, err := readBinaryBytes(r)
+		return string(b), err
+	case binaryGob:
+		b, err := readBinaryBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		items, err := gobDecodeElements(b)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) != 1 {
+			return nil, fmt.Errorf("gob element holds %d values", len(items))
+		}
+		return items[0], nil
+	}
+
+	return nil, fmt.Errorf("unknown type tag %d", tag)
+}
+
+// readBinaryBytes reads a length prefixed byte slice.
+func readBinaryBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
@@ -0,0 +1,101 @@
+package mapset
+
+import (
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+type binaryPoint struct {
+	X, Y int
+}
+
+func Test_Binary(t *testing.T) {
+	gob.Register(binaryPoint{})
+
+	constructors := map[string]func(...interface{}) Set{
+		"bounded": func(i ...interface{}) Set { s := NewBoundedSet(100); s.AddAll(i...); return s },
+		"lru":     func(i ...interface{}) Set { s := NewLRUSet(100); s.AddAll(i...); return s },
+	}
+	for name, newSet := range setConstructors {
+		constructors[name] = newSet
+	}
+
+	for name, newSet := range constructors {
+		for _, items := range [][]interface{}{
+			{},
+			{1, -2, 300000, math.MaxInt64, math.MinInt64},
+			{"", "a", "héllo", "a longer string"},
+			{binaryPoint{1, 2}, binaryPoint{-3, 4}},
+			{nil, true, false, int8(-8), int16(16), int32(-32), int64(64), uint(1), uint8(8),
+				uint16(16), uint32(32), uint64(math.MaxUint64), float32(1.5), 2.25, "2.25",
+				binaryPoint{5, 6}},
+		} {
+			expected := newSet(items...)
+			b, err := expected.(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				t.Fatalf("%s: MarshalBinary(%v): %v", name, items, err)
+			}
+
+			actual := newSet()
+			if err := actual.(encoding.BinaryUnmarshaler).UnmarshalBinary(b); err != nil {
+				t.Fatalf("%s: UnmarshalBinary(%v): %v", name, items, err)
+			}
+			if !actual.Equal(expected) {
+				t.Errorf("%s: expected %v, got %v", name, expected, actual)
+			}
+		}
+	}
+
+	frozen := NewSet(1, "a").Freeze()
+	b, err := frozen.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := NewSet()
+	if err := actual.(encoding.BinaryUnmarshaler).UnmarshalBinary(b); err != nil || !actual.Equal(frozen) {
+		t.Errorf("a frozen set should round-trip, got %v and error %v", actual, err)
+	}
+}
+
+func Test_BinaryIsCompact(t *testing.T) {
+	s := NewSet()
+	for i := 0; i < 100; i++ {
+		s.Add(i)
+		s.Add(string(rune('a' + i%26)))
+	}
+
+	b, err := s.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) >= len(j) {
+		t.Errorf("the binary form should be smaller than the JSON form, got %d and %d bytes", len(b), len(j))
+	}
+}
+
+func Test_UnmarshalBinaryInvalid(t *testing.T) {
+	valid, err := NewSet("abc", 1).(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range [][]byte{
+		nil,
+		valid[:len(valid)-1],
+		append(append([]byte{}, valid...), 0),
+		{1, 0xff},
+		{200},
+	} {
+		s := NewSet()
+		if err := s.(encoding.BinaryUnmarshaler).UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary(%v) should fail, got %v", data, s)
+		}
+	}
+}
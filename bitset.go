@@ -0,0 +1,504 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// bitSet is a thread-safe IntSet holding ints from 0 to max as the bits
+// of words, bit i%64 of word i/64 being set when i is in the set. count
+// caches the number of set bits.
+type bitSet struct {
+	words []uint64
+	max   int
+	count int
+	mutex sync.RWMutex
+}
+
+// NewBitSet creates and returns a reference to an empty IntSet holding
+// ints from 0 to maxValue, backed by a bitmap of maxValue/64+1 words in
+// place of a map. It takes a bit per possible element rather than tens
+// of bytes per element, which makes it far more compact and cache
+// friendly than NewIntSet as long as the elements are dense. Add,
+// Contains and Remove take constant time, and binary operations with
+// another bit set work a word, or 64 elements, at a time. Operations on
+// the resulting set are thread-safe.
+//
+// Ints outside of the range of the set are never added: Add returns
+// false for them, AddAll does not count them and Contains reports them as
+// missing. Binary operations accept any IntSet: Union and
+// SymmetricDifference with a set that isn't a bit set return a set backed
+// by a map, as NewIntSet does, since their result may hold ints outside
+// of the range; their other results are bit sets. A negative maxValue is
+// treated as 0.
+func NewBitSet(maxValue int) IntSet {
+	if maxValue < 0 {
+		maxValue = 0
+	}
+	return newBitSet(maxValue)
+}
+
+// MaxBitSetFromSetValue is the largest element NewBitSetFromSet accepts.
+// The size of a bit set follows from its largest element, so a single
+// large one would otherwise allocate a bitmap of that many bits, 2 MiB at
+// this limit; sparse sets of larger ints are better held by NewIntSet.
+const MaxBitSetFromSetValue = 1<<24 - 1
+
+// NewBitSetFromSet creates and returns a reference to a bit set, see
+// NewBitSet, holding the elements of set, which must all be ints from 0
+// to MaxBitSetFromSetValue; its maxValue is the largest of them. It
+// returns a *TypeMismatchError if an element is not an int, and an error
+// if one is out of that range. Every element is unboxed from its interface{} with a type
+// assertion, which does not allocate, so the conversion is a single pass
+// over set; the inverse conversion, ToSet, boxes every element again.
+func NewBitSetFromSet(set Set) (IntSet, error) {
+	items := set.ToSlice()
+
+	max := 0
+	for _, item := range items {
+		i, ok := item.(int)
+		if !ok {
+			return nil, newTypeMismatchError("NewBitSetFromSet", "an int element", item)
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("mapset: NewBitSetFromSet got the negative element %d", i)
+		}
+		if i > MaxBitSetFromSetValue {
+			return nil, fmt.Errorf("mapset: NewBitSetFromSet got the element %d, above MaxBitSetFromSetValue", i)
+		}
+		if i > max {
+			max = i
+		}
+	}
+
+	converted := newBitSet(max)
+	for _, item := range items {
+		converted.insert(item.(int))
+	}
+	return converted, nil
+}
+
+func newBitSet(max int) *bitSet {
+	return &bitSet{words: make([]uint64, max/64+1), max: max}
+}
+
+// The following helpers do not lock, callers must hold the lock. insert
+// returns false for ints outside of the range, without adding them.
+
+func (set *bitSet) has(i int) bool {
+	return i >= 0 && i <= set.max && set.words[i/64]&(1<<uint(i%64)) != 0
+}
+
+func (set *bitSet) insert(i int) bool {
+	if i < 0 || i > set.max || set.has(i) {
+		return false
+	}
+	set.words[i/64] |= 1 << uint(i%64)
+	set.count++
+	return true
+}
+
+func (set *bitSet) remove(i int) bool {
+	if !set.has(i) {
+		return false
+	}
+	set.words[i/64] &^= 1 << uint(i%64)
+	set.count--
+	return true
+}
+
+func (set *bitSet) each(callback func(int) bool) {
+	for w, word := range set.words {
+		for word != 0 {
+			if callback(w*64 + bits.TrailingZeros64(word)) {
+				return
+			}
+			word &= word - 1
+		}
+	}
+}
+
+// recount sets count from the words, after they were combined directly.
+func (set *bitSet) recount() *bitSet {
+	set.count = 0
+	for _, word := range set.words {
+		set.count += bits.OnesCount64(word)
+	}
+	return set
+}
+
+// isSubset reports whether every element of set is in other.
+func (set *bitSet) isSubset(other *bitSet) bool {
+	if set.count > other.count {
+		return false
+	}
+	for w, word := range set.words {
+		var otherWord uint64
+		if w < len(other.words) {
+			otherWord = other.words[w]
+		}
+		if word&^otherWord != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// rlockWith read-locks the set and other in the order used by rlockAll,
+// and returns a func releasing them.
+func (set *bitSet) rlockWith(other *bitSet) func() {
+	return rlockAll(&set.mutex, &other.mutex)
+}
+
+// combine returns a new bit set of the given max whose words are
+// f(a, b) of the words of set and other, missing words being 0. Both
+// sets must be read-locked.
+func (set *bitSet) combine(other *bitSet, max int, f func(a, b uint64) uint64) *bitSet {
+	result := newBitSet(max)
+	for w := range result.words {
+		var a, b uint64
+		if w < len(set.words) {
+			a = set.words[w]
+		}
+		if w < len(other.words) {
+			b = other.words[w]
+		}
+		result.words[w] = f(a, b)
+	}
+	return result.recount()
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (set *bitSet) Add(i int) bool {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.insert(i)
+}
+
+func (set *bitSet) AddAll(i ...int) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	added := 0
+	for _, item := range i {
+		if set.insert(item) {
+			added++
+		}
+	}
+	return added
+}
+
+func (set *bitSet) Cardinality() int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.count
+}
+
+func (set *bitSet) Clear() {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	for w := range set.words {
+		set.words[w] = 0
+	}
+	set.count = 0
+}
+
+func (set *bitSet) Clone() IntSet {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	clone := newBitSet(set.max)
+	copy(clone.words, set.words)
+	clone.count = set.count
+	return clone
+}
+
+func (set *bitSet) Contains(i ...int) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	for _, item := range i {
+		if !set.has(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *bitSet) Difference(other IntSet) IntSet {
+	if o, ok := other.(*bitSet); ok {
+		unlock := set.rlockWith(o)
+		defer unlock()
+
+		return set.combine(o, set.max, func(a, b uint64) uint64 { return a &^ b })
+	}
+
+	difference := set.Clone().(*bitSet)
+	for _, item := range other.ToSlice() {
+		difference.remove(item)
+	}
+	return difference
+}
+
+func (set *bitSet) Equal(other IntSet) bool {
+	if o, ok := other.(*bitSet); ok {
+		unlock := set.rlockWith(o)
+		defer unlock()
+
+		return set.count == o.count && set.isSubset(o)
+	}
+
+	items := other.ToSlice()
+	return set.Cardinality() == len(items) && set.Contains(items...)
+}
+
+func (set *bitSet) Intersect(other IntSet) IntSet {
+	if o, ok := other.(*bitSet); ok {
+		unlock := set.rlockWith(o)
+		defer unlock()
+
+		return set.combine(o, minInt(set.max, o.max), func(a, b uint64) uint64 { return a & b })
+	}
+
+	items := other.ToSlice()
+
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	intersection := newBitSet(set.max)
+	for _, item := range items {
+		if set.has(item) {
+			intersection.insert(item)
+		}
+	}
+	return intersection
+}
+
+func (set *bitSet) IsProperSubset(other IntSet) bool {
+	if o, ok := other.(*bitSet); ok {
+		unlock := set.rlockWith(o)
+		defer unlock()
+
+		return set.count < o.count && set.isSubset(o)
+	}
+
+	return set.Cardinality() < other.Cardinality() && set.IsSubset(other)
+}
+
+func (set *bitSet) IsProperSuperset(other IntSet) bool {
+	return other.IsProperSubset(set)
+}
+
+func (set *bitSet) IsSubset(other IntSet) bool {
+	if o, ok := other.(*bitSet); ok {
+		unlock := set.rlockWith(o)
+		defer unlock()
+
+		return set.isSubset(o)
+	}
+
+	return other.Contains(set.ToSlice()...)
+}
+
+func (set *bitSet) IsSuperset(other IntSet) bool {
+	return other.IsSubset(set)
+}
+
+func (set *bitSet) Each(callback func(int) bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	set.each(callback)
+}
+
+func (set *bitSet) Remove(i int) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	set.remove(i)
+}
+
+func (set *bitSet) RemoveAll(i ...int) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	removed := 0
+	for _, item := range i {
+		if set.remove(item) {
+			removed++
+		}
+	}
+	return removed
+}
+
+func (set *bitSet) String() string {
+	items := make([]string, 0, set.Cardinality())
+	set.Each(func(elem int) bool {
+		items = append(items, strconv.Itoa(elem))
+		return false
+	})
+
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+func (set *bitSet) SymmetricDifference(other IntSet) IntSet {
+	if o, ok := other.(*bitSet); ok {
+		unlock := set.rlockWith(o)
+		defer unlock()
+
+		return set.combine(o, maxInt(set.max, o.max), func(a, b uint64) uint64 { return a ^ b })
+	}
+
+	return NewIntSet(set.ToSlice()...).SymmetricDifference(other)
+}
+
+func (set *bitSet) Union(other IntSet) IntSet {
+	if o, ok := other.(*bitSet); ok {
+		unlock := set.rlockWith(o)
+		defer unlock()
+
+		return set.combine(o, maxInt(set.max, o.max), func(a, b uint64) uint64 { return a | b })
+	}
+
+	return NewIntSet(set.ToSlice()...).Union(other)
+}
+
+func (set *bitSet) Pop() (int, bool) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	elem, found := 0, false
+	set.each(func(i int) bool {
+		elem, found = i, true
+		return true
+	})
+	if found {
+		set.remove(elem)
+	}
+	return elem, found
+}
+
+func (set *bitSet) ToSlice() []int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	s := make([]int, 0, set.count)
+	set.each(func(elem int) bool {
+		s = append(s, elem)
+		return false
+	})
+	return s
+}
+
+func (set *bitSet) ToSet() Set {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	s := newThreadSafeSetWithSize(set.count)
+	set.each(func(elem int) bool {
+		s.objects[elem] = struct{}{}
+		return false
+	})
+	return &s
+}
+
+func (set *bitSet) Intersects(other IntSet) bool {
+	if o, ok := other.(*bitSet); ok {
+		unlock := set.rlockWith(o)
+		defer unlock()
+
+		for w := 0; w < minInt(len(set.words), len(o.words)); w++ {
+			if set.words[w]&o.words[w] != 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	items := other.ToSlice()
+
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	for _, item := range items {
+		if set.has(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func (set *bitSet) IsDisjoint(other IntSet) bool {
+	return !set.Intersects(other)
+}
+
+func (set *bitSet) Iter() <-chan int {
+	return iterInts(set)
+}
+
+func (set *bitSet) Iterator() *IntIterator {
+	return intIterator(set)
+}
+
+func (set *bitSet) PowerSet() []IntSet {
+	return intPowerSet(set, newBitSet(set.max))
+}
+
+func (set *bitSet) CartesianProduct(other IntSet) []IntPair {
+	return intCartesianProduct(set, other)
+}
+
+func (set *bitSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(set.ToSlice())
+}
+
+// UnmarshalJSON adds the ints of a JSON array to the set, ints outside of
+// its range are skipped as AddAll skips them.
+func (set *bitSet) UnmarshalJSON(b []byte) error {
+	return unmarshalIntsJSON(set, b)
+}
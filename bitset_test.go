@@ -0,0 +1,162 @@
+package mapset
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func Test_BitSetBasics(t *testing.T) {
+	s := NewBitSet(200)
+	if !s.Add(0) || s.Add(0) || !s.Add(200) {
+		t.Error("Add should only report new elements")
+	}
+	if n := s.AddAll(63, 64, 64, 127); n != 3 {
+		t.Errorf("AddAll should add 3 new elements, got %d", n)
+	}
+	if s.Cardinality() != 5 || !s.Contains(0, 63, 64, 127, 200) || s.Contains(1) || s.Contains(-1, 201) {
+		t.Errorf("unexpected elements in %v", s)
+	}
+	if str := s.String(); str != "Set{0, 63, 64, 127, 200}" {
+		t.Errorf("unexpected string representation %q", str)
+	}
+
+	s.Remove(200)
+	if n := s.RemoveAll(127, 128, -1); n != 1 || s.Cardinality() != 3 {
+		t.Errorf("RemoveAll should remove 1 element, got %d", n)
+	}
+
+	if slice := s.ToSlice(); len(slice) != 3 || slice[0] != 0 || slice[1] != 63 || slice[2] != 64 {
+		t.Errorf("ToSlice should return [0 63 64], got %v", slice)
+	}
+	if !s.ToSet().Equal(NewSet(0, 63, 64)) {
+		t.Errorf("ToSet should hold the same elements, got %v", s.ToSet())
+	}
+
+	if elem, ok := s.Pop(); !ok || elem != 0 || s.Contains(0) {
+		t.Errorf("Pop should remove an element, got %d, %v", elem, ok)
+	}
+	if _, ok := NewBitSet(10).Pop(); ok {
+		t.Error("Pop on the empty set should report false")
+	}
+
+	clone := s.Clone()
+	s.Clear()
+	if s.Cardinality() != 0 || clone.Cardinality() != 2 {
+		t.Error("Clear should empty the set without affecting its clone")
+	}
+
+	if s.Add(-1) || s.Add(201) || s.AddAll(-1, 5, 201) != 1 || s.Contains(-1) || s.Cardinality() != 1 {
+		t.Errorf("ints outside of the range should not be added, got %v", s)
+	}
+
+	if !NewBitSet(-5).Add(0) {
+		t.Error("a negative maxValue should be treated as 0")
+	}
+}
+
+func Test_BitSetFromSet(t *testing.T) {
+	s, err := NewBitSetFromSet(NewSet(3, 1, 200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Equal(NewIntSet(1, 3, 200)) || !s.Add(0) {
+		t.Errorf("the bit set should hold the elements of the set, got %v", s)
+	}
+	if !s.ToSet().Equal(NewSet(0, 1, 3, 200)) {
+		t.Errorf("ToSet should convert back to a Set, got %v", s.ToSet())
+	}
+
+	if s, err := NewBitSetFromSet(NewSet()); err != nil || s.Cardinality() != 0 {
+		t.Errorf("the empty set should convert to an empty bit set, got %v and error %v", s, err)
+	}
+
+	var mismatch *TypeMismatchError
+	if _, err := NewBitSetFromSet(NewSet(1, "2")); !errors.As(err, &mismatch) || mismatch.Actual != "string" {
+		t.Errorf("a non-int element should fail with a *TypeMismatchError, got %v", err)
+	}
+	if _, err := NewBitSetFromSet(NewSet(1, -2)); err == nil {
+		t.Error("a negative element should fail")
+	}
+	if _, err := NewBitSetFromSet(NewSet(1, MaxBitSetFromSetValue+1)); err == nil {
+		t.Error("an element above MaxBitSetFromSetValue should fail instead of allocating its bitmap")
+	}
+	if s, err := NewBitSetFromSet(NewSet(MaxBitSetFromSetValue)); err != nil || !s.Contains(MaxBitSetFromSetValue) {
+		t.Errorf("MaxBitSetFromSetValue itself should be accepted, got %v and error %v", s, err)
+	}
+}
+
+func newBitSetWith(max int, i ...int) IntSet {
+	s := NewBitSet(max)
+	s.AddAll(i...)
+	return s
+}
+
+func Test_BitSetOperations(t *testing.T) {
+	constructors := map[string]func(...int) IntSet{
+		"bits":      func(i ...int) IntSet { return newBitSetWith(100, i...) },
+		"wide bits": func(i ...int) IntSet { return newBitSetWith(1000, i...) },
+		"map":       NewIntSet,
+	}
+
+	for bn, newB := range constructors {
+		a := newBitSetWith(100, 1, 2, 3, 70)
+		b := newB(2, 3, 4, 99)
+
+		check := func(op string, actual, expected IntSet) {
+			if !actual.Equal(expected) || !expected.Equal(actual) {
+				t.Errorf("bits %s %s: expected %v, got %v", op, bn, expected, actual)
+			}
+		}
+		check("Union", a.Union(b), NewIntSet(1, 2, 3, 4, 70, 99))
+		check("Intersect", a.Intersect(b), NewIntSet(2, 3))
+		check("Difference", a.Difference(b), NewIntSet(1, 70))
+		check("SymmetricDifference", a.SymmetricDifference(b), NewIntSet(1, 4, 70, 99))
+
+		if !a.Intersects(b) || a.IsDisjoint(b) || a.Intersects(newB(5, 98)) || !a.IsDisjoint(newB()) {
+			t.Errorf("bits Intersects %s is wrong", bn)
+		}
+
+		if a.Equal(b) || !a.Equal(newB(70, 3, 2, 1)) {
+			t.Errorf("bits Equal %s is wrong", bn)
+		}
+
+		sub := newB(1, 2)
+		if !sub.IsSubset(a) || !sub.IsProperSubset(a) || !a.IsSuperset(sub) || !a.IsProperSuperset(sub) {
+			t.Errorf("bits subset relations against %s are wrong", bn)
+		}
+		if a.IsSubset(sub) || a.IsProperSubset(newB(1, 2, 3, 70)) || !a.IsSubset(newB(1, 2, 3, 70)) {
+			t.Errorf("bits subset relations against %s are wrong", bn)
+		}
+	}
+
+	a := newBitSetWith(100, 1, 2)
+	if !a.Union(NewIntSet(-1, 500)).Equal(NewIntSet(-1, 1, 2, 500)) {
+		t.Error("Union with a map-backed set should hold elements outside of the range")
+	}
+	if !a.Equal(a) || !a.IsSubset(a) || a.IsProperSubset(a) {
+		t.Error("subset relations of a bit set with itself are wrong")
+	}
+}
+
+func Test_BitSetConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s := NewBitSet(N)
+	var wg sync.WaitGroup
+	for i := 0; i < N; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Add(i)
+			s.Contains(i)
+			s.Union(NewBitSet(10))
+		}(i)
+	}
+	wg.Wait()
+
+	if s.Cardinality() != N {
+		t.Errorf("every element should have been added, got %d", s.Cardinality())
+	}
+}
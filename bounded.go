@@ -0,0 +1,111 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+// boundedSet is the thread-safe set returned by NewBoundedSet. It embeds
+// the thread-safe set holding its elements, so every read and removal is
+// that set's own, and overrides the operations inserting elements to
+// check the bound under the same write lock.
+type boundedSet struct {
+	setWrapper
+	objects *threadSafeSet
+	max     int
+}
+
+func newBoundedSet(max int) *boundedSet {
+	if max < 0 {
+		max = 0
+	}
+
+	objects := newThreadSafeSet()
+	set := &boundedSet{objects: &objects, max: max}
+	set.setWrapper = setWrapper{Set: &objects, self: set}
+	return set
+}
+
+// insert adds items while there is room and returns how many were added,
+// callers must hold the write lock.
+func (set *boundedSet) insert(items ...interface{}) int {
+	objects := set.objects.objects
+	added := 0
+	for _, item := range items {
+		if _, found := objects[item]; found || len(objects) >= set.max {
+			continue
+		}
+		objects[item] = struct{}{}
+		added++
+	}
+	return added
+}
+
+func (set *boundedSet) Add(i interface{}) bool {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.insert(i) == 1
+}
+
+func (set *boundedSet) AddAll(i ...interface{}) int {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.insert(i...)
+}
+
+func (set *boundedSet) AddIfNotContains(candidate, guard interface{}) bool {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	if _, found := set.objects.objects[guard]; found {
+		return false
+	}
+	return set.insert(candidate) == 1
+}
+
+func (set *boundedSet) reset(items []interface{}) {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.objects.objects.clear()
+	set.insert(items...)
+}
+
+func (set *boundedSet) ReplaceAll(i ...interface{}) {
+	set.reset(i)
+}
+
+func (set *boundedSet) Clone() Set {
+	set.objects.mutex.RLock()
+	defer set.objects.mutex.RUnlock()
+
+	clone := newBoundedSet(set.max)
+	clone.objects.objects = *set.objects.objects.Clone().(*threadUnsafeSet)
+	return clone
+}
+
+func (set *boundedSet) Snapshot() Set {
+	return set.Clone()
+}
@@ -0,0 +1,109 @@
+package mapset
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func Test_BoundedSetAdd(t *testing.T) {
+	a := NewBoundedSet(2)
+
+	if !a.Add(1) || !a.Add(2) {
+		t.Fatal("Add should accept elements while there is room")
+	}
+	if a.Add(3) || a.Contains(3) {
+		t.Error("Add should reject a new element once the set is full")
+	}
+	if a.Add(1) {
+		t.Error("Add should report false for an element already in the set")
+	}
+
+	a.Remove(1)
+	if !a.Add(3) || !a.Equal(NewSet(2, 3)) {
+		t.Errorf("removing an element should make room again, got %v", a)
+	}
+
+	b := NewBoundedSet(3)
+	if added := b.AddAll(1, 1, 2, 3, 4, 5); added != 3 || !b.Equal(NewSet(1, 2, 3)) {
+		t.Errorf("AddAll should stop inserting once the set is full, added %d to %v", added, b)
+	}
+
+	c := NewBoundedSet(3)
+	c.Add(1)
+	if added := c.AddSet(NewSet(2, 3, 4, 5)); added != 2 || c.Cardinality() != 3 {
+		t.Errorf("AddSet should stop inserting once the set is full, added %d to %v", added, c)
+	}
+
+	if c.AddIfNotContains(6, 7) {
+		t.Error("AddIfNotContains should reject a new element once the set is full")
+	}
+
+	if NewBoundedSet(-1).Add(1) {
+		t.Error("a negative bound should be treated as 0")
+	}
+}
+
+func Test_BoundedSetOperations(t *testing.T) {
+	a := NewBoundedSet(3)
+	a.AddAll(1, 2, 3)
+
+	clone := a.Clone()
+	clone.Remove(1)
+	if !clone.Add(4) || clone.Add(5) {
+		t.Error("the clone of a bounded set should keep the bound")
+	}
+	if !a.Equal(NewSet(1, 2, 3)) {
+		t.Error("changing the clone should not affect the set")
+	}
+
+	if union := a.Union(NewSet(4, 5)); union.Cardinality() != 5 {
+		t.Errorf("Union should return an ordinary set holding every element, got %v", union)
+	}
+
+	NewSet(7, 8, 9, 10).CopyTo(a)
+	if a.Cardinality() != 3 || !a.IsSubset(NewSet(7, 8, 9, 10)) {
+		t.Errorf("copying into a bounded set should respect the bound, got %v", a)
+	}
+
+	a.ReplaceAll(1, 2, 3, 4)
+	if a.Cardinality() != 3 || !a.IsSubset(NewSet(1, 2, 3, 4)) {
+		t.Errorf("ReplaceAll on a bounded set should respect the bound, got %v", a)
+	}
+
+	b := NewBoundedSet(2)
+	if err := json.Unmarshal([]byte(`["a","b","c"]`), b); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.ReadCSV(strings.NewReader("d\n")); err != nil {
+		t.Fatal(err)
+	}
+	if b.Cardinality() != 2 {
+		t.Errorf("decoding into a bounded set should respect the bound, got %v", b)
+	}
+}
+
+func Test_BoundedSetSelfArgument(t *testing.T) {
+	assertSelfArgument(t, "bounded", func() Set { return NewBoundedSet(10) })
+}
+
+func Test_BoundedSetConcurrentAdd(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s := NewBoundedSet(N / 2)
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for i := 0; i < N; i++ {
+		go func(i int) {
+			s.Add(i)
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	if s.Cardinality() != N/2 {
+		t.Errorf("concurrent adds should fill the set up to its bound of %d, got %d", N/2, s.Cardinality())
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+// SetBuilder builds a set incrementally and hands it out read-only with
+// Build, e.g. for a lookup table filled once at startup and then read by
+// many goroutines. A SetBuilder is not safe for concurrent use.
+//
+//	b := NewSetBuilder()
+//	for _, name := range names {
+//		b.Add(name)
+//	}
+//	allowed := b.Build()
+type SetBuilder struct {
+	objects threadUnsafeSet
+}
+
+// NewSetBuilder returns an empty SetBuilder.
+func NewSetBuilder() *SetBuilder {
+	return &SetBuilder{objects: newThreadUnsafeSet()}
+}
+
+// Add adds an element to the set being built and returns whether it was
+// added.
+func (b *SetBuilder) Add(i interface{}) bool {
+	return b.objects.Add(i)
+}
+
+// AddAll adds the given elements to the set being built and returns how
+// many of them were added.
+func (b *SetBuilder) AddAll(i ...interface{}) int {
+	return b.objects.AddAll(i...)
+}
+
+// Cardinality returns how many elements were added since the last Build.
+func (b *SetBuilder) Cardinality() int {
+	return b.objects.Cardinality()
+}
+
+// Build returns the set built so far as a frozen set: it panics on every
+// mutating operation and, since nothing can modify it anymore, reads take
+// no lock at all, so it may be shared freely between goroutines. The
+// builder hands the elements over rather than copying them and starts
+// again from an empty set, so it can be reused to build another set.
+func (b *SetBuilder) Build() Set {
+	built := b.objects
+	b.objects = newThreadUnsafeSet()
+	return freeze(&built)
+}
@@ -0,0 +1,57 @@
+package mapset
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func Test_SetBuilder(t *testing.T) {
+	b := NewSetBuilder()
+	if !b.Add(1) || b.Add(1) {
+		t.Error("Add should report whether the element was added")
+	}
+	if added := b.AddAll(1, 2, 3); added != 2 || b.Cardinality() != 3 {
+		t.Errorf("AddAll should report how many elements were added, got %d", added)
+	}
+
+	built := b.Build()
+	if !built.Equal(NewSet(1, 2, 3)) {
+		t.Errorf("Build should return every element added, got %v", built)
+	}
+	assertPanics(t, "Add", func() { built.Add(4) })
+
+	if b.Cardinality() != 0 {
+		t.Error("the builder should start again from an empty set after Build")
+	}
+	b.Add(4)
+	if again := b.Build(); !again.Equal(NewSet(4)) || built.Contains(4) {
+		t.Errorf("reusing the builder should not affect the sets already built, got %v and %v", built, again)
+	}
+}
+
+func Test_SetBuilderConcurrentReads(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	b := NewSetBuilder()
+	for i := 0; i < 100; i++ {
+		b.Add(i)
+	}
+	built := b.Build()
+	b.Add(100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if !built.Contains(j) {
+					t.Errorf("the built set should hold %d", j)
+				}
+			}
+			built.Each(func(interface{}) bool { return false })
+		}()
+	}
+	wg.Wait()
+}
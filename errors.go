@@ -0,0 +1,55 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import "fmt"
+
+// TypeMismatchError reports that an operation was given a value whose
+// concrete type it does not support. Operations returning an error return
+// it as is, others panic with it, so it can be recovered and inspected:
+//
+//	defer func() {
+//		if err, ok := recover().(*mapset.TypeMismatchError); ok {
+//			log.Printf("%s wants %s, got %s", err.Op, err.Expected, err.Actual)
+//		}
+//	}()
+type TypeMismatchError struct {
+	// Op is the name of the operation, e.g. "NewSetFromMapKeys".
+	Op string
+	// Expected describes the type the operation supports, e.g. "a map".
+	Expected string
+	// Actual is the concrete type of the value it was given, as
+	// formatted by the %T verb.
+	Actual string
+}
+
+func newTypeMismatchError(op, expected string, actual interface{}) *TypeMismatchError {
+	return &TypeMismatchError{Op: op, Expected: expected, Actual: fmt.Sprintf("%T", actual)}
+}
+
+func (e *TypeMismatchError) Error() string {
+	return "mapset: " + e.Op + " expected " + e.Expected + ", got " + e.Actual
+}
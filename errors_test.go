@@ -0,0 +1,36 @@
+package mapset
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+)
+
+func Test_TypeMismatchError(t *testing.T) {
+	func() {
+		defer func() {
+			err, ok := recover().(*TypeMismatchError)
+			if !ok {
+				t.Fatal("NewSetFromMapKeys should panic with a *TypeMismatchError")
+			}
+			if err.Op != "NewSetFromMapKeys" || err.Actual != "[]string" {
+				t.Errorf("unexpected error %+v", err)
+			}
+			if expected := "mapset: NewSetFromMapKeys expected a map, got []string"; err.Error() != expected {
+				t.Errorf("expected %q, got %q", expected, err.Error())
+			}
+		}()
+		NewSetFromMapKeys([]string{"a"})
+	}()
+
+	var mismatch *TypeMismatchError
+	err := NewSet().UnmarshalJSONInto([]byte(`[1]`), func() interface{} { return 0 })
+	if !errors.As(err, &mismatch) || mismatch.Op != "UnmarshalJSONInto" || mismatch.Actual != "int" {
+		t.Errorf("UnmarshalJSONInto should fail with a *TypeMismatchError, got %v", err)
+	}
+
+	_, err = xml.Marshal(NewSet(complex64(1)))
+	if !errors.As(err, &mismatch) || mismatch.Op != "MarshalXML" || mismatch.Actual != "complex64" {
+		t.Errorf("MarshalXML should fail with a *TypeMismatchError, got %v", err)
+	}
+}
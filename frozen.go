@@ -0,0 +1,168 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+)
+
+// frozenSet is the read-only view returned by Freeze. It embeds the set it
+// wraps, so every read operation is the wrapped set's own, and overrides
+// the mutating operations to panic. Derived sets, including Clone, are
+// ordinary mutable sets created by the wrapped set.
+type frozenSet struct {
+	Set
+}
+
+func freeze(set Set) Set {
+	return &frozenSet{Set: set}
+}
+
+func frozenPanic(method string) {
+	panic("mapset: " + method + " called on a frozen set")
+}
+
+func (set *frozenSet) Add(i interface{}) bool {
+	frozenPanic("Add")
+	return false
+}
+
+func (set *frozenSet) AddAll(i ...interface{}) int {
+	frozenPanic("AddAll")
+	return 0
+}
+
+func (set *frozenSet) AddIfNotContains(candidate, guard interface{}) bool {
+	frozenPanic("AddIfNotContains")
+	return false
+}
+
+func (set *frozenSet) Clear() {
+	frozenPanic("Clear")
+}
+
+func (set *frozenSet) ClearRetainingCapacity() {
+	frozenPanic("ClearRetainingCapacity")
+}
+
+func (set *frozenSet) ReplaceAll(i ...interface{}) {
+	frozenPanic("ReplaceAll")
+}
+
+func (set *frozenSet) Remove(i interface{}) {
+	frozenPanic("Remove")
+}
+
+func (set *frozenSet) RemoveAll(i ...interface{}) int {
+	frozenPanic("RemoveAll")
+	return 0
+}
+
+func (set *frozenSet) RetainAll(other Set) int {
+	frozenPanic("RetainAll")
+	return 0
+}
+
+func (set *frozenSet) AddSet(other Set) int {
+	frozenPanic("AddSet")
+	return 0
+}
+
+func (set *frozenSet) SubtractSet(other Set) int {
+	frozenPanic("SubtractSet")
+	return 0
+}
+
+func (set *frozenSet) Pop() interface{} {
+	frozenPanic("Pop")
+	return nil
+}
+
+func (set *frozenSet) TryPop() (interface{}, bool) {
+	frozenPanic("TryPop")
+	return nil, false
+}
+
+func (set *frozenSet) Drain() []interface{} {
+	frozenPanic("Drain")
+	return nil
+}
+
+func (set *frozenSet) UnmarshalJSONWith(b []byte, convertNumber func(json.Number) interface{}) error {
+	frozenPanic("UnmarshalJSONWith")
+	return nil
+}
+
+func (set *frozenSet) UnmarshalJSONInto(b []byte, prototype func() interface{}) error {
+	frozenPanic("UnmarshalJSONInto")
+	return nil
+}
+
+func (set *frozenSet) UnmarshalText(text []byte) error {
+	frozenPanic("UnmarshalText")
+	return nil
+}
+
+func (set *frozenSet) ReadCSV(r io.Reader) error {
+	frozenPanic("ReadCSV")
+	return nil
+}
+
+func (set *frozenSet) Freeze() Set {
+	return set
+}
+
+func (set *frozenSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(set.Set)
+}
+
+func (set *frozenSet) GobEncode() ([]byte, error) {
+	encoder, ok := set.Set.(gob.GobEncoder)
+	if !ok {
+		return nil, newTypeMismatchError("GobEncode", "a gob.GobEncoder", set.Set)
+	}
+	return encoder.GobEncode()
+}
+
+func (set *frozenSet) MarshalBinary() ([]byte, error) {
+	marshaler, ok := set.Set.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, newTypeMismatchError("MarshalBinary", "an encoding.BinaryMarshaler", set.Set)
+	}
+	return marshaler.MarshalBinary()
+}
+
+func (set *frozenSet) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	marshaler, ok := set.Set.(xml.Marshaler)
+	if !ok {
+		return newTypeMismatchError("MarshalXML", "an xml.Marshaler", set.Set)
+	}
+	return marshaler.MarshalXML(e, start)
+}
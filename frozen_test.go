@@ -0,0 +1,114 @@
+package mapset
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func assertPanics(t *testing.T, method string, f func()) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s on a frozen set should panic", method)
+		}
+	}()
+	f()
+}
+
+func Test_FrozenSetMutationsPanic(t *testing.T) {
+	for name, newSet := range setConstructors {
+		frozen := newSet(1, 2, 3).Freeze()
+
+		assertPanics(t, name+" Add", func() { frozen.Add(4) })
+		assertPanics(t, name+" AddAll", func() { frozen.AddAll(4, 5) })
+		assertPanics(t, name+" AddIfNotContains", func() { frozen.AddIfNotContains(4, 5) })
+		assertPanics(t, name+" Remove", func() { frozen.Remove(1) })
+		assertPanics(t, name+" RemoveAll", func() { frozen.RemoveAll(1, 2) })
+		assertPanics(t, name+" RetainAll", func() { frozen.RetainAll(NewSet(1)) })
+		assertPanics(t, name+" AddSet", func() { frozen.AddSet(NewSet(4)) })
+		assertPanics(t, name+" SubtractSet", func() { frozen.SubtractSet(NewSet(1)) })
+		assertPanics(t, name+" Clear", func() { frozen.Clear() })
+		assertPanics(t, name+" ClearRetainingCapacity", func() { frozen.ClearRetainingCapacity() })
+		assertPanics(t, name+" ReplaceAll", func() { frozen.ReplaceAll(4) })
+		assertPanics(t, name+" Pop", func() { frozen.Pop() })
+		assertPanics(t, name+" TryPop", func() { frozen.TryPop() })
+		assertPanics(t, name+" Drain", func() { frozen.Drain() })
+		assertPanics(t, name+" UnmarshalJSONInto", func() {
+			frozen.UnmarshalJSONInto([]byte("[4]"), func() interface{} { return new(int) })
+		})
+		assertPanics(t, name+" UnmarshalJSONWith", func() { frozen.UnmarshalJSONWith([]byte("[4]"), ConvertJSONNumber) })
+		assertPanics(t, name+" UnmarshalText", func() { frozen.UnmarshalText([]byte("4")) })
+		assertPanics(t, name+" ReadCSV", func() { frozen.ReadCSV(strings.NewReader("4")) })
+		assertPanics(t, name+" CopyTo", func() { NewSet(9).CopyTo(frozen) })
+
+		if frozen.Cardinality() != 3 || !frozen.Contains(1, 2, 3) {
+			t.Errorf("%s: the frozen set should be left untouched, got %v", name, frozen)
+		}
+	}
+}
+
+func Test_FrozenSetReads(t *testing.T) {
+	for name, newSet := range setConstructors {
+		frozen := newSet(1, 2, 3).Freeze()
+
+		if !frozen.Equal(NewSet(1, 2, 3)) || !NewSet(1, 2, 3).Equal(frozen) {
+			t.Errorf("%s: the frozen set should equal its contents", name)
+		}
+		if !frozen.Union(NewSet(4)).Equal(NewSet(1, 2, 3, 4)) {
+			t.Errorf("%s: Union on a frozen set is wrong", name)
+		}
+		if frozen.Freeze() != frozen {
+			t.Errorf("%s: freezing a frozen set should return it as is", name)
+		}
+
+		clone := frozen.Clone()
+		clone.Add(4)
+		if frozen.Contains(4) {
+			t.Errorf("%s: the clone of a frozen set should be mutable and independent", name)
+		}
+
+		b, err := json.Marshal(frozen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded []int
+		if err := json.Unmarshal(b, &decoded); err != nil || len(decoded) != 3 {
+			t.Errorf("%s: unexpected JSON %s", name, b)
+		}
+	}
+}
+
+func Test_FrozenSetConcurrentReads(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s := NewSet()
+	for i := 0; i < N; i++ {
+		s.Add(i)
+	}
+	frozen := s.Freeze()
+
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for i := 0; i < N; i++ {
+		go func(i int) {
+			frozen.Contains(i)
+			frozen.Cardinality()
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+}
+
+func Test_FrozenSetDerivedKinds(t *testing.T) {
+	if _, ok := NewSet(1, 2).Freeze().Clone().(*threadUnsafeSet); !ok {
+		t.Error("the view of a thread-safe set should derive thread-unsafe sets")
+	}
+	if _, ok := NewOrderedSet(1, 2).Freeze().Union(NewSet(3)).(*orderedSet); !ok {
+		t.Error("the view of an ordered set should derive ordered sets")
+	}
+	if _, ok := NewShardedSet(4).Freeze().Clone().(*shardedSet); !ok {
+		t.Error("the view of a sharded set should derive sharded sets")
+	}
+}
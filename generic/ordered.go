@@ -0,0 +1,194 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generic
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Ordered is a constraint permitting any type that supports the < operator,
+// the same type set as golang.org/x/exp/constraints.Ordered. It is declared
+// here so that the package keeps depending on the standard library only.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 |
+		~string
+}
+
+// OrderedSet is a Set whose elements are ordered by <. ToSlice returns the
+// elements sorted in increasing order, String lists them in that order, and
+// Min and Max need no comparator. Iter and Each still visit the elements in
+// no particular order.
+//
+// The sets returned by Clone, Map and the binary operations are OrderedSets
+// too, built on the same implementation as the receiver.
+type OrderedSet[T Ordered] interface {
+	Set[T]
+
+	// Returns the smallest element of the set, and
+	// whether the set was non-empty.
+	Min() (T, bool)
+
+	// Returns the largest element of the set, and
+	// whether the set was non-empty.
+	Max() (T, bool)
+}
+
+// orderedSet wraps a thread-safe or thread-unsafe Set, sorting its elements
+// on the way out.
+type orderedSet[T Ordered] struct {
+	Set[T]
+}
+
+// NewOrderedSet creates and returns a reference to an ordered set holding
+// the given elements. Operations on the resulting set are thread-safe.
+func NewOrderedSet[T Ordered](elements ...T) OrderedSet[T] {
+	return &orderedSet[T]{Set: NewSet(elements...)}
+}
+
+// NewThreadUnsafeOrderedSet creates and returns a reference to an ordered
+// set holding the given elements. Operations on the resulting set are not
+// thread-safe.
+func NewThreadUnsafeOrderedSet[T Ordered](elements ...T) OrderedSet[T] {
+	return &orderedSet[T]{Set: NewThreadUnsafeSet(elements...)}
+}
+
+// wrap returns s as an ordered set.
+func (set *orderedSet[T]) wrap(s Set[T]) Set[T] {
+	return &orderedSet[T]{Set: s}
+}
+
+// unwrap returns the set wrapped by other if it is an ordered set, so that
+// it can be passed to the wrapped set of the receiver.
+func (set *orderedSet[T]) unwrap(other Set[T]) Set[T] {
+	if o, ok := other.(*orderedSet[T]); ok {
+		return o.Set
+	}
+	return other
+}
+
+func (set *orderedSet[T]) Clone() Set[T] {
+	return set.wrap(set.Set.Clone())
+}
+
+func (set *orderedSet[T]) Difference(other Set[T]) Set[T] {
+	return set.wrap(set.Set.Difference(set.unwrap(other)))
+}
+
+func (set *orderedSet[T]) Equal(other Set[T]) bool {
+	return set.Set.Equal(set.unwrap(other))
+}
+
+func (set *orderedSet[T]) Intersect(other Set[T]) Set[T] {
+	return set.wrap(set.Set.Intersect(set.unwrap(other)))
+}
+
+func (set *orderedSet[T]) IsProperSubset(other Set[T]) bool {
+	return set.Set.IsProperSubset(set.unwrap(other))
+}
+
+func (set *orderedSet[T]) IsProperSuperset(other Set[T]) bool {
+	return set.Set.IsProperSuperset(set.unwrap(other))
+}
+
+func (set *orderedSet[T]) IsSubset(other Set[T]) bool {
+	return set.Set.IsSubset(set.unwrap(other))
+}
+
+func (set *orderedSet[T]) IsSuperset(other Set[T]) bool {
+	return set.Set.IsSuperset(set.unwrap(other))
+}
+
+func (set *orderedSet[T]) Map(transform func(T) T) Set[T] {
+	return set.wrap(set.Set.Map(transform))
+}
+
+func (set *orderedSet[T]) SymmetricDifference(other Set[T]) Set[T] {
+	return set.wrap(set.Set.SymmetricDifference(set.unwrap(other)))
+}
+
+func (set *orderedSet[T]) Union(other Set[T]) Set[T] {
+	return set.wrap(set.Set.Union(set.unwrap(other)))
+}
+
+func (set *orderedSet[T]) Min() (T, bool) {
+	var min T
+	found := false
+	set.Each(func(elem T) bool {
+		if !found || elem < min {
+			min, found = elem, true
+		}
+		return false
+	})
+	return min, found
+}
+
+func (set *orderedSet[T]) Max() (T, bool) {
+	var max T
+	found := false
+	set.Each(func(elem T) bool {
+		if !found || elem > max {
+			max, found = elem, true
+		}
+		return false
+	})
+	return max, found
+}
+
+func (set *orderedSet[T]) ToSlice() []T {
+	items := set.Set.ToSlice()
+	sort.Slice(items, func(i, j int) bool {
+		return items[i] < items[j]
+	})
+	return items
+}
+
+func (set *orderedSet[T]) String() string {
+	items := set.ToSlice()
+	strs := make([]string, len(items))
+	for i, elem := range items {
+		strs[i] = fmt.Sprintf("%v", elem)
+	}
+
+	return fmt.Sprintf("Set{%s}", strings.Join(strs, ", "))
+}
+
+// MarshalJSON creates a JSON array holding the elements in increasing order.
+func (set *orderedSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(set.ToSlice())
+}
+
+// UnmarshalJSON adds the elements of a JSON array to the set.
+func (set *orderedSet[T]) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, set.Set)
+}
@@ -0,0 +1,111 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generic
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_OrderedSet(t *testing.T) {
+	for name, newSet := range map[string]func(...int) OrderedSet[int]{
+		"safe":   NewOrderedSet[int],
+		"unsafe": NewThreadUnsafeOrderedSet[int],
+	} {
+		a := newSet(3, 1, 2)
+		if s := a.ToSlice(); !reflect.DeepEqual(s, []int{1, 2, 3}) {
+			t.Errorf("%s: ToSlice should return the sorted elements, got %v", name, s)
+		}
+		if s := a.String(); s != "Set{1, 2, 3}" {
+			t.Errorf("%s: unexpected string representation %q", name, s)
+		}
+
+		if min, ok := a.Min(); !ok || min != 1 {
+			t.Errorf("%s: Min should return 1, got %v, %v", name, min, ok)
+		}
+		if max, ok := a.Max(); !ok || max != 3 {
+			t.Errorf("%s: Max should return 3, got %v, %v", name, max, ok)
+		}
+		if _, ok := newSet().Min(); ok {
+			t.Errorf("%s: Min on an empty set should report false", name)
+		}
+
+		union := a.Union(newSet(5, 4))
+		if s := union.ToSlice(); !reflect.DeepEqual(s, []int{1, 2, 3, 4, 5}) {
+			t.Errorf("%s: the union should be an ordered set, got %v", name, s)
+		}
+		if !a.IsSubset(union) || !union.IsProperSuperset(a) || !a.Equal(a.Clone()) {
+			t.Errorf("%s: unexpected result comparing ordered sets", name)
+		}
+		if max, _ := union.(OrderedSet[int]).Max(); max != 5 {
+			t.Errorf("%s: Max of the union should be 5, got %v", name, max)
+		}
+	}
+
+	b, err := json.Marshal(NewOrderedSet("b", "c", "a"))
+	if err != nil || string(b) != `["a","b","c"]` {
+		t.Errorf("MarshalJSON should list the sorted elements, got %s, %v", b, err)
+	}
+	s := NewOrderedSet[string]()
+	if err := json.Unmarshal(b, s); err != nil || !s.Equal(NewOrderedSet("a", "b", "c")) {
+		t.Errorf("UnmarshalJSON should restore the elements, got %v, %v", s, err)
+	}
+}
+
+func Test_OrderedSetMixed(t *testing.T) {
+	plain := map[string]func(...int) Set[int]{
+		"safe":   NewSet[int],
+		"unsafe": NewThreadUnsafeSet[int],
+	}
+	ordered := map[string]func(...int) OrderedSet[int]{
+		"safe":   NewOrderedSet[int],
+		"unsafe": NewThreadUnsafeOrderedSet[int],
+	}
+
+	for pname, newPlain := range plain {
+		for oname, newOrdered := range ordered {
+			name := pname + "/ordered " + oname
+			for _, pair := range [][2]Set[int]{
+				{newPlain(1, 2, 3), newOrdered(3, 4)},
+				{newOrdered(1, 2, 3), newPlain(3, 4)},
+			} {
+				a, b := pair[0], pair[1]
+				assertEqual(a.Union(b), NewSet(1, 2, 3, 4), t)
+				assertEqual(a.Intersect(b), NewSet(3), t)
+				assertEqual(a.Difference(b), NewSet(1, 2), t)
+				assertEqual(a.SymmetricDifference(b), NewSet(1, 2, 4), t)
+
+				if !a.Equal(a.Union(b).Difference(NewSet(4))) || a.IsSubset(b) || !b.IsProperSubset(a.Union(b)) {
+					t.Errorf("%s: unexpected result comparing a plain and an ordered set", name)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,213 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package generic implements a strongly typed variant of the mapset
+// collection using Go generics. Items stored within it are unordered
+// and unique, and all of them share the element type T, so no type
+// assertions are needed when reading elements back out.
+//
+// Like package mapset, package generic provides a thread-safe
+// implementation, returned by NewSet and NewThreadSafeSet, and a
+// non-thread-safe implementation, returned by NewThreadUnsafeSet.
+// Methods taking another set, such as Union or Equal, accept any Set[T],
+// so the two implementations and OrderedSet can be mixed freely; the
+// result always uses the implementation of the receiver.
+package generic
+
+// Set is the primary interface provided by the generic package. It
+// mirrors mapset.Set but every element is of type T.
+type Set[T comparable] interface {
+	// Adds an element to the set. Returns whether
+	// the item was added.
+	Add(i T) bool
+
+	// Adds all of the given elements to the set.
+	// Returns the number of elements that were
+	// actually added, so duplicates are not counted.
+	AddAll(i ...T) int
+
+	// Returns the number of elements in the set.
+	Cardinality() int
+
+	// Returns the number of elements in the set.
+	Length() int
+
+	// Removes all elements from the set, leaving
+	// the empty set.
+	Clear()
+
+	// Returns a clone of the set using the same
+	// implementation, duplicating all keys.
+	Clone() Set[T]
+
+	// Returns whether the given items
+	// are all in the set.
+	Contains(i ...T) bool
+
+	// Returns the difference between this set
+	// and other. The returned set will contain
+	// all elements of this set that are not also
+	// elements of other.
+	Difference(other Set[T]) Set[T]
+
+	// Determines if two sets are equal to each
+	// other. If they have the same cardinality
+	// and contain the same elements, they are
+	// considered equal. The order in which
+	// the elements were added is irrelevant.
+	Equal(other Set[T]) bool
+
+	// Returns a new set containing only the elements
+	// that exist only in both sets.
+	Intersect(other Set[T]) Set[T]
+
+	// Determines if every element in this set is in
+	// the other set but the two sets are not equal.
+	IsProperSubset(other Set[T]) bool
+
+	// Determines if every element in the other set
+	// is in this set but the two sets are not
+	// equal.
+	IsProperSuperset(other Set[T]) bool
+
+	// Determines if every element in this set is in
+	// the other set.
+	IsSubset(other Set[T]) bool
+
+	// Determines if every element in the other set
+	// is in this set.
+	IsSuperset(other Set[T]) bool
+
+	// Iterates over elements and executes the passed func against each element.
+	// If passed func returns true, stop iteration at the time.
+	Each(func(T) bool)
+
+	// Returns a new set containing the result of
+	// applying transform to every element of this set.
+	// The returned set uses the same implementation
+	// as the receiver.
+	//
+	// Note that the returned set may be smaller than
+	// this set: if transform maps several elements to
+	// the same value, that value is only stored once.
+	Map(transform func(T) T) Set[T]
+
+	// Returns whether at least one element of the
+	// set satisfies predicate. Iteration stops at the
+	// first match. Any on an empty set returns false.
+	Any(predicate func(T) bool) bool
+
+	// Returns whether every element of the set
+	// satisfies predicate. Iteration stops at the
+	// first failure. All on an empty set returns true.
+	All(predicate func(T) bool) bool
+
+	// Returns a channel of elements that you can
+	// range over.
+	Iter() <-chan T
+
+	// Remove a single element from the set.
+	Remove(i T)
+
+	// Removes all of the given elements from the set.
+	// Returns the number of elements that were
+	// actually removed.
+	RemoveAll(i ...T) int
+
+	// Provides a convenient string representation
+	// of the current state of the set.
+	String() string
+
+	// Returns a new set with all elements which are
+	// in either this set or the other set but not in both.
+	SymmetricDifference(other Set[T]) Set[T]
+
+	// Returns a new set with all elements in both sets.
+	Union(other Set[T]) Set[T]
+
+	// Pop removes and returns an arbitrary item from the set.
+	// The boolean reports whether an item was removed; it is
+	// false when the set is empty.
+	Pop() (T, bool)
+
+	// Returns the members of the set as a slice.
+	ToSlice() []T
+}
+
+// Reduce folds every element of s into an accumulator, starting from
+// initial, and returns the final accumulator. It is a function rather
+// than a method because methods cannot declare their own type
+// parameters.
+//
+// Since the iteration order of a set is undefined, accumulate should be
+// associative and commutative for the result to be deterministic.
+func Reduce[T comparable, A any](s Set[T], initial A, accumulate func(acc A, elem T) A) A {
+	acc := initial
+	s.Each(func(elem T) bool {
+		acc = accumulate(acc, elem)
+		return false
+	})
+	return acc
+}
+
+// NewSet creates and returns a reference to a set holding the given
+// elements. Operations on the resulting set are thread-safe.
+func NewSet[T comparable](elements ...T) Set[T] {
+	set := newThreadSafeSet[T]()
+	set.objects.AddAll(elements...)
+	return set
+}
+
+// NewThreadSafeSet creates and returns a reference to a set holding the
+// given elements. It is equivalent to NewSet and exists for symmetry
+// with NewThreadUnsafeSet.
+func NewThreadSafeSet[T comparable](elements ...T) Set[T] {
+	return NewSet(elements...)
+}
+
+// NewSetFromSlice creates and returns a reference to a set from an
+// existing slice. Operations on the resulting set are thread-safe.
+func NewSetFromSlice[T comparable](elements []T) Set[T] {
+	return NewSet(elements...)
+}
+
+// NewThreadUnsafeSet creates and returns a reference to a set holding
+// the given elements. Operations on the resulting set are not
+// thread-safe.
+func NewThreadUnsafeSet[T comparable](elements ...T) Set[T] {
+	set := newThreadUnsafeSet[T](len(elements))
+	set.AddAll(elements...)
+	return set
+}
+
+// NewThreadUnsafeSetFromSlice creates and returns a reference to a set
+// from an existing slice. Operations on the resulting set are not
+// thread-safe.
+func NewThreadUnsafeSetFromSlice[T comparable](elements []T) Set[T] {
+	return NewThreadUnsafeSet(elements...)
+}
@@ -0,0 +1,189 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generic
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func assertEqual[T comparable](a, b Set[T], t *testing.T) {
+	if !a.Equal(b) {
+		t.Errorf("%v != %v\n", a, b)
+	}
+}
+
+func Test_NewSet(t *testing.T) {
+	a := NewSet[string]()
+	if a.Cardinality() != 0 {
+		t.Error("NewSet should start out as an empty set")
+	}
+
+	assertEqual(NewSetFromSlice([]string{"a", "b"}), NewSet("a", "b"), t)
+	assertEqual(NewThreadSafeSet(1, 2), NewSet(1, 2), t)
+}
+
+func Test_NewUnsafeSet(t *testing.T) {
+	a := NewThreadUnsafeSet[string]()
+	if a.Cardinality() != 0 {
+		t.Error("NewThreadUnsafeSet should start out as an empty set")
+	}
+
+	assertEqual(NewThreadUnsafeSetFromSlice([]string{"a", "b"}), NewThreadUnsafeSet("a", "b"), t)
+}
+
+func Test_SetOperations(t *testing.T) {
+	a := NewSet(1, 2, 3)
+	b := NewSet(3, 4)
+
+	assertEqual(a.Union(b), NewSet(1, 2, 3, 4), t)
+	assertEqual(a.Intersect(b), NewSet(3), t)
+	assertEqual(a.Difference(b), NewSet(1, 2), t)
+	assertEqual(a.SymmetricDifference(b), NewSet(1, 2, 4), t)
+
+	if !NewSet(1, 2).IsProperSubset(a) || !a.IsSuperset(NewSet(3)) {
+		t.Error("subset relations are wrong")
+	}
+}
+
+func Test_UnsafeSetOperations(t *testing.T) {
+	a := NewThreadUnsafeSet(1, 2, 3)
+	b := NewThreadUnsafeSet(3, 4)
+
+	assertEqual(a.Union(b), NewThreadUnsafeSet(1, 2, 3, 4), t)
+	assertEqual(a.Intersect(b), NewThreadUnsafeSet(3), t)
+	assertEqual(a.Difference(b), NewThreadUnsafeSet(1, 2), t)
+	assertEqual(a.SymmetricDifference(b), NewThreadUnsafeSet(1, 2, 4), t)
+
+	if !NewThreadUnsafeSet(1, 2).IsProperSubset(a) || !a.IsSuperset(NewThreadUnsafeSet(3)) {
+		t.Error("subset relations are wrong")
+	}
+}
+
+func Test_MixedSetOperations(t *testing.T) {
+	a := NewSet(1, 2, 3)
+	b := NewThreadUnsafeSet(3, 4)
+
+	assertEqual(a.Union(b), NewSet(1, 2, 3, 4), t)
+	assertEqual(a.Intersect(b), NewSet(3), t)
+	assertEqual(a.Difference(b), NewSet(1, 2), t)
+	assertEqual(a.SymmetricDifference(b), NewSet(1, 2, 4), t)
+
+	if !a.Equal(NewThreadUnsafeSet(1, 2, 3)) || !a.IsSubset(NewThreadUnsafeSet(1, 2, 3, 4)) {
+		t.Error("a thread-safe set should compare against a thread-unsafe one")
+	}
+}
+
+func Test_ConcurrentBinaryOperations(t *testing.T) {
+	a := NewSet(1, 2, 3)
+	b := NewSet(3, 4)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				x, y := a, b
+				if i%2 == 1 {
+					x, y = b, a
+				}
+				for j := 0; j < 20000; j++ {
+					if i < 2 {
+						x.Add(j)
+						x.Remove(j)
+						continue
+					}
+					x.Union(y)
+					x.Equal(y)
+					x.IsSubset(y)
+				}
+			}(i)
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("binary operations on two thread-safe sets deadlocked")
+	}
+}
+
+func Test_ToSlice(t *testing.T) {
+	for _, s := range []Set[string]{NewSet("b", "a"), NewThreadUnsafeSet("b", "a")} {
+		items := s.ToSlice()
+		sort.Strings(items)
+		if len(items) != 2 || items[0] != "a" || items[1] != "b" {
+			t.Errorf("ToSlice returned unexpected items: %v", items)
+		}
+	}
+}
+
+func Test_Pop(t *testing.T) {
+	for _, s := range []Set[int]{NewSet(7), NewThreadUnsafeSet(7)} {
+		if v, ok := s.Pop(); !ok || v != 7 {
+			t.Errorf("Pop should return 7, got %v, %v", v, ok)
+		}
+		if _, ok := s.Pop(); ok {
+			t.Error("Pop on an empty set should report false")
+		}
+	}
+}
+
+func Test_Reduce(t *testing.T) {
+	a := NewSet("a", "bb", "ccc")
+
+	total := Reduce(a, 0, func(acc int, elem string) int {
+		return acc + len(elem)
+	})
+	if total != 6 {
+		t.Errorf("Reduce should sum the lengths to 6, got %d", total)
+	}
+}
+
+func Test_JSON(t *testing.T) {
+	b, err := json.Marshal(NewSet(1, 2, 3))
+	if err != nil {
+		t.Errorf("Error should be nil: %v", err)
+	}
+
+	actual := NewSet[int]()
+	if err := json.Unmarshal(b, actual); err != nil {
+		t.Errorf("Error should be nil: %v", err)
+	}
+
+	assertEqual(actual, NewSet(1, 2, 3), t)
+}
@@ -0,0 +1,305 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generic
+
+import (
+	"sort"
+	"sync"
+	"unsafe"
+)
+
+type threadSafeSet[T comparable] struct {
+	objects threadUnsafeSet[T]
+	mutex   sync.RWMutex
+}
+
+func newThreadSafeSet[T comparable]() *threadSafeSet[T] {
+	return &threadSafeSet[T]{objects: make(threadUnsafeSet[T])}
+}
+
+// lessAddress reports whether mutex a sorts before mutex b in the lock
+// order shared by every operation that holds several locks at once.
+func lessAddress(a, b *sync.RWMutex) bool {
+	return uintptr(unsafe.Pointer(a)) < uintptr(unsafe.Pointer(b))
+}
+
+// rlockAll read-locks each of the given mutexes once, in order of
+// increasing address, and returns a func that releases those locks.
+func rlockAll(mutexes ...*sync.RWMutex) func() {
+	sorted := make([]*sync.RWMutex, len(mutexes))
+	copy(sorted, mutexes)
+	sort.Slice(sorted, func(i, j int) bool {
+		return lessAddress(sorted[i], sorted[j])
+	})
+
+	locked := sorted[:0]
+	for i, m := range sorted {
+		if i > 0 && m == sorted[i-1] {
+			continue
+		}
+		m.RLock()
+		locked = append(locked, m)
+	}
+
+	return func() {
+		for _, m := range locked {
+			m.RUnlock()
+		}
+	}
+}
+
+// snapshotOf returns other as a thread-unsafe set, copying its elements
+// through the Set interface unless it already is one.
+func snapshotOf[T comparable](other Set[T]) *threadUnsafeSet[T] {
+	if o, ok := other.(*threadUnsafeSet[T]); ok {
+		return o
+	}
+
+	items := other.ToSlice()
+	snapshot := newThreadUnsafeSet[T](len(items))
+	snapshot.AddAll(items...)
+	return snapshot
+}
+
+// rlockWith read-locks set along with other, if other is thread-safe too,
+// and returns the elements of other as a thread-unsafe set together with
+// a func releasing the locks. Other implementations are copied before
+// any lock is taken, so that they never wait on set, nor set on them.
+func (set *threadSafeSet[T]) rlockWith(other Set[T]) (*threadUnsafeSet[T], func()) {
+	if o, ok := other.(*threadSafeSet[T]); ok {
+		return &o.objects, rlockAll(&set.mutex, &o.mutex)
+	}
+
+	snapshot := snapshotOf(other)
+	set.mutex.RLock()
+	return snapshot, set.mutex.RUnlock
+}
+
+func (set *threadSafeSet[T]) Add(i T) bool {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.objects.Add(i)
+}
+
+func (set *threadSafeSet[T]) AddAll(i ...T) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.objects.AddAll(i...)
+}
+
+func (set *threadSafeSet[T]) Contains(i ...T) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.Contains(i...)
+}
+
+func (set *threadSafeSet[T]) IsSubset(other Set[T]) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.objects.IsSubset(o)
+}
+
+func (set *threadSafeSet[T]) IsProperSubset(other Set[T]) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.objects.IsProperSubset(o)
+}
+
+func (set *threadSafeSet[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(set)
+}
+
+func (set *threadSafeSet[T]) IsProperSuperset(other Set[T]) bool {
+	return other.IsProperSubset(set)
+}
+
+func (set *threadSafeSet[T]) Union(other Set[T]) Set[T] {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	union := set.objects.Union(o).(*threadUnsafeSet[T])
+	return &threadSafeSet[T]{objects: *union}
+}
+
+func (set *threadSafeSet[T]) Intersect(other Set[T]) Set[T] {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	unsafeIntersection := set.objects.Intersect(o).(*threadUnsafeSet[T])
+	return &threadSafeSet[T]{objects: *unsafeIntersection}
+}
+
+func (set *threadSafeSet[T]) Difference(other Set[T]) Set[T] {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	diff := set.objects.Difference(o).(*threadUnsafeSet[T])
+	return &threadSafeSet[T]{objects: *diff}
+}
+
+func (set *threadSafeSet[T]) SymmetricDifference(other Set[T]) Set[T] {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	diff := set.objects.SymmetricDifference(o).(*threadUnsafeSet[T])
+	return &threadSafeSet[T]{objects: *diff}
+}
+
+func (set *threadSafeSet[T]) Clear() {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	set.objects = make(threadUnsafeSet[T])
+}
+
+func (set *threadSafeSet[T]) Remove(i T) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	delete(set.objects, i)
+}
+
+func (set *threadSafeSet[T]) RemoveAll(i ...T) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.objects.RemoveAll(i...)
+}
+
+func (set *threadSafeSet[T]) Cardinality() int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return len(set.objects)
+}
+
+func (set *threadSafeSet[T]) Length() int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return len(set.objects)
+}
+
+func (set *threadSafeSet[T]) Each(callback func(T) bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	set.objects.Each(callback)
+}
+
+func (set *threadSafeSet[T]) Map(transform func(T) T) Set[T] {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	mapped := set.objects.Map(transform).(*threadUnsafeSet[T])
+	return &threadSafeSet[T]{objects: *mapped}
+}
+
+func (set *threadSafeSet[T]) Any(predicate func(T) bool) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.Any(predicate)
+}
+
+func (set *threadSafeSet[T]) All(predicate func(T) bool) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.All(predicate)
+}
+
+func (set *threadSafeSet[T]) Iter() <-chan T {
+	ch := make(chan T)
+	go func() {
+		set.mutex.RLock()
+		for elem := range set.objects {
+			ch <- elem
+		}
+		close(ch)
+		set.mutex.RUnlock()
+	}()
+
+	return ch
+}
+
+func (set *threadSafeSet[T]) Equal(other Set[T]) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.objects.Equal(o)
+}
+
+func (set *threadSafeSet[T]) Clone() Set[T] {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	clone := set.objects.Clone().(*threadUnsafeSet[T])
+	return &threadSafeSet[T]{objects: *clone}
+}
+
+func (set *threadSafeSet[T]) String() string {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.String()
+}
+
+func (set *threadSafeSet[T]) Pop() (T, bool) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.objects.Pop()
+}
+
+func (set *threadSafeSet[T]) ToSlice() []T {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.ToSlice()
+}
+
+func (set *threadSafeSet[T]) MarshalJSON() ([]byte, error) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.MarshalJSON()
+}
+
+func (set *threadSafeSet[T]) UnmarshalJSON(p []byte) error {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.objects.UnmarshalJSON(p)
+}
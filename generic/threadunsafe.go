@@ -0,0 +1,299 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type threadUnsafeSet[T comparable] map[T]struct{}
+
+func newThreadUnsafeSet[T comparable](size int) *threadUnsafeSet[T] {
+	set := make(threadUnsafeSet[T], size)
+	return &set
+}
+
+func (set *threadUnsafeSet[T]) Add(key T) bool {
+	_, found := (*set)[key]
+	if found {
+		return false //False if it existed already
+	}
+
+	(*set)[key] = struct{}{}
+
+	return true
+}
+
+func (set *threadUnsafeSet[T]) AddAll(keys ...T) int {
+	added := 0
+	for _, key := range keys {
+		if set.Add(key) {
+			added++
+		}
+	}
+
+	return added
+}
+
+func (set *threadUnsafeSet[T]) Contains(keys ...T) bool {
+	for _, key := range keys {
+		if _, ok := (*set)[key]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (set *threadUnsafeSet[T]) IsSubset(other Set[T]) bool {
+	if set.Cardinality() > other.Cardinality() {
+		return false
+	}
+
+	for elem := range *set {
+		if !other.Contains(elem) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (set *threadUnsafeSet[T]) IsProperSubset(other Set[T]) bool {
+	return set.IsSubset(other) && !set.Equal(other)
+}
+
+func (set *threadUnsafeSet[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(set)
+}
+
+func (set *threadUnsafeSet[T]) IsProperSuperset(other Set[T]) bool {
+	return set.IsSuperset(other) && !set.Equal(other)
+}
+
+func (set *threadUnsafeSet[T]) Union(other Set[T]) Set[T] {
+	union := newThreadUnsafeSet[T](len(*set) + other.Cardinality())
+	for elem := range *set {
+		union.Add(elem)
+	}
+	other.Each(func(elem T) bool {
+		union.Add(elem)
+		return false
+	})
+
+	return union
+}
+
+func (set *threadUnsafeSet[T]) Intersect(other Set[T]) Set[T] {
+	intersection := newThreadUnsafeSet[T](0)
+	// loop over smaller set
+	if set.Cardinality() < other.Cardinality() {
+		for elem := range *set {
+			if other.Contains(elem) {
+				intersection.Add(elem)
+			}
+		}
+	} else {
+		other.Each(func(elem T) bool {
+			if set.Contains(elem) {
+				intersection.Add(elem)
+			}
+			return false
+		})
+	}
+
+	return intersection
+}
+
+func (set *threadUnsafeSet[T]) Difference(other Set[T]) Set[T] {
+	difference := newThreadUnsafeSet[T](0)
+	for elem := range *set {
+		if !other.Contains(elem) {
+			difference.Add(elem)
+		}
+	}
+
+	return difference
+}
+
+func (set *threadUnsafeSet[T]) SymmetricDifference(other Set[T]) Set[T] {
+	sdo := set.Difference(other)
+	ods := other.Difference(set)
+
+	return sdo.Union(ods)
+}
+
+func (set *threadUnsafeSet[T]) Clear() {
+	*set = make(threadUnsafeSet[T])
+}
+
+func (set *threadUnsafeSet[T]) Remove(i T) {
+	delete(*set, i)
+}
+
+func (set *threadUnsafeSet[T]) RemoveAll(keys ...T) int {
+	removed := 0
+	for _, key := range keys {
+		if _, found := (*set)[key]; found {
+			delete(*set, key)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+func (set *threadUnsafeSet[T]) Cardinality() int {
+	return len(*set)
+}
+
+func (set *threadUnsafeSet[T]) Length() int {
+	return len(*set)
+}
+
+func (set *threadUnsafeSet[T]) Each(callback func(T) bool) {
+	for elem := range *set {
+		if callback(elem) {
+			break
+		}
+	}
+}
+
+func (set *threadUnsafeSet[T]) Map(transform func(T) T) Set[T] {
+	mapped := newThreadUnsafeSet[T](len(*set))
+	for elem := range *set {
+		mapped.Add(transform(elem))
+	}
+
+	return mapped
+}
+
+func (set *threadUnsafeSet[T]) Any(predicate func(T) bool) bool {
+	for elem := range *set {
+		if predicate(elem) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (set *threadUnsafeSet[T]) All(predicate func(T) bool) bool {
+	for elem := range *set {
+		if !predicate(elem) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (set *threadUnsafeSet[T]) Iter() <-chan T {
+	ch := make(chan T)
+
+	go func() {
+		for elem := range *set {
+			ch <- elem
+		}
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (set *threadUnsafeSet[T]) Equal(other Set[T]) bool {
+	if set.Cardinality() != other.Cardinality() {
+		return false
+	}
+
+	for elem := range *set {
+		if !other.Contains(elem) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (set *threadUnsafeSet[T]) Clone() Set[T] {
+	clonedSet := newThreadUnsafeSet[T](len(*set))
+	for elem := range *set {
+		clonedSet.Add(elem)
+	}
+
+	return clonedSet
+}
+
+func (set *threadUnsafeSet[T]) String() string {
+	items := make([]string, 0, len(*set))
+	for elem := range *set {
+		items = append(items, fmt.Sprintf("%v", elem))
+	}
+
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+func (set *threadUnsafeSet[T]) Pop() (T, bool) {
+	for item := range *set {
+		delete(*set, item)
+		return item, true
+	}
+
+	var zero T
+	return zero, false
+}
+
+func (set *threadUnsafeSet[T]) ToSlice() []T {
+	keys := make([]T, 0, set.Cardinality())
+	for elem := range *set {
+		keys = append(keys, elem)
+	}
+
+	return keys
+}
+
+// MarshalJSON creates a JSON array from the set, it marshals all elements
+func (set *threadUnsafeSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(set.ToSlice())
+}
+
+// UnmarshalJSON recreates a set from a JSON array. Since the element
+// type is known, every array item is decoded directly into a T.
+func (set *threadUnsafeSet[T]) UnmarshalJSON(b []byte) error {
+	var items []T
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+
+	return nil
+}
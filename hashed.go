@@ -0,0 +1,247 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+// HashedSet is a Set caching its Hash, see NewHashedSet.
+type HashedSet interface {
+	Set
+
+	// Determines if two sets are equal to each
+	// other like Equal, but first compares their
+	// cached hashes, so that sets with different
+	// elements are told apart without looking at
+	// them. The elements are only compared when
+	// the hashes are the same. The short-circuit
+	// requires other to be a HashedSet as well:
+	// for any other set, EqualFast is Equal.
+	EqualFast(other Set) bool
+}
+
+// NewHashedSet creates and returns a reference to a set from an existing
+// slice that keeps its Hash up to date as elements are added and
+// removed, so that Hash costs nothing and EqualFast can tell sets apart
+// by their hashes, e.g. when comparing sets against a large set that
+// rarely changes. Adding and removing elements cost an extra element
+// hash each. Operations on the resulting set are thread-safe.
+//
+// Clone and Snapshot return a HashedSet, other operations deriving a new
+// set, such as Union or Map, return ordinary thread-safe sets.
+func NewHashedSet(s ...interface{}) HashedSet {
+	set := newHashedSet()
+	set.insert(s...)
+	return set
+}
+
+// hashedSet is the thread-safe set returned by NewHashedSet. It embeds
+// the thread-safe set holding its elements, so every read is that set's
+// own, and overrides the operations adding and removing elements to
+// update hash under the same write lock. As the hash of a set is the XOR
+// of the mixed hashes of its elements, adding or removing an element
+// flips its own bits in, or out of, hash.
+type hashedSet struct {
+	setWrapper
+	objects *threadSafeSet
+	hash    uint64
+}
+
+func newHashedSet() *hashedSet {
+	objects := newThreadSafeSet()
+	set := &hashedSet{objects: &objects}
+	set.setWrapper = setWrapper{Set: &objects, self: set}
+	return set
+}
+
+// insert adds items and returns how many were not in the set yet,
+// callers must hold the write lock.
+func (set *hashedSet) insert(items ...interface{}) int {
+	added := 0
+	for _, item := range items {
+		if set.objects.objects.Add(item) {
+			set.hash ^= mixHash(hashElement(item))
+			added++
+		}
+	}
+	return added
+}
+
+// remove removes item and reports whether it was in the set, callers must
+// hold the write lock.
+func (set *hashedSet) remove(item interface{}) bool {
+	if _, found := set.objects.objects[item]; !found {
+		return false
+	}
+	delete(set.objects.objects, item)
+	set.hash ^= mixHash(hashElement(item))
+	return true
+}
+
+func (set *hashedSet) Hash() uint64 {
+	set.objects.mutex.RLock()
+	defer set.objects.mutex.RUnlock()
+
+	return set.hash
+}
+
+func (set *hashedSet) EqualFast(other Set) bool {
+	if o, ok := other.(*hashedSet); ok && o != set && set.Hash() != o.Hash() {
+		return false
+	}
+	return set.Equal(other)
+}
+
+func (set *hashedSet) Add(i interface{}) bool {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.insert(i) == 1
+}
+
+func (set *hashedSet) AddAll(i ...interface{}) int {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.insert(i...)
+}
+
+func (set *hashedSet) AddIfNotContains(candidate, guard interface{}) bool {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	if _, found := set.objects.objects[guard]; found {
+		return false
+	}
+	return set.insert(candidate) == 1
+}
+
+func (set *hashedSet) Remove(i interface{}) {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.remove(i)
+}
+
+func (set *hashedSet) RemoveAll(i ...interface{}) int {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	removed := 0
+	for _, item := range i {
+		if set.remove(item) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// RetainAll reads the elements of other before taking the lock, so that
+// other may be the set itself.
+func (set *hashedSet) RetainAll(other Set) int {
+	keep := NewThreadUnsafeSetFromSlice(nonNil(other).ToSlice())
+
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	removed := 0
+	for item := range set.objects.objects {
+		if !keep.Contains(item) && set.remove(item) {
+			removed++
+		}
+	}
+	return removed
+}
+
+func (set *hashedSet) SubtractSet(other Set) int {
+	return set.RemoveAll(nonNil(other).ToSlice()...)
+}
+
+func (set *hashedSet) Clear() {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.objects.objects = newThreadUnsafeSet()
+	set.hash = 0
+}
+
+func (set *hashedSet) ClearRetainingCapacity() {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.objects.objects.clear()
+	set.hash = 0
+}
+
+func (set *hashedSet) reset(items []interface{}) {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.objects.objects.clear()
+	set.hash = 0
+	set.insert(items...)
+}
+
+func (set *hashedSet) ReplaceAll(i ...interface{}) {
+	set.reset(i)
+}
+
+func (set *hashedSet) Pop() interface{} {
+	item, _ := set.TryPop()
+	return item
+}
+
+func (set *hashedSet) TryPop() (interface{}, bool) {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	for item := range set.objects.objects {
+		set.remove(item)
+		return item, true
+	}
+	return nil, false
+}
+
+func (set *hashedSet) Drain() []interface{} {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	items := set.objects.objects.ToSlice()
+	set.objects.objects.clear()
+	set.hash = 0
+	return items
+}
+
+func (set *hashedSet) Clone() Set {
+	set.objects.mutex.RLock()
+	defer set.objects.mutex.RUnlock()
+
+	clone := newHashedSet()
+	clone.objects.objects = *set.objects.objects.Clone().(*threadUnsafeSet)
+	clone.hash = set.hash
+	return clone
+}
+
+func (set *hashedSet) Snapshot() Set {
+	return set.Clone()
+}
@@ -0,0 +1,110 @@
+package mapset
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// assertHashCurrent fails when the cached hash of set is not the hash of
+// its elements.
+func assertHashCurrent(t *testing.T, op string, set Set) {
+	t.Helper()
+	if want := NewSet(set.ToSlice()...).Hash(); set.Hash() != want {
+		t.Errorf("%s should keep the hash up to date, got %x want %x", op, set.Hash(), want)
+	}
+}
+
+func Test_HashedSetHash(t *testing.T) {
+	a := NewHashedSet(1, 2, 3)
+	assertHashCurrent(t, "NewHashedSet", a)
+
+	a.Add(4)
+	a.Add(4)
+	assertHashCurrent(t, "Add", a)
+	a.AddAll(5, 6, 1)
+	assertHashCurrent(t, "AddAll", a)
+	a.AddIfNotContains(7, 8)
+	assertHashCurrent(t, "AddIfNotContains", a)
+	a.AddSet(NewSet("a", "b"))
+	assertHashCurrent(t, "AddSet", a)
+
+	a.Remove(1)
+	a.Remove(1)
+	assertHashCurrent(t, "Remove", a)
+	a.RemoveAll(2, 3, 9)
+	assertHashCurrent(t, "RemoveAll", a)
+	a.SubtractSet(NewSet("a"))
+	assertHashCurrent(t, "SubtractSet", a)
+	a.RetainAll(NewSet(4, 5, 6, 7, "b"))
+	assertHashCurrent(t, "RetainAll", a)
+	a.RetainAll(a)
+	assertHashCurrent(t, "RetainAll with itself", a)
+	a.Pop()
+	assertHashCurrent(t, "Pop", a)
+
+	a.ReplaceAll(1, 2)
+	assertHashCurrent(t, "ReplaceAll", a)
+	NewSet(3).CopyTo(a)
+	assertHashCurrent(t, "CopyTo", a)
+	if err := a.UnmarshalText([]byte("x,y")); err != nil {
+		t.Fatal(err)
+	}
+	assertHashCurrent(t, "UnmarshalText", a)
+
+	clone := a.Clone()
+	if _, ok := clone.(HashedSet); !ok {
+		t.Errorf("Clone should return a HashedSet, got %T", clone)
+	}
+	assertHashCurrent(t, "Clone", clone)
+
+	a.Drain()
+	assertHashCurrent(t, "Drain", a)
+	a.AddAll(1, 2)
+	a.Clear()
+	assertHashCurrent(t, "Clear", a)
+	a.AddAll(1, 2)
+	a.ClearRetainingCapacity()
+	assertHashCurrent(t, "ClearRetainingCapacity", a)
+}
+
+func Test_HashedSetEqualFast(t *testing.T) {
+	a := NewHashedSet(1, 2, 3)
+	b := NewHashedSet(3, 2, 1)
+
+	if !a.EqualFast(b) || !a.EqualFast(a) {
+		t.Error("EqualFast should report sets with the same elements as equal")
+	}
+	b.Add(4)
+	if a.EqualFast(b) {
+		t.Error("EqualFast should report sets with different elements as not equal")
+	}
+	b.Remove(4)
+	if !a.EqualFast(b) {
+		t.Error("EqualFast should follow the changes of the sets")
+	}
+
+	if !a.EqualFast(NewSet(1, 2, 3)) || a.EqualFast(NewSet(1, 2)) {
+		t.Error("EqualFast should fall back to Equal for other sets")
+	}
+}
+
+func Test_HashedSetConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	a := NewHashedSet()
+	var wg sync.WaitGroup
+	for i := 0; i < N; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a.Add(i)
+			if i%2 == 0 {
+				a.Remove(i)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assertHashCurrent(t, "concurrent Add and Remove", a)
+}
@@ -0,0 +1,598 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// IntSet is a set of ints. It mirrors Set, but stores its elements
+// unboxed in a map[int]struct{}, which saves allocating every element as
+// an interface{} and needs no type assertions to read the elements back.
+type IntSet interface {
+	// Adds an element to the set. Returns whether
+	// the item was added.
+	Add(i int) bool
+
+	// Adds all of the given elements to the set.
+	// Returns the number of elements that were
+	// actually added, so duplicates are not counted.
+	AddAll(i ...int) int
+
+	// Returns the number of elements in the set.
+	Cardinality() int
+
+	// Removes all elements from the set, leaving
+	// the empty set.
+	Clear()
+
+	// Returns a clone of the set, duplicating all
+	// keys.
+	Clone() IntSet
+
+	// Returns whether the given items
+	// are all in the set.
+	Contains(i ...int) bool
+
+	// Returns the difference between this set
+	// and other. The returned set will contain
+	// all elements of this set that are not also
+	// elements of other.
+	Difference(other IntSet) IntSet
+
+	// Determines if two sets hold the same
+	// elements.
+	Equal(other IntSet) bool
+
+	// Returns a new set containing only the elements
+	// that exist only in both sets.
+	Intersect(other IntSet) IntSet
+
+	// Determines if every element in this set is in
+	// the other set but the two sets are not equal.
+	IsProperSubset(other IntSet) bool
+
+	// Determines if every element in the other set
+	// is in this set but the two sets are not
+	// equal.
+	IsProperSuperset(other IntSet) bool
+
+	// Determines if every element in this set is in
+	// the other set.
+	IsSubset(other IntSet) bool
+
+	// Determines if every element in the other set
+	// is in this set.
+	IsSuperset(other IntSet) bool
+
+	// Determines if the two sets have at least one
+	// element in common.
+	Intersects(other IntSet) bool
+
+	// Determines if the two sets have no element
+	// in common.
+	IsDisjoint(other IntSet) bool
+
+	// Iterates over elements and executes the passed func against each element.
+	// If passed func returns true, stop iteration at the time.
+	Each(func(int) bool)
+
+	// Returns a channel of elements that you can
+	// range over. The set stays read-locked until
+	// the channel is drained, use Iterator to stop
+	// early.
+	Iter() <-chan int
+
+	// Returns an IntIterator that you can use to
+	// range over the set and stop at any point.
+	Iterator() *IntIterator
+
+	// Remove a single element from the set.
+	Remove(i int)
+
+	// Removes all of the given elements from the set.
+	// Returns the number of elements that were
+	// actually removed.
+	RemoveAll(i ...int) int
+
+	// Provides a convenient string representation
+	// of the current state of the set.
+	String() string
+
+	// Returns a new set with all elements which are
+	// in either this set or the other set but not in both.
+	SymmetricDifference(other IntSet) IntSet
+
+	// Returns a new set with all elements in both sets.
+	Union(other IntSet) IntSet
+
+	// Removes and returns an arbitrary item from the
+	// set. The boolean reports whether an item was
+	// removed, it is false when the set is empty.
+	Pop() (int, bool)
+
+	// Returns all subsets of the set, the empty set
+	// and the set itself included, as sets of the
+	// same kind. A slice is returned in place of a
+	// set of sets since an IntSet only holds ints.
+	PowerSet() []IntSet
+
+	// Returns the Cartesian product of this set and
+	// other, every pair of an element of this set
+	// and an element of other.
+	CartesianProduct(other IntSet) []IntPair
+
+	// Returns the members of the set as a slice.
+	ToSlice() []int
+
+	// Returns a Set holding the same elements, e.g.
+	// to pass them to code that only accepts a Set.
+	// Every element is boxed into an interface{},
+	// which allocates for most ints: the conversion
+	// costs an allocation per element and the Set
+	// takes several times the memory of the IntSet.
+	ToSet() Set
+
+	// Encodes the set as a JSON array of ints.
+	MarshalJSON() ([]byte, error)
+
+	// Adds the ints of a JSON array to the set, any
+	// element that isn't an int is an error.
+	UnmarshalJSON(b []byte) error
+}
+
+// An IntPair represents a 2-tuple of ints, the IntSet counterpart of
+// OrderedPair.
+type IntPair struct {
+	First  int
+	Second int
+}
+
+// intSet is a thread-safe IntSet, the int counterpart of threadSafeSet.
+type intSet struct {
+	items map[int]struct{}
+	mutex sync.RWMutex
+}
+
+// NewIntSet creates and returns a reference to a set holding the given
+// ints. Operations on the resulting set are thread-safe.
+func NewIntSet(i ...int) IntSet {
+	set := newIntSet(len(i))
+	for _, item := range i {
+		set.items[item] = struct{}{}
+	}
+	return set
+}
+
+// NewIntSetFromSlice creates and returns a reference to a set from an
+// existing slice of ints. Operations on the resulting set are thread-safe.
+func NewIntSetFromSlice(s []int) IntSet {
+	return NewIntSet(s...)
+}
+
+func newIntSet(size int) *intSet {
+	return &intSet{items: make(map[int]struct{}, size)}
+}
+
+// rlockWith read-locks the set and other in the order used by rlockAll,
+// and returns a func releasing them.
+func (set *intSet) rlockWith(other IntSet) (*intSet, func()) {
+	o := set.coerce(other)
+	return o, rlockAll(&set.mutex, &o.mutex)
+}
+
+// coerce returns other if it is an *intSet, and otherwise a private copy
+// of it, so that binary operations accept any IntSet.
+func (set *intSet) coerce(other IntSet) *intSet {
+	if o, ok := other.(*intSet); ok {
+		return o
+	}
+
+	o := newIntSet(other.Cardinality())
+	other.Each(func(elem int) bool {
+		o.items[elem] = struct{}{}
+		return false
+	})
+	return o
+}
+
+// isSubset reports whether every element of set is in other, both must be
+// read-locked.
+func (set *intSet) isSubset(other *intSet) bool {
+	if len(set.items) > len(other.items) {
+		return false
+	}
+	for elem := range set.items {
+		if _, found := other.items[elem]; !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *intSet) Add(i int) bool {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	if _, found := set.items[i]; found {
+		return false
+	}
+	set.items[i] = struct{}{}
+	return true
+}
+
+func (set *intSet) AddAll(i ...int) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	added := 0
+	for _, item := range i {
+		if _, found := set.items[item]; !found {
+			set.items[item] = struct{}{}
+			added++
+		}
+	}
+	return added
+}
+
+func (set *intSet) Cardinality() int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return len(set.items)
+}
+
+func (set *intSet) Clear() {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	set.items = make(map[int]struct{})
+}
+
+func (set *intSet) Clone() IntSet {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	clone := newIntSet(len(set.items))
+	for elem := range set.items {
+		clone.items[elem] = struct{}{}
+	}
+	return clone
+}
+
+func (set *intSet) Contains(i ...int) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	for _, item := range i {
+		if _, found := set.items[item]; !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *intSet) Difference(other IntSet) IntSet {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	difference := newIntSet(0)
+	for elem := range set.items {
+		if _, found := o.items[elem]; !found {
+			difference.items[elem] = struct{}{}
+		}
+	}
+	return difference
+}
+
+func (set *intSet) Equal(other IntSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return len(set.items) == len(o.items) && set.isSubset(o)
+}
+
+func (set *intSet) Intersect(other IntSet) IntSet {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	smaller, larger := set, o
+	if len(smaller.items) > len(larger.items) {
+		smaller, larger = larger, smaller
+	}
+
+	intersection := newIntSet(0)
+	for elem := range smaller.items {
+		if _, found := larger.items[elem]; found {
+			intersection.items[elem] = struct{}{}
+		}
+	}
+	return intersection
+}
+
+func (set *intSet) IsProperSubset(other IntSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return len(set.items) < len(o.items) && set.isSubset(o)
+}
+
+func (set *intSet) IsProperSuperset(other IntSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return len(o.items) < len(set.items) && o.isSubset(set)
+}
+
+func (set *intSet) IsSubset(other IntSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.isSubset(o)
+}
+
+func (set *intSet) IsSuperset(other IntSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return o.isSubset(set)
+}
+
+func (set *intSet) Each(callback func(int) bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	for elem := range set.items {
+		if callback(elem) {
+			break
+		}
+	}
+}
+
+func (set *intSet) Remove(i int) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	delete(set.items, i)
+}
+
+func (set *intSet) RemoveAll(i ...int) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	removed := 0
+	for _, item := range i {
+		if _, found := set.items[item]; found {
+			delete(set.items, item)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (set *intSet) String() string {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	items := make([]string, 0, len(set.items))
+	for elem := range set.items {
+		items = append(items, fmt.Sprintf("%d", elem))
+	}
+
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+func (set *intSet) SymmetricDifference(other IntSet) IntSet {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	sd := newIntSet(0)
+	for elem := range set.items {
+		if _, found := o.items[elem]; !found {
+			sd.items[elem] = struct{}{}
+		}
+	}
+	for elem := range o.items {
+		if _, found := set.items[elem]; !found {
+			sd.items[elem] = struct{}{}
+		}
+	}
+	return sd
+}
+
+func (set *intSet) Union(other IntSet) IntSet {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	union := newIntSet(len(set.items) + len(o.items))
+	for elem := range set.items {
+		union.items[elem] = struct{}{}
+	}
+	for elem := range o.items {
+		union.items[elem] = struct{}{}
+	}
+	return union
+}
+
+func (set *intSet) Pop() (int, bool) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	for elem := range set.items {
+		delete(set.items, elem)
+		return elem, true
+	}
+	return 0, false
+}
+
+func (set *intSet) ToSlice() []int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	s := make([]int, 0, len(set.items))
+	for elem := range set.items {
+		s = append(s, elem)
+	}
+	return s
+}
+
+func (set *intSet) ToSet() Set {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	s := newThreadSafeSetWithSize(len(set.items))
+	for elem := range set.items {
+		s.objects[elem] = struct{}{}
+	}
+	return &s
+}
+
+func (set *intSet) Intersects(other IntSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	smaller, larger := set, o
+	if len(smaller.items) > len(larger.items) {
+		smaller, larger = larger, smaller
+	}
+
+	for elem := range smaller.items {
+		if _, found := larger.items[elem]; found {
+			return true
+		}
+	}
+	return false
+}
+
+func (set *intSet) IsDisjoint(other IntSet) bool {
+	return !set.Intersects(other)
+}
+
+func (set *intSet) Iter() <-chan int {
+	return iterInts(set)
+}
+
+func (set *intSet) Iterator() *IntIterator {
+	return intIterator(set)
+}
+
+func (set *intSet) PowerSet() []IntSet {
+	return intPowerSet(set, newIntSet(0))
+}
+
+func (set *intSet) CartesianProduct(other IntSet) []IntPair {
+	return intCartesianProduct(set, other)
+}
+
+func (set *intSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(set.ToSlice())
+}
+
+func (set *intSet) UnmarshalJSON(b []byte) error {
+	return unmarshalIntsJSON(set, b)
+}
+
+// The following helpers implement the IntSet methods that intSet and
+// bitSet share on top of the rest of the interface, so they take no lock
+// of their own.
+
+// iterInts returns a channel fed with the elements of set by a goroutine,
+// which holds the read lock of set until the channel is drained.
+func iterInts(set IntSet) <-chan int {
+	ch := make(chan int, iterBufferSize)
+	go func() {
+		set.Each(func(elem int) bool {
+			ch <- elem
+			return false
+		})
+		close(ch)
+	}()
+
+	return ch
+}
+
+// intIterator returns an IntIterator over the elements of set.
+func intIterator(set IntSet) *IntIterator {
+	ch := make(chan int)
+	stop := make(chan struct{})
+
+	go func() {
+		set.Each(func(elem int) bool {
+			select {
+			case <-stop:
+				return true
+			case ch <- elem:
+				return false
+			}
+		})
+		close(ch)
+	}()
+
+	return &IntIterator{C: ch, stop: stop}
+}
+
+// intPowerSet returns the subsets of set, each a clone of empty holding
+// some of its elements.
+func intPowerSet(set, empty IntSet) []IntSet {
+	powSet := []IntSet{empty}
+	for _, elem := range set.ToSlice() {
+		for _, subset := range powSet {
+			withElem := subset.Clone()
+			withElem.Add(elem)
+			powSet = append(powSet, withElem)
+		}
+	}
+	return powSet
+}
+
+// intCartesianProduct returns the pairs of an element of set and an
+// element of other. The sets are read one after the other, so that no
+// lock is held while the other one is taken.
+func intCartesianProduct(set, other IntSet) []IntPair {
+	firsts, seconds := set.ToSlice(), other.ToSlice()
+
+	product := make([]IntPair, 0, len(firsts)*len(seconds))
+	for _, first := range firsts {
+		for _, second := range seconds {
+			product = append(product, IntPair{First: first, Second: second})
+		}
+	}
+	return product
+}
+
+// unmarshalIntsJSON decodes a JSON array of ints and adds them to set.
+func unmarshalIntsJSON(set IntSet, b []byte) error {
+	var items []int
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
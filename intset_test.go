@@ -0,0 +1,167 @@
+package mapset
+
+import (
+	"encoding/json"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func Test_IntSetBasics(t *testing.T) {
+	s := NewIntSet(1, 2)
+	if s.Add(2) || !s.Add(3) {
+		t.Error("Add should only report new elements")
+	}
+	if n := s.AddAll(3, 4, 5); n != 2 {
+		t.Errorf("AddAll should add 2 new elements, got %d", n)
+	}
+	if s.Cardinality() != 5 || !s.Contains(1, 5) || s.Contains(6) {
+		t.Errorf("unexpected elements in %v", s)
+	}
+
+	s.Remove(5)
+	if n := s.RemoveAll(4, 5); n != 1 {
+		t.Errorf("RemoveAll should remove 1 element, got %d", n)
+	}
+
+	slice := s.ToSlice()
+	sort.Ints(slice)
+	if len(slice) != 3 || slice[0] != 1 || slice[1] != 2 || slice[2] != 3 {
+		t.Errorf("ToSlice should return [1 2 3], got %v", slice)
+	}
+
+	if elem, ok := NewIntSet(7).Pop(); !ok || elem != 7 {
+		t.Errorf("Pop should return 7, got %d, %v", elem, ok)
+	}
+	if _, ok := NewIntSet().Pop(); ok {
+		t.Error("Pop on the empty set should report false")
+	}
+
+	clone := s.Clone()
+	s.Clear()
+	if s.Cardinality() != 0 || clone.Cardinality() != 3 {
+		t.Error("Clear should empty the set without affecting its clone")
+	}
+
+	if str := NewIntSetFromSlice([]int{42}).String(); str != "Set{42}" {
+		t.Errorf("unexpected string representation %q", str)
+	}
+}
+
+func Test_IntSetOperations(t *testing.T) {
+	a := NewIntSet(1, 2, 3)
+	b := NewIntSet(2, 3, 4)
+
+	if !a.Union(b).Equal(NewIntSet(1, 2, 3, 4)) {
+		t.Errorf("unexpected union %v", a.Union(b))
+	}
+	if !a.Intersect(b).Equal(NewIntSet(2, 3)) {
+		t.Errorf("unexpected intersection %v", a.Intersect(b))
+	}
+	if !a.Difference(b).Equal(NewIntSet(1)) {
+		t.Errorf("unexpected difference %v", a.Difference(b))
+	}
+	if !a.SymmetricDifference(b).Equal(NewIntSet(1, 4)) {
+		t.Errorf("unexpected symmetric difference %v", a.SymmetricDifference(b))
+	}
+
+	sub := NewIntSet(1, 2)
+	if !sub.IsSubset(a) || !sub.IsProperSubset(a) || !a.IsSuperset(sub) || !a.IsProperSuperset(sub) {
+		t.Error("{1, 2} should be a proper subset of {1, 2, 3}")
+	}
+	if !a.IsSubset(a) || a.IsProperSubset(a) || a.IsProperSuperset(a) {
+		t.Error("a set should be a subset, but not a proper subset, of itself")
+	}
+	if a.Equal(b) || !a.Equal(a.Clone()) {
+		t.Error("a set should only equal its clone")
+	}
+
+	if !a.ToSet().Equal(NewSet(1, 2, 3)) {
+		t.Errorf("ToSet should hold the same elements, got %v", a.ToSet())
+	}
+}
+
+func Test_IntSetConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s := NewIntSet()
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for i := 0; i < N; i++ {
+		go func(i int) {
+			s.Add(i)
+			s.Union(s)
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	if s.Cardinality() != N {
+		t.Errorf("expected %d elements, got %d", N, s.Cardinality())
+	}
+}
+
+func Test_IntSetIterationAndCodecs(t *testing.T) {
+	constructors := map[string]func(...int) IntSet{
+		"map":  NewIntSet,
+		"bits": func(i ...int) IntSet { return newBitSetWith(100, i...) },
+	}
+
+	for name, newSet := range constructors {
+		s := newSet(1, 2, 3)
+
+		sum := 0
+		for elem := range s.Iter() {
+			sum += elem
+		}
+		if sum != 6 {
+			t.Errorf("%s: Iter should yield 1, 2 and 3, got a sum of %d", name, sum)
+		}
+
+		it := s.Iterator()
+		<-it.C
+		it.Stop()
+		it.Stop()
+		if !s.Add(4) {
+			t.Errorf("%s: a stopped Iterator should release the set", name)
+		}
+
+		if !s.Intersects(newSet(4, 5)) || !s.IsDisjoint(newSet(5, 6)) || s.Intersects(NewIntSet(-1)) {
+			t.Errorf("%s: Intersects is wrong", name)
+		}
+
+		powSet := newSet(1, 2).PowerSet()
+		if len(powSet) != 4 {
+			t.Fatalf("%s: PowerSet should return 4 subsets, got %d", name, len(powSet))
+		}
+		for _, expected := range []IntSet{NewIntSet(), NewIntSet(1), NewIntSet(2), NewIntSet(1, 2)} {
+			found := false
+			for _, subset := range powSet {
+				found = found || subset.Equal(expected)
+			}
+			if !found {
+				t.Errorf("%s: PowerSet is missing %v", name, expected)
+			}
+		}
+
+		product := newSet(1, 2).CartesianProduct(NewIntSet(7))
+		sort.Slice(product, func(i, j int) bool { return product[i].First < product[j].First })
+		if len(product) != 2 || product[0] != (IntPair{1, 7}) || product[1] != (IntPair{2, 7}) {
+			t.Errorf("%s: unexpected Cartesian product %v", name, product)
+		}
+
+		b, err := json.Marshal(newSet(3))
+		if err != nil || string(b) != "[3]" {
+			t.Errorf("%s: MarshalJSON should return [3], got %s, %v", name, b, err)
+		}
+
+		decoded := newSet()
+		if err := json.Unmarshal([]byte("[1,2,2,5]"), decoded); err != nil || !decoded.Equal(NewIntSet(1, 2, 5)) {
+			t.Errorf("%s: UnmarshalJSON should decode 1, 2 and 5, got %v, %v", name, decoded, err)
+		}
+		if err := json.Unmarshal([]byte(`[1,"a"]`), newSet()); err == nil {
+			t.Errorf("%s: UnmarshalJSON should reject elements that aren't ints", name)
+		}
+	}
+}
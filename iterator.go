@@ -25,8 +25,27 @@ SOFTWARE.
 
 package mapset
 
+import "sync"
+
+// iterBufferSize is the capacity of the channels returned by Iter. Buffering
+// lets the producer goroutine run ahead of the consumer, amortising the
+// cost of handing elements over one at a time.
+const iterBufferSize = 16
+
 // Iterator defines an iterator over a Set, its C channel can be used to range over the Set's
 // elements.
+//
+// Unlike the channel returned by Set.Iter, an Iterator may be abandoned before all elements
+// were received, as long as Stop is called: it ends the producer goroutine and, for
+// thread-safe sets, releases the read lock, which an abandoned Iter channel holds forever.
+//
+//	it := set.Iterator()
+//	defer it.Stop()
+//	for elem := range it.C {
+//		if found(elem) {
+//			break
+//		}
+//	}
 type Iterator struct {
 	C    <-chan interface{}
 	stop chan struct{}
@@ -47,6 +66,209 @@ func (i *Iterator) Stop() {
 	}
 }
 
+// PairIterator is an Iterator over OrderedPair values, its C channel can be used to range
+// over the pairs. Like an Iterator, it may be abandoned early as long as Stop is called.
+type PairIterator struct {
+	C    <-chan OrderedPair
+	stop chan struct{}
+}
+
+// Stop stops the PairIterator, no further pairs will be received on C, C will be closed.
+func (i *PairIterator) Stop() {
+	// Allows for Stop() to be called multiple times
+	// (close() panics when called on already closed channel)
+	defer func() {
+		recover()
+	}()
+
+	close(i.stop)
+
+	// Exhaust any remaining pairs.
+	for range i.C {
+	}
+}
+
+// IntIterator is the Iterator of an IntSet, its C channel can be used to
+// range over the ints of the set. It may be abandoned early as long as Stop
+// is called.
+type IntIterator struct {
+	C    <-chan int
+	stop chan struct{}
+}
+
+// Stop stops the IntIterator, no further ints will be received on C, C will be closed.
+func (i *IntIterator) Stop() {
+	// Allows for Stop() to be called multiple times
+	// (close() panics when called on already closed channel)
+	defer func() {
+		recover()
+	}()
+
+	close(i.stop)
+
+	// Exhaust any remaining ints.
+	for range i.C {
+	}
+}
+
+// SliceIterator iterates over a snapshot of the elements of a Set, taken
+// when it is created with NewSliceIterator. No goroutine or channel is
+// involved, so it may be abandoned at any point without being stopped,
+// and it never holds the lock of a thread-safe set while it is used. The
+// price is the snapshot, which holds every element of the set, and
+// changes to the set made after it was taken are not seen.
+//
+//	it := NewSliceIterator(set)
+//	for elem, ok := it.Next(); ok; elem, ok = it.Next() {
+//		if found(elem) {
+//			break
+//		}
+//	}
+type SliceIterator struct {
+	items []interface{}
+	next  int
+}
+
+// NewSliceIterator returns a SliceIterator over a snapshot of the
+// elements of set.
+func NewSliceIterator(set Set) *SliceIterator {
+	return &SliceIterator{items: set.Elements()}
+}
+
+// Next returns the next element and true, or nil and false once every
+// element was returned.
+func (i *SliceIterator) Next() (interface{}, bool) {
+	if i.next >= len(i.items) {
+		return nil, false
+	}
+
+	item := i.items[i.next]
+	i.items[i.next] = nil
+	i.next++
+	return item, true
+}
+
+// iterSlice returns a channel of iterBufferSize elements fed with items by
+// a new goroutine, which closes it after sending the last one.
+func iterSlice(items []interface{}) <-chan interface{} {
+	ch := make(chan interface{}, iterBufferSize)
+	go func() {
+		for _, item := range items {
+			ch <- item
+		}
+		close(ch)
+	}()
+
+	return ch
+}
+
+// teeSlice returns two channels like iterSlice, each fed with items by
+// its own goroutine, so that a slow consumer never holds up the other.
+func teeSlice(items []interface{}) (<-chan interface{}, <-chan interface{}) {
+	return iterSlice(items), iterSlice(items)
+}
+
+// cartesianProductIterator returns a PairIterator sending every pair of an
+// element of first followed by an element of second.
+func cartesianProductIterator(first, second []interface{}) *PairIterator {
+	ch := make(chan OrderedPair)
+	stopCh := make(chan struct{})
+
+	go func() {
+	L:
+		for _, i := range first {
+			for _, j := range second {
+				select {
+				case <-stopCh:
+					break L
+				case ch <- OrderedPair{First: i, Second: j}:
+				}
+			}
+		}
+		close(ch)
+	}()
+
+	return &PairIterator{C: ch, stop: stopCh}
+}
+
+// eachParallel calls callback on every item from workers goroutines, and
+// returns the errors it returned.
+func eachParallel(items []interface{}, workers int, callback func(interface{}) error) []error {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	ch := make(chan interface{}, iterBufferSize)
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		errs  []error
+	)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for item := range ch {
+				if err := callback(item); err != nil {
+					mutex.Lock()
+					errs = append(errs, err)
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, item := range items {
+		ch <- item
+	}
+	close(ch)
+	wg.Wait()
+
+	return errs
+}
+
+// powerSetIterator returns an Iterator sending every subset of items, each one
+// built by newSubset. A subset holds the items whose bit is set in a mask which
+// is incremented after every subset, so only one subset is alive at a time.
+func powerSetIterator(items []interface{}, newSubset func(items []interface{}) Set) *Iterator {
+	iterator, ch, stopCh := newIterator()
+
+	go func() {
+		mask := make([]bool, len(items))
+		subset := make([]interface{}, 0, len(items))
+	L:
+		for {
+			subset = subset[:0]
+			for i, in := range mask {
+				if in {
+					subset = append(subset, items[i])
+				}
+			}
+
+			select {
+			case <-stopCh:
+				break L
+			case ch <- newSubset(subset):
+			}
+
+			i := 0
+			for ; i < len(mask) && mask[i]; i++ {
+				mask[i] = false
+			}
+			if i == len(mask) {
+				break
+			}
+			mask[i] = true
+		}
+		close(ch)
+	}()
+
+	return iterator
+}
+
 // newIterator returns a new Iterator instance together with its item and stop channels.
 func newIterator() (*Iterator, chan<- interface{}, <-chan struct{}) {
 	c := make(chan interface{})
@@ -30,3 +30,17 @@ func ExampleIterator() {
 
 	// Output: Found &{Name:John}
 }
+
+func ExampleSliceIterator() {
+	set := NewSet(1, 2, 3)
+
+	sum := 0
+	it := NewSliceIterator(set)
+	for elem, ok := it.Next(); ok; elem, ok = it.Next() {
+		sum += elem.(int)
+	}
+
+	fmt.Println(sum)
+
+	// Output: 6
+}
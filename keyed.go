@@ -0,0 +1,282 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+// keyedSet is the thread-safe set returned by NewSetByKey. It embeds the
+// thread-safe set holding its elements, so every read is that set's own,
+// and overrides the operations adding, looking up and removing elements
+// to go through the key of each element, under the same lock.
+type keyedSet struct {
+	setWrapper
+	objects *threadSafeSet
+	keyFunc func(interface{}) interface{}
+	keys    map[interface{}]interface{}
+}
+
+func newKeyedSet(keyFunc func(interface{}) interface{}) *keyedSet {
+	objects := newThreadSafeSet()
+	set := &keyedSet{
+		objects: &objects,
+		keyFunc: keyFunc,
+		keys:    make(map[interface{}]interface{}),
+	}
+	set.setWrapper = setWrapper{Set: &objects, self: set}
+	return set
+}
+
+// insert adds the items whose key is not in the set yet and returns how
+// many were added, callers must hold the write lock.
+func (set *keyedSet) insert(items ...interface{}) int {
+	added := 0
+	for _, item := range items {
+		key := set.keyFunc(item)
+		if _, found := set.keys[key]; found {
+			continue
+		}
+		set.keys[key] = item
+		set.objects.objects[item] = struct{}{}
+		added++
+	}
+	return added
+}
+
+// remove removes the element stored under the key of item, callers must
+// hold the write lock.
+func (set *keyedSet) remove(item interface{}) bool {
+	key := set.keyFunc(item)
+	elem, found := set.keys[key]
+	if !found {
+		return false
+	}
+	delete(set.keys, key)
+	delete(set.objects.objects, elem)
+	return true
+}
+
+func (set *keyedSet) has(item interface{}) bool {
+	_, found := set.keys[set.keyFunc(item)]
+	return found
+}
+
+// keysOf returns the keys of the elements of other. It is called before
+// taking the lock, so that other may be the set itself.
+func (set *keyedSet) keysOf(other Set) map[interface{}]struct{} {
+	keys := make(map[interface{}]struct{})
+	for _, item := range nonNil(other).ToSlice() {
+		keys[set.keyFunc(item)] = struct{}{}
+	}
+	return keys
+}
+
+func (set *keyedSet) Add(i interface{}) bool {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.insert(i) == 1
+}
+
+func (set *keyedSet) AddAll(i ...interface{}) int {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.insert(i...)
+}
+
+func (set *keyedSet) AddIfNotContains(candidate, guard interface{}) bool {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	if set.has(guard) {
+		return false
+	}
+	return set.insert(candidate) == 1
+}
+
+func (set *keyedSet) Contains(i ...interface{}) bool {
+	set.objects.mutex.RLock()
+	defer set.objects.mutex.RUnlock()
+
+	for _, item := range i {
+		if !set.has(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *keyedSet) Has(i interface{}) bool {
+	set.objects.mutex.RLock()
+	defer set.objects.mutex.RUnlock()
+
+	return set.has(i)
+}
+
+func (set *keyedSet) ContainsAll(i ...interface{}) bool {
+	return set.Contains(i...)
+}
+
+func (set *keyedSet) ContainsAny(i ...interface{}) bool {
+	set.objects.mutex.RLock()
+	defer set.objects.mutex.RUnlock()
+
+	for _, item := range i {
+		if set.has(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func (set *keyedSet) Remove(i interface{}) {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.remove(i)
+}
+
+func (set *keyedSet) RemoveAll(i ...interface{}) int {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	removed := 0
+	for _, item := range i {
+		if set.remove(item) {
+			removed++
+		}
+	}
+	return removed
+}
+
+func (set *keyedSet) RetainAll(other Set) int {
+	keep := set.keysOf(other)
+
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	removed := 0
+	for key, elem := range set.keys {
+		if _, found := keep[key]; !found {
+			delete(set.keys, key)
+			delete(set.objects.objects, elem)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (set *keyedSet) SubtractSet(other Set) int {
+	drop := set.keysOf(other)
+
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	removed := 0
+	for key := range drop {
+		if elem, found := set.keys[key]; found {
+			delete(set.keys, key)
+			delete(set.objects.objects, elem)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (set *keyedSet) Clear() {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.keys = make(map[interface{}]interface{})
+	set.objects.objects.clear()
+}
+
+func (set *keyedSet) ClearRetainingCapacity() {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	for key := range set.keys {
+		delete(set.keys, key)
+	}
+	set.objects.objects.clear()
+}
+
+func (set *keyedSet) reset(items []interface{}) {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.keys = make(map[interface{}]interface{})
+	set.objects.objects.clear()
+	set.insert(items...)
+}
+
+func (set *keyedSet) ReplaceAll(i ...interface{}) {
+	set.reset(i)
+}
+
+func (set *keyedSet) Pop() interface{} {
+	item, _ := set.TryPop()
+	return item
+}
+
+func (set *keyedSet) TryPop() (interface{}, bool) {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	for key, elem := range set.keys {
+		delete(set.keys, key)
+		delete(set.objects.objects, elem)
+		return elem, true
+	}
+	return nil, false
+}
+
+func (set *keyedSet) Drain() []interface{} {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	items := make([]interface{}, 0, len(set.keys))
+	for _, elem := range set.keys {
+		items = append(items, elem)
+	}
+	set.keys = make(map[interface{}]interface{})
+	set.objects.objects.clear()
+	return items
+}
+
+func (set *keyedSet) Clone() Set {
+	set.objects.mutex.RLock()
+	defer set.objects.mutex.RUnlock()
+
+	clone := newKeyedSet(set.keyFunc)
+	for key, elem := range set.keys {
+		clone.keys[key] = elem
+		clone.objects.objects[elem] = struct{}{}
+	}
+	return clone
+}
+
+func (set *keyedSet) Snapshot() Set {
+	return set.Clone()
+}
@@ -0,0 +1,111 @@
+package mapset
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func setKey(i interface{}) interface{} {
+	return i.(Set).StringSorted()
+}
+
+func Test_SetByKeyAdd(t *testing.T) {
+	a := NewSetByKey(setKey)
+
+	first := NewSet(1, 2)
+	if !a.Add(first) || !a.Add(NewSet(3)) {
+		t.Fatal("Add should accept elements with new keys")
+	}
+	if a.Add(NewSet(2, 1)) {
+		t.Error("Add should reject an element whose key is already in the set")
+	}
+	if a.Cardinality() != 2 || !a.Contains(NewSet(1, 2), NewSet(3)) || !a.Has(NewSet(2, 1)) {
+		t.Errorf("Contains should look elements up by key, got %v", a)
+	}
+	if !a.ContainsAny(NewSet(4), NewSet(2, 1)) || a.ContainsAny(NewSet(4)) {
+		t.Error("ContainsAny should look elements up by key")
+	}
+	if !a.IsSuperset(NewSet(first)) {
+		t.Error("the set should keep the first element added for a key")
+	}
+
+	if added := a.AddAll(NewSet(), NewSet(3), NewSet()); added != 1 {
+		t.Errorf("AddAll should skip elements with keys in the set, added %d", added)
+	}
+	if a.AddIfNotContains(NewSet(5), NewSet(1, 2)) {
+		t.Error("AddIfNotContains should look the guard up by key")
+	}
+
+	if removed := a.RemoveAll(NewSet(2, 1), NewSet(9)); removed != 1 || a.Contains(first) {
+		t.Errorf("RemoveAll should remove elements by key, removed %d", removed)
+	}
+	a.Remove(NewSet())
+	if a.Cardinality() != 1 {
+		t.Errorf("Remove should remove elements by key, got %v", a)
+	}
+}
+
+func Test_SetByKeyOperations(t *testing.T) {
+	a := NewSetByKey(setKey)
+	a.AddAll(NewSet(1), NewSet(2), NewSet(3))
+
+	if removed := a.RetainAll(NewSet(NewSet(1), NewSet(2))); removed != 1 || a.Cardinality() != 2 {
+		t.Errorf("RetainAll should compare elements by key, removed %d", removed)
+	}
+	if removed := a.SubtractSet(NewSet(NewSet(2))); removed != 1 || !a.Contains(NewSet(1)) {
+		t.Errorf("SubtractSet should compare elements by key, removed %d", removed)
+	}
+	if removed := a.SubtractSet(a); removed != 1 || a.Cardinality() != 0 {
+		t.Errorf("subtracting a set from itself should clear it, removed %d", removed)
+	}
+
+	a.Add(NewSet(1))
+	clone := a.Clone()
+	if clone.Add(NewSet(1)) || !clone.Add(NewSet(2)) {
+		t.Error("the clone of a keyed set should keep the key function")
+	}
+	if a.Cardinality() != 1 {
+		t.Error("changing the clone should not affect the set")
+	}
+
+	if _, ok := a.TryPop(); !ok || !a.Add(NewSet(1)) {
+		t.Error("TryPop should remove the element together with its key")
+	}
+	if items := a.Drain(); len(items) != 1 || !a.Add(NewSet(1)) {
+		t.Error("Drain should remove every element together with its key")
+	}
+	a.Clear()
+	if !a.Add(NewSet(1)) {
+		t.Error("Clear should remove every key")
+	}
+	a.ClearRetainingCapacity()
+	if !a.Add(NewSet(1)) {
+		t.Error("ClearRetainingCapacity should remove every key")
+	}
+}
+
+func Test_SetByKeyDecode(t *testing.T) {
+	a := NewSetByKey(func(i interface{}) interface{} {
+		return strings.ToLower(i.(string))
+	})
+	if err := json.Unmarshal([]byte(`["a","A","b"]`), a); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ReadCSV(strings.NewReader("B,c\n")); err != nil {
+		t.Fatal(err)
+	}
+	if a.Cardinality() != 3 || !a.Contains("A", "B", "C") {
+		t.Errorf("decoding into a keyed set should deduplicate by key, got %v", a)
+	}
+
+	a.ReplaceAll("y", "Y")
+	if a.Cardinality() != 1 || !a.Contains("y") || a.Contains("x") {
+		t.Errorf("ReplaceAll on a keyed set should deduplicate by key, got %v", a)
+	}
+
+	NewSet("x", "X").CopyTo(a)
+	if a.Cardinality() != 1 || !a.Contains("x") {
+		t.Errorf("copying into a keyed set should deduplicate by key, got %v", a)
+	}
+}
@@ -0,0 +1,217 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+// lruSet is the thread-safe set returned by NewLRUSet. It wraps an
+// ordered set, whose map and doubly-linked list give constant time
+// lookups, moves and evictions, and keeps the list in order of use: the
+// front is the least recently used element, the next one to be evicted.
+type lruSet struct {
+	setWrapper
+	ordered *orderedSet
+	max     int
+	onEvict func(elem interface{})
+}
+
+func newLRUSet(max int, onEvict func(elem interface{})) *lruSet {
+	if max < 1 {
+		max = 1
+	}
+
+	ordered := newOrderedSet()
+	set := &lruSet{ordered: ordered, max: max, onEvict: onEvict}
+	set.setWrapper = setWrapper{Set: ordered, self: set}
+	return set
+}
+
+// use inserts elem, evicting the least recently used element if the set
+// is full, or marks it as the most recently used one if it is already in
+// the set. It returns whether elem was added and appends the evicted
+// element to evicted. Callers must hold the write lock.
+func (set *lruSet) use(elem interface{}, evicted []interface{}) (bool, []interface{}) {
+	if e, found := set.ordered.index[elem]; found {
+		set.ordered.order.MoveToBack(e)
+		return false, evicted
+	}
+
+	if len(set.ordered.index) >= set.max {
+		if item, ok := set.ordered.pop(); ok {
+			evicted = append(evicted, item)
+		}
+	}
+	set.ordered.insert(elem)
+	return true, evicted
+}
+
+// evict reports evicted elements to onEvict, callers must not hold the
+// lock.
+func (set *lruSet) evict(evicted []interface{}) {
+	if set.onEvict == nil {
+		return
+	}
+	for _, elem := range evicted {
+		set.onEvict(elem)
+	}
+}
+
+func (set *lruSet) Add(i interface{}) bool {
+	set.ordered.mutex.Lock()
+	added, evicted := set.use(i, nil)
+	set.ordered.mutex.Unlock()
+
+	set.evict(evicted)
+	return added
+}
+
+func (set *lruSet) AddAll(i ...interface{}) int {
+	set.ordered.mutex.Lock()
+	added := 0
+	var evicted []interface{}
+	for _, item := range i {
+		var ok bool
+		if ok, evicted = set.use(item, evicted); ok {
+			added++
+		}
+	}
+	set.ordered.mutex.Unlock()
+
+	set.evict(evicted)
+	return added
+}
+
+// AddIfNotContains does not count guard as used.
+func (set *lruSet) AddIfNotContains(candidate, guard interface{}) bool {
+	set.ordered.mutex.Lock()
+	if set.ordered.has(guard) {
+		set.ordered.mutex.Unlock()
+		return false
+	}
+	added, evicted := set.use(candidate, nil)
+	set.ordered.mutex.Unlock()
+
+	set.evict(evicted)
+	return added
+}
+
+// Contains marks every given element that is in the set as the most
+// recently used one.
+func (set *lruSet) Contains(i ...interface{}) bool {
+	set.ordered.mutex.Lock()
+	defer set.ordered.mutex.Unlock()
+
+	all := true
+	for _, item := range i {
+		if e, found := set.ordered.index[item]; found {
+			set.ordered.order.MoveToBack(e)
+		} else {
+			all = false
+		}
+	}
+	return all
+}
+
+func (set *lruSet) Has(i interface{}) bool {
+	set.ordered.mutex.Lock()
+	defer set.ordered.mutex.Unlock()
+
+	e, found := set.ordered.index[i]
+	if found {
+		set.ordered.order.MoveToBack(e)
+	}
+	return found
+}
+
+func (set *lruSet) ContainsAll(i ...interface{}) bool {
+	return set.Contains(i...)
+}
+
+// ContainsAny marks the first given element that is in the set as the
+// most recently used one.
+func (set *lruSet) ContainsAny(i ...interface{}) bool {
+	set.ordered.mutex.Lock()
+	defer set.ordered.mutex.Unlock()
+
+	for _, item := range i {
+		if e, found := set.ordered.index[item]; found {
+			set.ordered.order.MoveToBack(e)
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsBy marks the element found as the most recently used one.
+func (set *lruSet) ContainsBy(value interface{}, keyFunc func(interface{}) interface{}) bool {
+	set.ordered.mutex.Lock()
+	defer set.ordered.mutex.Unlock()
+
+	key := keyFunc(value)
+	for e := set.ordered.order.Front(); e != nil; e = e.Next() {
+		if keyFunc(e.Value) == key {
+			set.ordered.order.MoveToBack(e)
+			return true
+		}
+	}
+	return false
+}
+
+func (set *lruSet) reset(items []interface{}) {
+	set.ordered.mutex.Lock()
+	for elem := range set.ordered.index {
+		delete(set.ordered.index, elem)
+	}
+	set.ordered.order.Init()
+	var evicted []interface{}
+	for _, item := range items {
+		_, evicted = set.use(item, evicted)
+	}
+	set.ordered.mutex.Unlock()
+
+	set.evict(evicted)
+}
+
+// ReplaceAll goes through reset, so that the elements beyond max are
+// evicted.
+func (set *lruSet) ReplaceAll(i ...interface{}) {
+	set.reset(i)
+}
+
+func (set *lruSet) Clone() Set {
+	set.ordered.mutex.RLock()
+	defer set.ordered.mutex.RUnlock()
+
+	clone := newLRUSet(set.max, set.onEvict)
+	set.ordered.each(func(elem interface{}) bool {
+		clone.ordered.insert(elem)
+		return false
+	})
+
+	return clone
+}
+
+func (set *lruSet) Snapshot() Set {
+	return set.Clone()
+}
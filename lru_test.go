@@ -0,0 +1,140 @@
+package mapset
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_LRUSetEviction(t *testing.T) {
+	var evicted []interface{}
+	a := NewLRUSetWithEvict(3, func(elem interface{}) {
+		evicted = append(evicted, elem)
+	})
+
+	a.AddAll(1, 2, 3)
+	if !a.Add(4) || a.Contains(1) || a.Cardinality() != 3 {
+		t.Errorf("adding to a full set should evict the least recently used element, got %v", a)
+	}
+
+	// Contains and Add both count as uses.
+	a.Contains(2)
+	a.Add(3)
+	a.Add(5)
+	assertOrder(a, []interface{}{2, 3, 5}, t)
+
+	a.Add(6)
+	assertOrder(a, []interface{}{3, 5, 6}, t)
+
+	if !reflect.DeepEqual(evicted, []interface{}{1, 4, 2}) {
+		t.Errorf("onEvict should be called with 1, 4 and 2 in that order, got %v", evicted)
+	}
+
+	evicted = nil
+	a.AddSet(NewOrderedSet(7, 8))
+	assertOrder(a, []interface{}{6, 7, 8}, t)
+	if !reflect.DeepEqual(evicted, []interface{}{3, 5}) {
+		t.Errorf("AddSet should evict 3 and 5, got %v", evicted)
+	}
+
+	a.ContainsAny(0, 6)
+	assertOrder(a, []interface{}{7, 8, 6}, t)
+
+	sameParity := func(i interface{}) interface{} { return i.(int) % 2 }
+	if !a.ContainsBy(10, sameParity) {
+		t.Error("ContainsBy should find an even element")
+	}
+	assertOrder(a, []interface{}{7, 6, 8}, t)
+
+	if !a.Has(7) || a.Has(9) {
+		t.Error("Has should find 7 and not 9")
+	}
+	assertOrder(a, []interface{}{6, 8, 7}, t)
+
+	b := NewLRUSet(0)
+	if b.AddAll(1, 2); !b.Equal(NewSet(2)) {
+		t.Error("a max below 1 should be treated as 1")
+	}
+}
+
+func Test_LRUSetOperations(t *testing.T) {
+	a := NewLRUSet(2)
+	a.AddAll("a", "b")
+
+	if item, _ := a.Peek(); item != "a" {
+		t.Errorf("Peek should return the least recently used element, got %v", item)
+	}
+
+	clone := a.Clone()
+	clone.Add("c")
+	if clone.Cardinality() != 2 || !a.Equal(NewSet("a", "b")) {
+		t.Error("the clone of an LRU set should keep the bound and be independent")
+	}
+
+	NewOrderedSet(1, 2, 3).CopyTo(a)
+	assertOrder(a, []interface{}{2, 3}, t)
+
+	b := NewLRUSet(2)
+	b.ReplaceAll("x", "y", "z")
+	assertOrder(b, []interface{}{"y", "z"}, t)
+
+	if !NewSet(2, 3).Equal(a) || !a.IsSubset(NewSet(1, 2, 3)) {
+		t.Error("an LRU set should work as the argument and receiver of binary operations")
+	}
+}
+
+func Test_LRUSetConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	var mutex sync.Mutex
+	evictions := 0
+	s := NewLRUSetWithEvict(N/2, func(elem interface{}) {
+		mutex.Lock()
+		evictions++
+		mutex.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for i := 0; i < N; i++ {
+		go func(i int) {
+			s.Add(i)
+			s.Contains(i)
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	if s.Cardinality() != N/2 || evictions != N/2 {
+		t.Errorf("expected %d elements and as many evictions, got %d and %d", N/2, s.Cardinality(), evictions)
+	}
+}
+
+func Test_LRUSetSelfArgument(t *testing.T) {
+	assertSelfArgument(t, "lru", func() Set { return NewLRUSet(10) })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		a := NewLRUSet(10)
+		a.AddAll(1, 2)
+		if !a.Intersects(a) || a.IsDisjoint(a) {
+			t.Errorf("a non-empty LRU set should intersect itself, got %v", a)
+		}
+		if a.IntersectCardinality(a) != 2 || a.UnionCardinality(a) != 2 {
+			t.Errorf("the intersection and union of an LRU set with itself should hold 2 elements, got %v", a)
+		}
+		if a.JaccardSimilarity(a) != 1 || a.OverlapCoefficient(a) != 1 {
+			t.Errorf("an LRU set should be fully similar to itself, got %v", a)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("an LRU set given itself deadlocked")
+	}
+}
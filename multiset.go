@@ -0,0 +1,324 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MultiSet is a set that counts how many times each element was added,
+// also known as a bag. Its binary operations follow multiset semantics:
+// Union keeps the larger count of every element, Intersect the smaller
+// one, and Difference subtracts the counts of the argument.
+type MultiSet interface {
+	// Adds an occurrence of the given element and
+	// returns its new count.
+	Add(i interface{}) int
+
+	// Returns how many times the given element is in
+	// the multiset, 0 if it is not in it at all.
+	Count(i interface{}) int
+
+	// Removes a single occurrence of the given element
+	// and returns whether there was one to remove.
+	RemoveOne(i interface{}) bool
+
+	// Removes every occurrence of the given element.
+	Remove(i interface{})
+
+	// Returns whether the given element occurs at
+	// least once.
+	Contains(i interface{}) bool
+
+	// Returns the number of distinct elements.
+	Cardinality() int
+
+	// Returns the number of occurrences of all the
+	// elements, the sum of their counts.
+	Total() int
+
+	// Removes all elements, leaving the empty
+	// multiset.
+	Clear()
+
+	// Returns a clone of the multiset, duplicating
+	// all the counts.
+	Clone() MultiSet
+
+	// Determines if two multisets hold the same
+	// elements with the same counts.
+	Equal(other MultiSet) bool
+
+	// Returns a new multiset in which every element
+	// has the larger of its counts in this multiset
+	// and other.
+	Union(other MultiSet) MultiSet
+
+	// Returns a new multiset in which every element
+	// has the smaller of its counts in this multiset
+	// and other. Elements missing from either are
+	// left out.
+	Intersect(other MultiSet) MultiSet
+
+	// Returns a new multiset in which the count of
+	// every element is its count in this multiset
+	// minus its count in other. Elements whose count
+	// drops to zero or below are left out.
+	Difference(other MultiSet) MultiSet
+
+	// Iterates over the distinct elements with their
+	// counts. If the passed func returns true, stop
+	// iteration at that point.
+	Each(func(elem interface{}, count int) bool)
+
+	// Returns a Set of the distinct elements,
+	// dropping the counts.
+	ToSet() Set
+
+	// Provides a convenient string representation
+	// of the multiset, listing each element with its
+	// count.
+	String() string
+}
+
+// multiSet is a thread-safe MultiSet. It uses the same map-backed design
+// as threadUnsafeSet, carrying counts as the map values instead of
+// struct{}. An element is only in the map while its count is positive.
+type multiSet struct {
+	counts map[interface{}]int
+	mutex  sync.RWMutex
+}
+
+// NewMultiSet creates and returns a reference to an empty multiset.
+// Operations on the resulting multiset are thread-safe.
+func NewMultiSet() MultiSet {
+	return newMultiSet()
+}
+
+func newMultiSet() *multiSet {
+	return &multiSet{counts: make(map[interface{}]int)}
+}
+
+// rlockWith read-locks the set and other in the order used by rlockAll,
+// and returns a func releasing them.
+func (set *multiSet) rlockWith(other MultiSet) (*multiSet, func()) {
+	o := set.coerce(other)
+	return o, rlockAll(&set.mutex, &o.mutex)
+}
+
+// coerce returns other if it is a *multiSet, and otherwise a private copy
+// of it, so that binary operations accept any MultiSet.
+func (set *multiSet) coerce(other MultiSet) *multiSet {
+	if o, ok := other.(*multiSet); ok {
+		return o
+	}
+
+	o := newMultiSet()
+	other.Each(func(elem interface{}, count int) bool {
+		o.counts[elem] = count
+		return false
+	})
+	return o
+}
+
+func (set *multiSet) Add(i interface{}) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	set.counts[i]++
+	return set.counts[i]
+}
+
+func (set *multiSet) Count(i interface{}) int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.counts[i]
+}
+
+func (set *multiSet) RemoveOne(i interface{}) bool {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	count, found := set.counts[i]
+	if !found {
+		return false
+	}
+
+	if count == 1 {
+		delete(set.counts, i)
+	} else {
+		set.counts[i] = count - 1
+	}
+	return true
+}
+
+func (set *multiSet) Remove(i interface{}) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	delete(set.counts, i)
+}
+
+func (set *multiSet) Contains(i interface{}) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	_, found := set.counts[i]
+	return found
+}
+
+func (set *multiSet) Cardinality() int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return len(set.counts)
+}
+
+func (set *multiSet) Total() int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	total := 0
+	for _, count := range set.counts {
+		total += count
+	}
+	return total
+}
+
+func (set *multiSet) Clear() {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	set.counts = make(map[interface{}]int)
+}
+
+func (set *multiSet) Clone() MultiSet {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	clone := &multiSet{counts: make(map[interface{}]int, len(set.counts))}
+	for elem, count := range set.counts {
+		clone.counts[elem] = count
+	}
+	return clone
+}
+
+func (set *multiSet) Equal(other MultiSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	if len(set.counts) != len(o.counts) {
+		return false
+	}
+	for elem, count := range set.counts {
+		if o.counts[elem] != count {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *multiSet) Union(other MultiSet) MultiSet {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	union := newMultiSet()
+	for elem, count := range set.counts {
+		union.counts[elem] = count
+	}
+	for elem, count := range o.counts {
+		if count > union.counts[elem] {
+			union.counts[elem] = count
+		}
+	}
+	return union
+}
+
+func (set *multiSet) Intersect(other MultiSet) MultiSet {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	intersection := newMultiSet()
+	for elem, count := range set.counts {
+		if n, found := o.counts[elem]; found {
+			if n < count {
+				count = n
+			}
+			intersection.counts[elem] = count
+		}
+	}
+	return intersection
+}
+
+func (set *multiSet) Difference(other MultiSet) MultiSet {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	difference := newMultiSet()
+	for elem, count := range set.counts {
+		if count -= o.counts[elem]; count > 0 {
+			difference.counts[elem] = count
+		}
+	}
+	return difference
+}
+
+func (set *multiSet) Each(callback func(elem interface{}, count int) bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	for elem, count := range set.counts {
+		if callback(elem, count) {
+			break
+		}
+	}
+}
+
+func (set *multiSet) ToSet() Set {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	s := newThreadSafeSetWithSize(len(set.counts))
+	for elem := range set.counts {
+		s.objects[elem] = struct{}{}
+	}
+	return &s
+}
+
+func (set *multiSet) String() string {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	items := make([]string, 0, len(set.counts))
+	for elem, count := range set.counts {
+		items = append(items, fmt.Sprintf("%v:%d", elem, count))
+	}
+
+	return fmt.Sprintf("MultiSet{%s}", strings.Join(items, ", "))
+}
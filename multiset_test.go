@@ -0,0 +1,103 @@
+package mapset
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func makeMultiSet(items ...interface{}) MultiSet {
+	m := NewMultiSet()
+	for _, item := range items {
+		m.Add(item)
+	}
+	return m
+}
+
+func Test_MultiSetCounts(t *testing.T) {
+	m := NewMultiSet()
+	if m.Cardinality() != 0 || m.Total() != 0 {
+		t.Error("NewMultiSet should start out empty")
+	}
+
+	if n := m.Add("a"); n != 1 {
+		t.Errorf("Add should return the new count 1, got %d", n)
+	}
+	if n := m.Add("a"); n != 2 {
+		t.Errorf("Add should return the new count 2, got %d", n)
+	}
+	m.Add("b")
+
+	if m.Count("a") != 2 || m.Count("b") != 1 || m.Count("c") != 0 {
+		t.Errorf("unexpected counts in %v", m)
+	}
+	if m.Cardinality() != 2 || m.Total() != 3 {
+		t.Errorf("expected 2 distinct elements and 3 in total, got %d and %d", m.Cardinality(), m.Total())
+	}
+
+	if !m.RemoveOne("a") || m.Count("a") != 1 {
+		t.Error("RemoveOne should decrement the count of a to 1")
+	}
+	if !m.RemoveOne("a") || m.Contains("a") {
+		t.Error("RemoveOne should drop a once its count reaches 0")
+	}
+	if m.RemoveOne("a") {
+		t.Error("RemoveOne should report false for a missing element")
+	}
+
+	m.Add("b")
+	m.Remove("b")
+	if m.Contains("b") || m.Cardinality() != 0 {
+		t.Error("Remove should drop every occurrence of b")
+	}
+}
+
+func Test_MultiSetOperations(t *testing.T) {
+	a := makeMultiSet(1, 1, 1, 2, 3, 3)
+	b := makeMultiSet(1, 2, 2, 3, 3, 4)
+
+	if expected := makeMultiSet(1, 1, 1, 2, 2, 3, 3, 4); !a.Union(b).Equal(expected) {
+		t.Errorf("Union should keep the larger counts, got %v", a.Union(b))
+	}
+	if expected := makeMultiSet(1, 2, 3, 3); !a.Intersect(b).Equal(expected) {
+		t.Errorf("Intersect should keep the smaller counts, got %v", a.Intersect(b))
+	}
+	if expected := makeMultiSet(1, 1); !a.Difference(b).Equal(expected) {
+		t.Errorf("Difference should subtract the counts, got %v", a.Difference(b))
+	}
+
+	if a.Equal(makeMultiSet(1, 2, 3)) {
+		t.Error("multisets with different counts should not be equal")
+	}
+	if !a.Equal(a.Clone()) {
+		t.Error("a clone should equal the original")
+	}
+
+	if !a.ToSet().Equal(NewSet(1, 2, 3)) {
+		t.Errorf("ToSet should drop the counts, got %v", a.ToSet())
+	}
+
+	if s := makeMultiSet("x", "x").String(); s != "MultiSet{x:2}" {
+		t.Errorf("unexpected string representation %q", s)
+	}
+}
+
+func Test_MultiSetConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	m := NewMultiSet()
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for i := 0; i < N; i++ {
+		go func(i int) {
+			m.Add(i % 10)
+			m.Union(m)
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	if m.Total() != N || m.Count(0) != N/10 {
+		t.Errorf("expected %d occurrences in total, got %d", N, m.Total())
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import "reflect"
+
+// nonNilSet is the thread-safe set returned by NewNonNilSet. It embeds
+// the thread-safe set holding its elements, so every read and removal is
+// that set's own, and overrides the operations inserting elements to skip
+// nil ones under the same write lock.
+type nonNilSet struct {
+	setWrapper
+	objects *threadSafeSet
+}
+
+func newNonNilSet() *nonNilSet {
+	objects := newThreadSafeSet()
+	set := &nonNilSet{objects: &objects}
+	set.setWrapper = setWrapper{Set: &objects, self: set}
+	return set
+}
+
+// isNil reports whether elem is nil, or a nil pointer, map, slice,
+// channel or func stored in an interface.
+func isNil(elem interface{}) bool {
+	if elem == nil {
+		return true
+	}
+
+	switch v := reflect.ValueOf(elem); v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// insert adds the items that are not nil and returns how many were added,
+// callers must hold the write lock.
+func (set *nonNilSet) insert(items ...interface{}) int {
+	added := 0
+	for _, item := range items {
+		if !isNil(item) && set.objects.objects.Add(item) {
+			added++
+		}
+	}
+	return added
+}
+
+func (set *nonNilSet) Add(i interface{}) bool {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.insert(i) == 1
+}
+
+func (set *nonNilSet) AddAll(i ...interface{}) int {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.insert(i...)
+}
+
+func (set *nonNilSet) AddIfNotContains(candidate, guard interface{}) bool {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	if _, found := set.objects.objects[guard]; found {
+		return false
+	}
+	return set.insert(candidate) == 1
+}
+
+func (set *nonNilSet) reset(items []interface{}) {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.objects.objects.clear()
+	set.insert(items...)
+}
+
+func (set *nonNilSet) ReplaceAll(i ...interface{}) {
+	set.reset(i)
+}
+
+func (set *nonNilSet) Clone() Set {
+	set.objects.mutex.RLock()
+	defer set.objects.mutex.RUnlock()
+
+	clone := newNonNilSet()
+	clone.objects.objects = *set.objects.objects.Clone().(*threadUnsafeSet)
+	return clone
+}
+
+func (set *nonNilSet) Snapshot() Set {
+	return set.Clone()
+}
@@ -0,0 +1,68 @@
+package mapset
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test_NonNilSetAdd(t *testing.T) {
+	a := NewNonNilSet()
+
+	var p *int
+	var m map[string]int
+	if a.Add(nil) || a.Add(p) || a.Add(m) || a.Contains(nil) || a.Cardinality() != 0 {
+		t.Errorf("Add should reject nil elements, got %v", a)
+	}
+	if !a.Add(1) || !a.Add(0) || !a.Add("") || a.Add(1) {
+		t.Error("Add should accept elements that are not nil, and zero values")
+	}
+
+	if added := a.AddAll(nil, 2, p, 3); added != 2 || !a.Equal(NewSet(0, 1, 2, 3, "")) {
+		t.Errorf("AddAll should skip nil elements, added %d to %v", added, a)
+	}
+	if added := a.AddSet(NewSet(nil, 4)); added != 1 || a.Contains(nil) {
+		t.Errorf("AddSet should skip nil elements, added %d", added)
+	}
+	if a.AddIfNotContains(nil, 9) {
+		t.Error("AddIfNotContains should reject nil")
+	}
+
+	a.ReplaceAll(nil, 5)
+	if !a.Equal(NewSet(5)) {
+		t.Errorf("ReplaceAll should skip nil elements, got %v", a)
+	}
+	NewSet(nil, 6).CopyTo(a)
+	if !a.Equal(NewSet(6)) {
+		t.Errorf("CopyTo should skip nil elements, got %v", a)
+	}
+
+	if !NewSet().Add(nil) {
+		t.Error("the default sets should still accept nil")
+	}
+}
+
+func Test_NonNilSetOperations(t *testing.T) {
+	a := NewNonNilSet()
+	a.AddAll(1, 2)
+
+	clone := a.Clone()
+	if clone.Add(nil) || !clone.Equal(a) {
+		t.Errorf("the clone of a set rejecting nil should reject nil too, got %v", clone)
+	}
+
+	b := NewNonNilSet()
+	if err := json.Unmarshal([]byte(`[1, null, 2]`), b); err != nil {
+		t.Fatal(err)
+	}
+	if b.Contains(nil) || b.Cardinality() != 2 {
+		t.Errorf("UnmarshalJSON should skip null elements, got %v", b)
+	}
+
+	if Move(NewSet(nil), a, nil); a.Contains(nil) {
+		t.Error("Move should not add nil to a set rejecting nil")
+	}
+}
+
+func Test_NonNilSetSelfArgument(t *testing.T) {
+	assertSelfArgument(t, "nonNil", NewNonNilSet)
+}
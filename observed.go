@@ -0,0 +1,200 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+// SetObserver receives the hooks of a set created by NewObservedSet, e.g.
+// to count operations with metrics. The hooks are called after the
+// operation completed, outside of any lock of the set, from the goroutine
+// that called it, so they must be safe for concurrent use if the set is
+// used concurrently, and must not block for long.
+type SetObserver interface {
+	// OnAdd is called after an operation that may add elements, with
+	// the number of elements it added, possibly 0.
+	OnAdd(n int)
+	// OnRemove is called after an operation that may remove elements,
+	// with the number of elements it removed, possibly 0.
+	OnRemove(n int)
+	// OnContains is called after every membership check, Contains,
+	// ContainsAll, ContainsAny and ContainsBy, with its result.
+	OnContains(found bool)
+}
+
+// observedSet is the set returned by NewObservedSet. It embeds the set it
+// wraps, so every operation is the wrapped set's own, and overrides the
+// operations adding, removing and looking up elements to call the hooks
+// of its observer once they return.
+type observedSet struct {
+	setWrapper
+	observer SetObserver
+}
+
+func newObservedSet(inner Set, observer SetObserver) *observedSet {
+	set := &observedSet{observer: observer}
+	set.setWrapper = setWrapper{Set: inner, self: set}
+	return set
+}
+
+func (set *observedSet) Add(i interface{}) bool {
+	added := set.Set.Add(i)
+	if added {
+		set.observer.OnAdd(1)
+	} else {
+		set.observer.OnAdd(0)
+	}
+	return added
+}
+
+func (set *observedSet) AddAll(i ...interface{}) int {
+	added := set.Set.AddAll(i...)
+	set.observer.OnAdd(added)
+	return added
+}
+
+func (set *observedSet) AddIfNotContains(candidate, guard interface{}) bool {
+	added := set.Set.AddIfNotContains(candidate, guard)
+	if added {
+		set.observer.OnAdd(1)
+	} else {
+		set.observer.OnAdd(0)
+	}
+	return added
+}
+
+// unwrap hands the wrapped set to itself in place of the observed set, so
+// that a set given itself never calls back into the observed set under
+// its own lock.
+func (set *observedSet) unwrap(other Set) Set {
+	if other == Set(set) {
+		return set.Set
+	}
+	return other
+}
+
+func (set *observedSet) AddSet(other Set) int {
+	added := set.Set.AddSet(set.unwrap(other))
+	set.observer.OnAdd(added)
+	return added
+}
+
+func (set *observedSet) Contains(i ...interface{}) bool {
+	found := set.Set.Contains(i...)
+	set.observer.OnContains(found)
+	return found
+}
+
+func (set *observedSet) Has(i interface{}) bool {
+	found := set.Set.Has(i)
+	set.observer.OnContains(found)
+	return found
+}
+
+func (set *observedSet) ContainsAll(i ...interface{}) bool {
+	found := set.Set.ContainsAll(i...)
+	set.observer.OnContains(found)
+	return found
+}
+
+func (set *observedSet) ContainsAny(i ...interface{}) bool {
+	found := set.Set.ContainsAny(i...)
+	set.observer.OnContains(found)
+	return found
+}
+
+func (set *observedSet) ContainsBy(value interface{}, keyFunc func(interface{}) interface{}) bool {
+	found := set.Set.ContainsBy(value, keyFunc)
+	set.observer.OnContains(found)
+	return found
+}
+
+// Remove goes through RemoveAll, which reports whether the element was
+// removed.
+func (set *observedSet) Remove(i interface{}) {
+	set.observer.OnRemove(set.Set.RemoveAll(i))
+}
+
+func (set *observedSet) RemoveAll(i ...interface{}) int {
+	removed := set.Set.RemoveAll(i...)
+	set.observer.OnRemove(removed)
+	return removed
+}
+
+func (set *observedSet) RetainAll(other Set) int {
+	removed := set.Set.RetainAll(set.unwrap(other))
+	set.observer.OnRemove(removed)
+	return removed
+}
+
+func (set *observedSet) SubtractSet(other Set) int {
+	removed := set.Set.SubtractSet(set.unwrap(other))
+	set.observer.OnRemove(removed)
+	return removed
+}
+
+// Clear goes through Drain, which reports how many elements were removed.
+func (set *observedSet) Clear() {
+	set.observer.OnRemove(len(set.Set.Drain()))
+}
+
+// ClearRetainingCapacity reads the number of elements removed before
+// clearing the set, so it is approximate while other goroutines modify
+// the set.
+func (set *observedSet) ClearRetainingCapacity() {
+	removed := set.Set.Cardinality()
+	set.Set.ClearRetainingCapacity()
+	set.observer.OnRemove(removed)
+}
+
+// ReplaceAll reports every previous element as removed and every new one
+// as added. The counts are read before and after replacing the elements,
+// so they are approximate while other goroutines modify the set.
+func (set *observedSet) ReplaceAll(i ...interface{}) {
+	before := set.Set.Cardinality()
+	set.Set.ReplaceAll(i...)
+	set.observer.OnRemove(before)
+	set.observer.OnAdd(set.Set.Cardinality())
+}
+
+// Pop goes through TryPop, as nil may be an element of the set.
+func (set *observedSet) Pop() interface{} {
+	item, _ := set.TryPop()
+	return item
+}
+
+func (set *observedSet) TryPop() (interface{}, bool) {
+	item, ok := set.Set.TryPop()
+	if ok {
+		set.observer.OnRemove(1)
+	} else {
+		set.observer.OnRemove(0)
+	}
+	return item, ok
+}
+
+func (set *observedSet) Drain() []interface{} {
+	items := set.Set.Drain()
+	set.observer.OnRemove(len(items))
+	return items
+}
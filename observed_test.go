@@ -0,0 +1,125 @@
+package mapset
+
+import (
+	"encoding/json"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type countingObserver struct {
+	added, removed, hits, misses int64
+}
+
+func (o *countingObserver) OnAdd(n int) {
+	atomic.AddInt64(&o.added, int64(n))
+}
+
+func (o *countingObserver) OnRemove(n int) {
+	atomic.AddInt64(&o.removed, int64(n))
+}
+
+func (o *countingObserver) OnContains(found bool) {
+	if found {
+		atomic.AddInt64(&o.hits, 1)
+	} else {
+		atomic.AddInt64(&o.misses, 1)
+	}
+}
+
+func (o *countingObserver) assert(t *testing.T, added, removed, hits, misses int64) {
+	t.Helper()
+	if o.added != added || o.removed != removed || o.hits != hits || o.misses != misses {
+		t.Errorf("expected %d added, %d removed, %d hits and %d misses, got %+v",
+			added, removed, hits, misses, *o)
+	}
+}
+
+func Test_ObservedSet(t *testing.T) {
+	for name, newSet := range setConstructors {
+		obs := &countingObserver{}
+		a := NewObservedSet(newSet(), obs)
+
+		a.Add(1)
+		a.Add(1)
+		a.AddAll(2, 3, 4)
+		a.AddIfNotContains(5, 9)
+		a.AddSet(NewSet(5, 6))
+		obs.assert(t, 6, 0, 0, 0)
+
+		a.Contains(1, 2)
+		a.ContainsAll(7)
+		a.ContainsAny(7, 1)
+		a.ContainsBy(8, func(i interface{}) interface{} { return i.(int) % 2 })
+		obs.assert(t, 6, 0, 3, 1)
+
+		a.Remove(1)
+		a.Remove(1)
+		a.RemoveAll(2, 3)
+		a.RetainAll(NewSet(4, 5))
+		a.SubtractSet(NewSet(5))
+		obs.assert(t, 6, 5, 3, 1)
+
+		a.TryPop()
+		a.Pop()
+		a.AddAll(1, 2, 3)
+		a.Drain()
+		a.AddAll(1, 2)
+		a.Clear()
+		obs.assert(t, 11, 11, 3, 1)
+
+		a.AddAll(1, 2)
+		a.ReplaceAll(3, 4, 5)
+		obs.assert(t, 16, 13, 3, 1)
+
+		if err := json.Unmarshal([]byte(`["a","b"]`), a); err != nil {
+			t.Fatal(err)
+		}
+		obs.assert(t, 18, 13, 3, 1)
+
+		a.Has(3)
+		a.Has(9)
+		obs.assert(t, 18, 13, 4, 2)
+
+		if !a.Equal(NewSet(3, 4, 5, "a", "b")) {
+			t.Errorf("%s: the observed set should hold the elements of the wrapped set, got %v", name, a)
+		}
+	}
+}
+
+func Test_ObservedSetSelfArgument(t *testing.T) {
+	for name, newSet := range setConstructors {
+		assertSelfArgument(t, "observed "+name, func() Set {
+			return NewObservedSet(newSet(), &countingObserver{})
+		})
+	}
+
+	obs := &countingObserver{}
+	a := NewObservedSet(NewSet(1, 2), obs)
+	if a.AddSet(a) != 0 {
+		t.Error("adding a set to itself should add nothing")
+	}
+	obs.assert(t, 0, 0, 0, 0)
+}
+
+func Test_ObservedSetConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	obs := &countingObserver{}
+	s := NewObservedSet(NewSet(), obs)
+
+	var wg sync.WaitGroup
+	for i := 0; i < N; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Add(i)
+			s.Contains(i)
+			s.Remove(i)
+		}(i)
+	}
+	wg.Wait()
+
+	obs.assert(t, N, N, N, 0)
+}
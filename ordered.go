@@ -0,0 +1,1347 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// orderedSet is a thread-safe set that remembers the order in which its
+// elements were first added. The map gives constant time membership tests
+// and the linked list keeps the insertion order, so removing an element
+// splices it out of the order in constant time too.
+//
+// Sample draws from rand when it is set, randMutex serializes those draws
+// since a rand.Rand is not safe for concurrent use.
+type orderedSet struct {
+	index map[interface{}]*list.Element
+	order *list.List
+	mutex sync.RWMutex
+
+	rand      *rand.Rand
+	randMutex sync.Mutex
+}
+
+func newOrderedSet() *orderedSet {
+	return &orderedSet{
+		index: make(map[interface{}]*list.Element),
+		order: list.New(),
+	}
+}
+
+// rlockOrdered read-locks each of the given sets once, see rlockAll.
+func rlockOrdered(sets ...*orderedSet) func() {
+	mutexes := make([]*sync.RWMutex, len(sets))
+	for i, s := range sets {
+		mutexes[i] = &s.mutex
+	}
+	return rlockAll(mutexes...)
+}
+
+// coerce returns other if it is an ordered set, the ordered set embedded
+// in other if it is an LRU set, and otherwise a private ordered copy of
+// it, so that binary operations accept any Set. Unwrapping LRU sets lets
+// operations spot an LRU set given itself, whose Contains would otherwise
+// wait for the lock they hold, and means reading an LRU set as the
+// argument does not count as a use of its elements.
+func (set *orderedSet) coerce(other Set) *orderedSet {
+	switch o := other.(type) {
+	case *orderedSet:
+		return o
+	case *lruSet:
+		return o.ordered
+	}
+
+	o := newOrderedSet()
+	other.Each(func(elem interface{}) bool {
+		o.insert(elem)
+		return false
+	})
+	return o
+}
+
+// The following helpers do not lock, callers must hold the lock.
+
+func (set *orderedSet) has(elem interface{}) bool {
+	_, found := set.index[elem]
+	return found
+}
+
+func (set *orderedSet) insert(elem interface{}) bool {
+	if _, found := set.index[elem]; found {
+		return false
+	}
+
+	set.index[elem] = set.order.PushBack(elem)
+	return true
+}
+
+func (set *orderedSet) remove(elem interface{}) bool {
+	e, found := set.index[elem]
+	if !found {
+		return false
+	}
+
+	set.order.Remove(e)
+	delete(set.index, elem)
+	return true
+}
+
+// each calls callback on every element in insertion order until it
+// returns true.
+func (set *orderedSet) each(callback func(interface{}) bool) {
+	for e := set.order.Front(); e != nil; e = e.Next() {
+		if callback(e.Value) {
+			return
+		}
+	}
+}
+
+func (set *orderedSet) items() []interface{} {
+	items := make([]interface{}, 0, len(set.index))
+	set.each(func(elem interface{}) bool {
+		items = append(items, elem)
+		return false
+	})
+	return items
+}
+
+// unordered copies every element into a thread-unsafe set.
+func (set *orderedSet) unordered() threadUnsafeSet {
+	unordered := newThreadUnsafeSetWithSize(len(set.index))
+	for elem := range set.index {
+		unordered.Add(elem)
+	}
+	return unordered
+}
+
+func (set *orderedSet) subset(o *orderedSet) bool {
+	if len(set.index) > len(o.index) {
+		return false
+	}
+
+	for elem := range set.index {
+		if !o.has(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *orderedSet) equal(o *orderedSet) bool {
+	return len(set.index) == len(o.index) && set.subset(o)
+}
+
+func (set *orderedSet) pop() (interface{}, bool) {
+	e := set.order.Front()
+	if e == nil {
+		return nil, false
+	}
+
+	set.remove(e.Value)
+	return e.Value, true
+}
+
+func (set *orderedSet) Add(i interface{}) bool {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.insert(i)
+}
+
+func (set *orderedSet) AddAll(i ...interface{}) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	added := 0
+	for _, item := range i {
+		if set.insert(item) {
+			added++
+		}
+	}
+
+	return added
+}
+
+func (set *orderedSet) AddIfNotContains(candidate, guard interface{}) bool {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	if set.has(guard) {
+		return false
+	}
+	return set.insert(candidate)
+}
+
+func (set *orderedSet) Cardinality() int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return len(set.index)
+}
+
+func (set *orderedSet) Length() int {
+	return set.Cardinality()
+}
+
+func (set *orderedSet) Clear() {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	set.index = make(map[interface{}]*list.Element)
+	set.order = list.New()
+}
+
+func (set *orderedSet) ClearRetainingCapacity() {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	for elem := range set.index {
+		delete(set.index, elem)
+	}
+	set.order.Init()
+}
+
+func (set *orderedSet) ReplaceAll(i ...interface{}) {
+	set.reset(i)
+}
+
+func (set *orderedSet) Clone() Set {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	clone := newOrderedSet()
+	set.each(func(elem interface{}) bool {
+		clone.insert(elem)
+		return false
+	})
+
+	return clone
+}
+
+func (set *orderedSet) Snapshot() Set {
+	return set.Clone()
+}
+
+func (set *orderedSet) reset(items []interface{}) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	for elem := range set.index {
+		delete(set.index, elem)
+	}
+	set.order.Init()
+	for _, item := range items {
+		set.insert(item)
+	}
+}
+
+// CopyTo copies the elements in insertion order, so an ordered dest ends
+// up with the same order as the set.
+func (set *orderedSet) CopyTo(dest Set) {
+	if dest == Set(set) {
+		return
+	}
+
+	copyItems(dest, set.ToSlice())
+}
+
+// Freeze wraps the set itself, reads on the view still take its locks.
+func (set *orderedSet) Freeze() Set {
+	return freeze(set)
+}
+
+func (set *orderedSet) Contains(i ...interface{}) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	for _, item := range i {
+		if !set.has(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (set *orderedSet) Has(i interface{}) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.has(i)
+}
+
+func (set *orderedSet) ContainsAll(i ...interface{}) bool {
+	return set.Contains(i...)
+}
+
+func (set *orderedSet) ContainsAny(i ...interface{}) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	for _, item := range i {
+		if set.has(item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (set *orderedSet) ContainsBy(value interface{}, keyFunc func(interface{}) interface{}) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	key := keyFunc(value)
+	found := false
+	set.each(func(elem interface{}) bool {
+		found = keyFunc(elem) == key
+		return found
+	})
+	return found
+}
+
+func (set *orderedSet) Difference(other Set) Set {
+	other = nonNil(other)
+	return set.DifferenceAll(other)
+}
+
+func (set *orderedSet) DifferenceAll(others ...Set) Set {
+	others = nonNilAll(others)
+	os := make([]*orderedSet, len(others))
+	for i, other := range others {
+		os[i] = set.coerce(other)
+	}
+
+	unlock := rlockOrdered(append(os, set)...)
+	defer unlock()
+
+	difference := newOrderedSet()
+	set.each(func(elem interface{}) bool {
+		for _, o := range os {
+			if o.has(elem) {
+				return false
+			}
+		}
+		difference.insert(elem)
+		return false
+	})
+
+	return difference
+}
+
+func (set *orderedSet) Diff(other Set) (added Set, removed Set) {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockOrdered(set, o)
+	defer unlock()
+
+	a, r := newOrderedSet(), newOrderedSet()
+	o.each(func(elem interface{}) bool {
+		if !set.has(elem) {
+			a.insert(elem)
+		}
+		return false
+	})
+	set.each(func(elem interface{}) bool {
+		if !o.has(elem) {
+			r.insert(elem)
+		}
+		return false
+	})
+
+	return a, r
+}
+
+func (set *orderedSet) Merge(other Set) (union Set, common Set) {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockOrdered(set, o)
+	defer unlock()
+
+	u, c := newOrderedSet(), newOrderedSet()
+	set.each(func(elem interface{}) bool {
+		u.insert(elem)
+		if o.has(elem) {
+			c.insert(elem)
+		}
+		return false
+	})
+	o.each(func(elem interface{}) bool {
+		u.insert(elem)
+		return false
+	})
+
+	return u, c
+}
+
+func (set *orderedSet) Equal(other Set) bool {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockOrdered(set, o)
+	defer unlock()
+
+	return set.equal(o)
+}
+
+func (set *orderedSet) EqualFunc(other Set, eq func(a, b interface{}) bool) bool {
+	other = nonNil(other)
+	return equalFunc(set.ToSlice(), other.ToSlice(), eq)
+}
+
+func (set *orderedSet) EqualUsing(other Set, eq func(a, b interface{}) bool) bool {
+	other = nonNil(other)
+	return equalUsing(set.ToSlice(), other.ToSlice(), eq)
+}
+
+func (set *orderedSet) Intersect(other Set) Set {
+	other = nonNil(other)
+	return set.IntersectAll(other)
+}
+
+func (set *orderedSet) IntersectAll(others ...Set) Set {
+	others = nonNilAll(others)
+	os := make([]*orderedSet, len(others))
+	for i, other := range others {
+		os[i] = set.coerce(other)
+	}
+
+	unlock := rlockOrdered(append(os, set)...)
+	defer unlock()
+
+	// The result keeps the order of the receiver, so it is walked in
+	// order instead of starting from the smallest set.
+	intersection := newOrderedSet()
+	set.each(func(elem interface{}) bool {
+		for _, o := range os {
+			if !o.has(elem) {
+				return false
+			}
+		}
+		intersection.insert(elem)
+		return false
+	})
+
+	return intersection
+}
+
+func (set *orderedSet) IsProperSubset(other Set) bool {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockOrdered(set, o)
+	defer unlock()
+
+	return set.subset(o) && !set.equal(o)
+}
+
+func (set *orderedSet) IsProperSuperset(other Set) bool {
+	return nonNil(other).IsProperSubset(set)
+}
+
+func (set *orderedSet) IsSubset(other Set) bool {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockOrdered(set, o)
+	defer unlock()
+
+	return set.subset(o)
+}
+
+func (set *orderedSet) IsSuperset(other Set) bool {
+	return nonNil(other).IsSubset(set)
+}
+
+// Intersects coerces other before taking any lock, probing it through its
+// interface under the lock of the receiver could lock both sets in either
+// order.
+func (set *orderedSet) Intersects(other Set) bool {
+	o := set.coerce(nonNil(other))
+
+	unlock := rlockOrdered(set, o)
+	defer unlock()
+
+	small, large := set, o
+	if len(small.index) > len(large.index) {
+		small, large = large, small
+	}
+
+	for elem := range small.index {
+		if large.has(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+func (set *orderedSet) IntersectCardinality(other Set) int {
+	o := set.coerce(nonNil(other))
+
+	unlock := rlockOrdered(set, o)
+	defer unlock()
+
+	return set.intersectSize(o)
+}
+
+// intersectSize returns how many elements set and other have in common,
+// iterating the smaller of both, callers must hold both read locks.
+func (set *orderedSet) intersectSize(other *orderedSet) int {
+	small, large := set, other
+	if len(small.index) > len(large.index) {
+		small, large = large, small
+	}
+
+	n := 0
+	for elem := range small.index {
+		if large.has(elem) {
+			n++
+		}
+	}
+	return n
+}
+
+func (set *orderedSet) UnionCardinality(other Set) int {
+	o := set.coerce(nonNil(other))
+
+	unlock := rlockOrdered(set, o)
+	defer unlock()
+
+	return len(set.index) + len(o.index) - set.intersectSize(o)
+}
+
+func (set *orderedSet) IsDisjoint(other Set) bool {
+	other = nonNil(other)
+	return !set.Intersects(other)
+}
+
+func (set *orderedSet) JaccardSimilarity(other Set) float64 {
+	other = nonNil(other)
+	return jaccard(set.overlap(other))
+}
+
+func (set *orderedSet) OverlapCoefficient(other Set) float64 {
+	other = nonNil(other)
+	return overlapCoefficient(set.overlap(other))
+}
+
+// overlap is threadUnsafeSet.overlap for ordered sets, other is coerced
+// before taking any lock.
+func (set *orderedSet) overlap(other Set) (common, size, otherSize int) {
+	o := set.coerce(other)
+
+	unlock := rlockOrdered(set, o)
+	defer unlock()
+
+	small, large := set, o
+	if len(small.index) > len(large.index) {
+		small, large = large, small
+	}
+	for elem := range small.index {
+		if large.has(elem) {
+			common++
+		}
+	}
+	return common, len(set.index), len(o.index)
+}
+
+func (set *orderedSet) Each(callback func(interface{}) bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	set.each(callback)
+}
+
+func (set *orderedSet) ForEach(callback func(interface{})) {
+	set.Each(func(elem interface{}) bool {
+		callback(elem)
+		return false
+	})
+}
+
+func (set *orderedSet) ForEachUntil(callback func(interface{}) bool) {
+	set.Each(func(elem interface{}) bool {
+		return !callback(elem)
+	})
+}
+
+func (set *orderedSet) IterateIndexed(callback func(index int, elem interface{}) bool) {
+	index := 0
+	set.Each(func(elem interface{}) bool {
+		stop := !callback(index, elem)
+		index++
+		return stop
+	})
+}
+
+func (set *orderedSet) EachParallel(workers int, callback func(interface{})) {
+	eachParallel(set.ToSlice(), workers, func(elem interface{}) error {
+		callback(elem)
+		return nil
+	})
+}
+
+func (set *orderedSet) EachParallelErr(workers int, callback func(interface{}) error) []error {
+	return eachParallel(set.ToSlice(), workers, callback)
+}
+
+func (set *orderedSet) Map(transform func(interface{}) interface{}) Set {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	mapped := newOrderedSet()
+	set.each(func(elem interface{}) bool {
+		mapped.insert(transform(elem))
+		return false
+	})
+
+	return mapped
+}
+
+func (set *orderedSet) Partition(predicate func(interface{}) bool) (Set, Set) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	matched, rest := newOrderedSet(), newOrderedSet()
+	set.each(func(elem interface{}) bool {
+		if predicate(elem) {
+			matched.insert(elem)
+		} else {
+			rest.insert(elem)
+		}
+		return false
+	})
+
+	return matched, rest
+}
+
+// GroupBy keeps the insertion order of the set within every group.
+func (set *orderedSet) GroupBy(keyFunc func(interface{}) interface{}) map[interface{}]Set {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	groups := make(map[interface{}]*orderedSet)
+	set.each(func(elem interface{}) bool {
+		key := keyFunc(elem)
+		group, found := groups[key]
+		if !found {
+			group = newOrderedSet()
+			groups[key] = group
+		}
+		group.insert(elem)
+		return false
+	})
+
+	result := make(map[interface{}]Set, len(groups))
+	for key, group := range groups {
+		result[key] = group
+	}
+	return result
+}
+
+func (set *orderedSet) Reduce(initial interface{}, accumulate func(acc, elem interface{}) interface{}) interface{} {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	acc := initial
+	set.each(func(elem interface{}) bool {
+		acc = accumulate(acc, elem)
+		return false
+	})
+
+	return acc
+}
+
+func (set *orderedSet) Any(predicate func(interface{}) bool) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	found := false
+	set.each(func(elem interface{}) bool {
+		found = predicate(elem)
+		return found
+	})
+
+	return found
+}
+
+func (set *orderedSet) All(predicate func(interface{}) bool) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	all := true
+	set.each(func(elem interface{}) bool {
+		all = predicate(elem)
+		return !all
+	})
+
+	return all
+}
+
+func (set *orderedSet) Min(less func(a, b interface{}) bool) (interface{}, bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return minimum(less, set.each)
+}
+
+func (set *orderedSet) Max(less func(a, b interface{}) bool) (interface{}, bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return minimum(func(a, b interface{}) bool { return less(b, a) }, set.each)
+}
+
+func (set *orderedSet) Count(predicate func(interface{}) bool) int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	count := 0
+	set.each(func(elem interface{}) bool {
+		if predicate(elem) {
+			count++
+		}
+		return false
+	})
+
+	return count
+}
+
+func (set *orderedSet) Iter() <-chan interface{} {
+	ch := make(chan interface{}, iterBufferSize)
+	go func() {
+		set.mutex.RLock()
+		set.each(func(elem interface{}) bool {
+			ch <- elem
+			return false
+		})
+		close(ch)
+		set.mutex.RUnlock()
+	}()
+
+	return ch
+}
+
+func (set *orderedSet) IterContext(ctx context.Context) <-chan interface{} {
+	ch := make(chan interface{}, iterBufferSize)
+	go func() {
+		set.mutex.RLock()
+		set.each(func(elem interface{}) bool {
+			select {
+			case <-ctx.Done():
+				return true
+			case ch <- elem:
+				return false
+			}
+		})
+		close(ch)
+		set.mutex.RUnlock()
+	}()
+
+	return ch
+}
+
+func (set *orderedSet) IterSnapshot() <-chan interface{} {
+	return iterSlice(set.Elements())
+}
+
+func (set *orderedSet) IterSorted(less func(a, b interface{}) bool) <-chan interface{} {
+	return iterSlice(set.ToSortedSlice(less))
+}
+
+func (set *orderedSet) Tee() (<-chan interface{}, <-chan interface{}) {
+	return teeSlice(set.Elements())
+}
+
+func (set *orderedSet) Iterator() *Iterator {
+	iterator, ch, stopCh := newIterator()
+
+	go func() {
+		set.mutex.RLock()
+		set.each(func(elem interface{}) bool {
+			select {
+			case <-stopCh:
+				return true
+			case ch <- elem:
+				return false
+			}
+		})
+		close(ch)
+		set.mutex.RUnlock()
+	}()
+
+	return iterator
+}
+
+func (set *orderedSet) Elements() []interface{} {
+	return set.ToSlice()
+}
+
+func (set *orderedSet) Remove(i interface{}) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	set.remove(i)
+}
+
+func (set *orderedSet) RemoveAll(i ...interface{}) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	removed := 0
+	for _, item := range i {
+		if set.remove(item) {
+			removed++
+		}
+	}
+
+	return removed
+}
+
+func (set *orderedSet) RetainAll(other Set) int {
+	other = nonNil(other)
+	o := set.coerce(other)
+	if o == set {
+		return 0
+	}
+
+	if lessAddress(&set.mutex, &o.mutex) {
+		set.mutex.Lock()
+		o.mutex.RLock()
+	} else {
+		o.mutex.RLock()
+		set.mutex.Lock()
+	}
+	defer set.mutex.Unlock()
+	defer o.mutex.RUnlock()
+
+	removed := 0
+	for elem := range set.index {
+		if !o.has(elem) {
+			set.remove(elem)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+func (set *orderedSet) AddSet(other Set) int {
+	other = nonNil(other)
+	o := set.coerce(other)
+	if o == set {
+		return 0
+	}
+
+	if lessAddress(&set.mutex, &o.mutex) {
+		set.mutex.Lock()
+		o.mutex.RLock()
+	} else {
+		o.mutex.RLock()
+		set.mutex.Lock()
+	}
+	defer set.mutex.Unlock()
+	defer o.mutex.RUnlock()
+
+	added := 0
+	o.each(func(elem interface{}) bool {
+		if set.insert(elem) {
+			added++
+		}
+		return false
+	})
+
+	return added
+}
+
+func (set *orderedSet) SubtractSet(other Set) int {
+	other = nonNil(other)
+	o := set.coerce(other)
+	if o == set {
+		set.mutex.Lock()
+		defer set.mutex.Unlock()
+
+		removed := len(set.index)
+		set.index = make(map[interface{}]*list.Element)
+		set.order = list.New()
+		return removed
+	}
+
+	if lessAddress(&set.mutex, &o.mutex) {
+		set.mutex.Lock()
+		o.mutex.RLock()
+	} else {
+		o.mutex.RLock()
+		set.mutex.Lock()
+	}
+	defer set.mutex.Unlock()
+	defer o.mutex.RUnlock()
+
+	removed := 0
+	for elem := range set.index {
+		if o.has(elem) {
+			set.remove(elem)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+func (set *orderedSet) String() string {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	items := make([]string, 0, len(set.index))
+	set.each(func(elem interface{}) bool {
+		items = append(items, fmt.Sprintf("%v", elem))
+		return false
+	})
+
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+func (set *orderedSet) SymmetricDifference(other Set) Set {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockOrdered(set, o)
+	defer unlock()
+
+	diff := newOrderedSet()
+	set.each(func(elem interface{}) bool {
+		if !o.has(elem) {
+			diff.insert(elem)
+		}
+		return false
+	})
+	o.each(func(elem interface{}) bool {
+		if !set.has(elem) {
+			diff.insert(elem)
+		}
+		return false
+	})
+
+	return diff
+}
+
+func (set *orderedSet) Union(other Set) Set {
+	other = nonNil(other)
+	return set.UnionAll(other)
+}
+
+func (set *orderedSet) UnionAll(others ...Set) Set {
+	others = nonNilAll(others)
+	sets := make([]*orderedSet, 0, len(others)+1)
+	sets = append(sets, set)
+	for _, other := range others {
+		sets = append(sets, set.coerce(other))
+	}
+
+	unlock := rlockOrdered(sets...)
+	defer unlock()
+
+	union := newOrderedSet()
+	for _, s := range sets {
+		s.each(func(elem interface{}) bool {
+			union.insert(elem)
+			return false
+		})
+	}
+
+	return union
+}
+
+// Pop removes and returns the oldest element of the set.
+func (set *orderedSet) Pop() interface{} {
+	item, _ := set.TryPop()
+	return item
+}
+
+// TryPop removes and returns the oldest element of the set.
+func (set *orderedSet) TryPop() (interface{}, bool) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.pop()
+}
+
+// Peek returns the oldest element, the one Pop would remove.
+func (set *orderedSet) Peek() (interface{}, bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	e := set.order.Front()
+	if e == nil {
+		return nil, false
+	}
+	return e.Value, true
+}
+
+func (set *orderedSet) Sample(n int) Set {
+	if set.rand == nil {
+		return set.SampleWithRand(n, nil)
+	}
+
+	set.randMutex.Lock()
+	defer set.randMutex.Unlock()
+
+	return set.SampleWithRand(n, set.rand)
+}
+
+// SampleWithRand keeps the sampled elements in the insertion order of the
+// set.
+func (set *orderedSet) SampleWithRand(n int, r *rand.Rand) Set {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	picked := newThreadUnsafeSet()
+	for _, elem := range reservoir(n, r, set.each) {
+		picked.Add(elem)
+	}
+
+	sample := newOrderedSet()
+	set.each(func(elem interface{}) bool {
+		if picked.Contains(elem) {
+			sample.insert(elem)
+		}
+		return false
+	})
+	return sample
+}
+
+func (set *orderedSet) PowerSet() Set {
+	set.mutex.RLock()
+	items := set.items()
+	set.mutex.RUnlock()
+
+	// Every subset lists its elements in the order of the receiver.
+	subsets := [][]interface{}{{}}
+	for _, item := range items {
+		for _, subset := range subsets {
+			extended := make([]interface{}, len(subset), len(subset)+1)
+			copy(extended, subset)
+			subsets = append(subsets, append(extended, item))
+		}
+	}
+
+	powSet := newOrderedSet()
+	for _, subset := range subsets {
+		s := newOrderedSet()
+		for _, item := range subset {
+			s.insert(item)
+		}
+		powSet.insert(s)
+	}
+
+	return powSet
+}
+
+func (set *orderedSet) PowerSetIterator() *Iterator {
+	set.mutex.RLock()
+	items := set.items()
+	set.mutex.RUnlock()
+
+	// Every subset lists its elements in the order of the receiver.
+	return powerSetIterator(items, func(items []interface{}) Set {
+		subset := newOrderedSet()
+		for _, item := range items {
+			subset.insert(item)
+		}
+		return subset
+	})
+}
+
+func (set *orderedSet) CartesianProduct(other Set) Set {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockOrdered(set, o)
+	defer unlock()
+
+	cartProduct := newOrderedSet()
+	set.each(func(i interface{}) bool {
+		o.each(func(j interface{}) bool {
+			cartProduct.insert(OrderedPair{First: i, Second: j})
+			return false
+		})
+		return false
+	})
+
+	return cartProduct
+}
+
+func (set *orderedSet) CartesianProductIterator(other Set) *PairIterator {
+	other = nonNil(other)
+	return cartesianProductIterator(set.ToSlice(), other.ToSlice())
+}
+
+func (set *orderedSet) ToSlice() []interface{} {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.items()
+}
+
+func (set *orderedSet) Chunk(size int) [][]interface{} {
+	return chunk(set.ToSlice(), size)
+}
+
+func (set *orderedSet) ToMap() map[interface{}]struct{} {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	m := make(map[interface{}]struct{}, len(set.index))
+	for elem := range set.index {
+		m[elem] = struct{}{}
+	}
+	return m
+}
+
+// Drain returns the elements in insertion order.
+func (set *orderedSet) Drain() []interface{} {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	items := set.items()
+	set.index = make(map[interface{}]*list.Element)
+	set.order = list.New()
+
+	return items
+}
+
+func (set *orderedSet) ToSortedSlice(less func(a, b interface{}) bool) []interface{} {
+	keys := set.ToSlice()
+	sort.SliceStable(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+
+	return keys
+}
+
+func (set *orderedSet) Hash() uint64 {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	var h uint64
+	for elem := range set.index {
+		h ^= mixHash(hashElement(elem))
+	}
+	return h
+}
+
+func (set *orderedSet) StringSorted() string {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	unordered := set.unordered()
+	return unordered.StringSorted()
+}
+
+func (set *orderedSet) Strings() []string {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	keys := make([]string, 0, len(set.index))
+	set.each(func(elem interface{}) bool {
+		if s, ok := elem.(string); ok {
+			keys = append(keys, s)
+		}
+		return false
+	})
+
+	return keys
+}
+
+func (set *orderedSet) StringsWithConversion() []string {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	keys := make([]string, 0, len(set.index))
+	set.each(func(elem interface{}) bool {
+		keys = append(keys, fmt.Sprintf("%v", elem))
+		return false
+	})
+
+	return keys
+}
+
+func (set *orderedSet) Ints() []int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	keys := make([]int, 0, len(set.index))
+	set.each(func(elem interface{}) bool {
+		if i, ok := elem.(int); ok {
+			keys = append(keys, i)
+		}
+		return false
+	})
+
+	return keys
+}
+
+func (set *orderedSet) Float64s() []float64 {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	keys := make([]float64, 0, len(set.index))
+	set.each(func(elem interface{}) bool {
+		if f, ok := elem.(float64); ok {
+			keys = append(keys, f)
+		}
+		return false
+	})
+
+	return keys
+}
+
+// MarshalJSON creates a JSON array from the set, in insertion order.
+func (set *orderedSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(set.ToSlice())
+}
+
+func (set *orderedSet) MarshalJSONSorted() ([]byte, error) {
+	set.mutex.RLock()
+	unordered := set.unordered()
+	set.mutex.RUnlock()
+
+	return unordered.MarshalJSONSorted()
+}
+
+// UnmarshalJSON adds the primitive items of a JSON array to the set, in
+// the order they appear.
+func (set *orderedSet) UnmarshalJSON(p []byte) error {
+	items, err := unmarshalJSONElements(p)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *orderedSet) GobEncode() ([]byte, error) {
+	return gobEncodeElements(set.ToSlice())
+}
+
+func (set *orderedSet) UnmarshalJSONWith(b []byte, convertNumber func(json.Number) interface{}) error {
+	items, err := unmarshalJSONElementsWith(b, convertNumber)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *orderedSet) UnmarshalJSONInto(b []byte, prototype func() interface{}) error {
+	items, err := unmarshalJSONElementsInto(b, prototype)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *orderedSet) GobDecode(b []byte) error {
+	items, err := gobDecodeElements(b)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *orderedSet) MarshalBinary() ([]byte, error) {
+	return marshalBinaryElements(set.ToSlice())
+}
+
+func (set *orderedSet) UnmarshalBinary(data []byte) error {
+	items, err := unmarshalBinaryElements(data)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *orderedSet) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalXMLElements(e, start, set.ToSlice())
+}
+
+func (set *orderedSet) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	items, err := unmarshalXMLElements(d, start)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *orderedSet) MarshalText() ([]byte, error) {
+	return marshalTextElements(set.StringsWithConversion()), nil
+}
+
+func (set *orderedSet) UnmarshalText(text []byte) error {
+	items, err := unmarshalTextElements(text)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *orderedSet) WriteCSV(w io.Writer) error {
+	return writeCSVElements(w, set.StringsWithConversion())
+}
+
+func (set *orderedSet) ReadCSV(r io.Reader) error {
+	items, err := readCSVElements(r)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
@@ -0,0 +1,173 @@
+package mapset
+
+import (
+	"encoding/json"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func assertOrder(s Set, expected []interface{}, t *testing.T) {
+	if actual := s.ToSlice(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected elements in order %v, got %v", expected, actual)
+	}
+}
+
+func Test_OrderedSetOrder(t *testing.T) {
+	a := NewOrderedSet(3, 1, 2, 1)
+	assertOrder(a, []interface{}{3, 1, 2}, t)
+
+	a.AddAll(0, 3)
+	a.Remove(1)
+	assertOrder(a, []interface{}{3, 2, 0}, t)
+
+	var each []interface{}
+	a.Each(func(elem interface{}) bool {
+		each = append(each, elem)
+		return false
+	})
+	if !reflect.DeepEqual(each, []interface{}{3, 2, 0}) {
+		t.Errorf("Each should visit elements in insertion order, got %v", each)
+	}
+
+	var iter []interface{}
+	for elem := range a.Iter() {
+		iter = append(iter, elem)
+	}
+	if !reflect.DeepEqual(iter, []interface{}{3, 2, 0}) {
+		t.Errorf("Iter should yield elements in insertion order, got %v", iter)
+	}
+
+	var iterator []interface{}
+	for elem := range a.Iterator().C {
+		iterator = append(iterator, elem)
+	}
+	if !reflect.DeepEqual(iterator, []interface{}{3, 2, 0}) {
+		t.Errorf("Iterator should yield elements in insertion order, got %v", iterator)
+	}
+
+	if a.String() != "Set{3, 2, 0}" {
+		t.Errorf("String should list elements in insertion order, got %s", a.String())
+	}
+
+	if b, _ := json.Marshal(a); string(b) != "[3,2,0]" {
+		t.Errorf("MarshalJSON should list elements in insertion order, got %s", b)
+	}
+
+	if a.Pop() != 3 || a.Pop() != 2 {
+		t.Error("Pop should remove the oldest elements first")
+	}
+}
+
+func Test_OrderedSetOperations(t *testing.T) {
+	a := NewOrderedSet(3, 2, 1)
+	b := NewOrderedSet(4, 3)
+
+	assertOrder(a.Union(b), []interface{}{3, 2, 1, 4}, t)
+	assertOrder(a.Intersect(b), []interface{}{3}, t)
+	assertOrder(a.Difference(b), []interface{}{2, 1}, t)
+	assertOrder(a.SymmetricDifference(b), []interface{}{2, 1, 4}, t)
+	assertOrder(a.Clone(), []interface{}{3, 2, 1}, t)
+
+	if !NewOrderedSet(1, 2).IsProperSubset(a) || !a.IsSuperset(NewOrderedSet(3)) {
+		t.Error("subset relations are wrong")
+	}
+
+	if !a.Equal(NewOrderedSet(1, 2, 3)) {
+		t.Error("Equal should ignore the insertion order")
+	}
+
+	if removed := a.RetainAll(b); removed != 2 {
+		t.Errorf("RetainAll should report 2 items removed, got %d", removed)
+	}
+	assertOrder(a, []interface{}{3}, t)
+
+	if a.PowerSet().Cardinality() != 2 || a.CartesianProduct(b).Cardinality() != 2 {
+		t.Error("PowerSet or CartesianProduct has the wrong cardinality")
+	}
+}
+
+func Test_OrderedSetJSON(t *testing.T) {
+	a := NewOrderedSet()
+	if err := json.Unmarshal([]byte(`["c", "a", "b"]`), a); err != nil {
+		t.Errorf("Error should be nil: %v", err)
+	}
+
+	assertOrder(a, []interface{}{"c", "a", "b"}, t)
+}
+
+func Test_OrderedSetConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s := NewOrderedSet()
+
+	var wg sync.WaitGroup
+	wg.Add(2 * N)
+	for i := 0; i < N; i++ {
+		go func(i int) {
+			s.Add(i)
+			wg.Done()
+		}(i)
+		go func(i int) {
+			s.Remove(i - 1)
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	if s.Cardinality() != len(s.ToSlice()) {
+		t.Error("The insertion order is out of sync with the set contents")
+	}
+}
+
+func Test_OrderedSetCopyTo(t *testing.T) {
+	dest := NewOrderedSet(9)
+	NewOrderedSet(3, 1, 2).CopyTo(dest)
+
+	assertOrder(dest, []interface{}{3, 1, 2}, t)
+}
+
+func Test_OrderedSetPeek(t *testing.T) {
+	a := NewOrderedSet(3, 1, 2)
+
+	if item, ok := a.Peek(); !ok || item != 3 {
+		t.Errorf("Peek should return the oldest element 3, got %v", item)
+	}
+	if item := a.Pop(); item != 3 {
+		t.Errorf("Pop should remove the element Peek returned, got %v", item)
+	}
+}
+
+func Test_OrderedSetIterateIndexed(t *testing.T) {
+	a := NewOrderedSet("c", "a", "b")
+
+	var elems []interface{}
+	a.IterateIndexed(func(index int, elem interface{}) bool {
+		if index != len(elems) {
+			t.Errorf("unexpected index %d for %v", index, elem)
+		}
+		elems = append(elems, elem)
+		return true
+	})
+	if !reflect.DeepEqual(elems, []interface{}{"c", "a", "b"}) {
+		t.Errorf("IterateIndexed should follow the insertion order, got %v", elems)
+	}
+}
+
+func Test_SeededSetDeterministic(t *testing.T) {
+	items := []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	a, b := NewSeededSet(42, items...), NewSeededSet(42, items...)
+
+	for i := 0; i < 5; i++ {
+		if sa, sb := a.Sample(3), b.Sample(3); !reflect.DeepEqual(sa.ToSlice(), sb.ToSlice()) {
+			t.Errorf("sets with the same seed should sample the same elements, got %v and %v", sa, sb)
+		}
+	}
+
+	for a.Cardinality() > 0 {
+		if pa, pb := a.Pop(), b.Pop(); pa != pb {
+			t.Errorf("sets with the same seed should pop the same elements, got %v and %v", pa, pb)
+		}
+	}
+}
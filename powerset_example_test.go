@@ -0,0 +1,31 @@
+package mapset
+
+import (
+	"fmt"
+	"sort"
+)
+
+func ExampleSet_PowerSet() {
+	set := NewSetWith("a", "b", "c")
+
+	var subsets []string
+	for elem := range set.PowerSet().Iter() {
+		subset := elem.(Set)
+		subsets = append(subsets, subset.StringSorted())
+	}
+	sort.Strings(subsets)
+
+	for _, subset := range subsets {
+		fmt.Println(subset)
+	}
+
+	// Output:
+	// Set{a, b, c}
+	// Set{a, b}
+	// Set{a, c}
+	// Set{a}
+	// Set{b, c}
+	// Set{b}
+	// Set{c}
+	// Set{}
+}
@@ -35,14 +35,38 @@ SOFTWARE.
 // that can enforce mutual exclusion through other means.
 package mapset
 
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
 // Set is the primary interface provided by the mapset package.  It
 // represents an unordered set of data and a large number of
-// operations that can be applied to that set.
+// operations that can be applied to that set. The operations taking
+// another set treat a nil Set as the empty set.
 type Set interface {
 	// Adds an element to the set. Returns whether
 	// the item was added.
 	Add(i interface{}) bool
 
+	// Adds all of the given elements to the set.
+	// Returns the number of elements that were
+	// actually added, so duplicates are not counted.
+	AddAll(i ...interface{}) int
+
+	// Adds candidate to the set, but only if guard
+	// is not in it. Returns whether candidate was
+	// added. Thread-safe sets check guard and add
+	// candidate under a single write lock, so no
+	// other goroutine can add guard in between.
+	AddIfNotContains(candidate, guard interface{}) bool
+
 	// Returns the number of elements in the set.
 	Cardinality() int
 
@@ -50,146 +74,914 @@ type Set interface {
 	Length() int
 
 	// Removes all elements from the set, leaving
-	// the empty set.
+	// the empty set. The set starts over with a new,
+	// empty backing map, releasing the memory of the
+	// old one: use Clear when the set will stay
+	// empty or hold far fewer elements.
 	Clear()
 
+	// Removes all elements from the set like Clear,
+	// but deletes them from the backing map, which
+	// keeps the capacity it grew to. Refilling the
+	// set to a similar size then allocates nothing:
+	// use it to reuse a set, e.g. once per request,
+	// keeping in mind that the memory is held until
+	// the set is discarded or cleared with Clear.
+	ClearRetainingCapacity()
+
+	// Replaces all elements of the set with the
+	// given ones. On thread-safe sets it clears and
+	// refills the set under a single write lock, so
+	// unlike Clear followed by AddAll, concurrent
+	// readers see either the old or the new
+	// contents, never an empty or partial set.
+	ReplaceAll(i ...interface{})
+
 	// Returns a clone of the set using the same
 	// implementation, duplicating all keys.
 	Clone() Set
 
+	// Returns a point-in-time copy of the set, taken
+	// atomically. It behaves exactly like Clone: later
+	// changes to the snapshot never affect the set, and
+	// vice versa. The separate name documents the
+	// intent of reading a consistent view.
+	Snapshot() Set
+
+	// Replaces the contents of dest with the
+	// elements of the set, reusing the storage dest
+	// has already allocated instead of allocating a
+	// new set as Clone does. Thread-safe sets lock
+	// the receiver for reading and dest for writing
+	// in a fixed order.
+	CopyTo(dest Set)
+
+	// Returns a read-only view of the set. Add,
+	// AddAll, AddSet, Remove, RemoveAll, RetainAll,
+	// SubtractSet, Clear, ClearRetainingCapacity,
+	// ReplaceAll, Pop and TryPop panic on the view,
+	// while read operations pass straight through to
+	// the set. Freeze does not copy the set:
+	// mutating it after freezing has undefined
+	// results.
+	//
+	// The view of a set created by NewSet, or of a
+	// wrapper built on one, reads the underlying
+	// thread-unsafe set without taking the lock, so
+	// it can be shared across goroutines without
+	// locking overhead. The sets it derives, such as
+	// Clone, Union or Map, are therefore thread-unsafe
+	// sets. Views of ordered, LRU and sharded sets
+	// still lock on every read, and derive the same
+	// sets as the frozen set does.
+	Freeze() Set
+
 	// Returns whether the given items
-	// are all in the set.
+	// are all in the set. Contains with no items
+	// returns true. See ContainsAny to check for
+	// any of the items instead.
 	Contains(i ...interface{}) bool
 
+	// Returns whether the given item is in the set,
+	// like Contains with a single item but without
+	// allocating a slice for the variadic argument,
+	// for hot membership checks.
+	Has(i interface{}) bool
+
+	// Equivalent to Contains, spelling out that
+	// every one of the given items must be in the
+	// set.
+	ContainsAll(i ...interface{}) bool
+
+	// Returns whether at least one of the given
+	// items is in the set. Checking stops at the
+	// first item found. ContainsAny with no items
+	// returns false.
+	ContainsAny(i ...interface{}) bool
+
+	// Returns whether the set holds an element
+	// whose key, as returned by keyFunc, equals that
+	// of value, so that elements can be looked up by
+	// a derived key such as an ID. keyFunc must
+	// return comparable keys. Every element is
+	// checked, so ContainsBy takes linear time; on a
+	// set created by NewSetByKey, Contains looks
+	// elements up by the set's own key in constant
+	// time instead.
+	ContainsBy(value interface{}, keyFunc func(interface{}) interface{}) bool
+
 	// Returns the difference between this set
 	// and other. The returned set will contain
 	// all elements of this set that are not also
 	// elements of other.
 	//
-	// Note that the argument to Difference
-	// must be of the same type as the receiver
-	// of the method. Otherwise, Difference will
-	// panic.
+	// The argument to Difference may be any Set
+	// implementation; the returned set uses
+	// the implementation of the receiver.
 	Difference(other Set) Set
 
+	// Returns a new set with all elements of this set
+	// that are not elements of any of the others. The
+	// receiver is left unchanged.
+	//
+	// The arguments to DifferenceAll may be any Set
+	// implementation; the returned set uses
+	// the implementation of the receiver.
+	DifferenceAll(others ...Set) Set
+
+	// Returns what changed from this set to other:
+	// added holds the elements of other that are not
+	// in this set, and removed the elements of this
+	// set that are not in other. It is equivalent to
+	// other.Difference(set) and set.Difference(other)
+	// computed under a single lock of both sets.
+	//
+	// The argument to Diff may be any Set
+	// implementation; the returned sets use
+	// the implementation of the receiver.
+	Diff(other Set) (added Set, removed Set)
+
+	// Returns both the union of the set and other
+	// and their common elements, their intersection,
+	// computed together under a single lock of both
+	// sets rather than by Union and Intersect in two
+	// separate passes.
+	//
+	// The argument to Merge may be any Set
+	// implementation; the returned sets use
+	// the implementation of the receiver.
+	Merge(other Set) (union Set, common Set)
+
 	// Determines if two sets are equal to each
 	// other. If they have the same cardinality
 	// and contain the same elements, they are
 	// considered equal. The order in which
 	// the elements were added is irrelevant.
 	//
-	// Note that the argument to Equal must be
-	// of the same type as the receiver of the
-	// method. Otherwise, Equal will panic.
+	// The argument to Equal may be any Set
+	// implementation.
 	Equal(other Set) bool
 
+	// Behaves like Equal, but compares elements with
+	// eq instead of ==, so that for instance 5 and
+	// json.Number("5") can be considered equal. The
+	// sets are equal if they have the same
+	// cardinality and every element of this set is eq
+	// to an element of other. Every element is
+	// compared with every element of other, so
+	// EqualFunc takes quadratic time.
+	//
+	// The argument to EqualFunc may be any Set
+	// implementation.
+	EqualFunc(other Set, eq func(a, b interface{}) bool) bool
+
+	// Behaves like EqualFunc, but every element of
+	// other is matched at most once, so that pointers
+	// to structs can be compared with
+	// reflect.DeepEqual: the sets are equal if they
+	// have the same cardinality and every element of
+	// this set is eq to a distinct element of other.
+	// Like EqualFunc, EqualUsing takes quadratic time.
+	//
+	// eq only affects this comparison: membership
+	// still uses ==, as elements are map keys, so an
+	// element must be comparable to be stored at all,
+	// and two elements that are eq but not == are
+	// both stored.
+	//
+	// The argument to EqualUsing may be any Set
+	// implementation.
+	EqualUsing(other Set, eq func(a, b interface{}) bool) bool
+
 	// Returns a new set containing only the elements
 	// that exist only in both sets.
 	//
-	// Note that the argument to Intersect
-	// must be of the same type as the receiver
-	// of the method. Otherwise, Intersect will
-	// panic.
+	// The argument to Intersect may be any Set
+	// implementation; the returned set uses
+	// the implementation of the receiver.
 	Intersect(other Set) Set
 
+	// Returns a new set containing only the elements
+	// that exist in this set and in every one of the
+	// others.
+	//
+	// The sets are visited from the smallest to the
+	// largest: the running intersection starts as a
+	// copy of the smallest set, only ever shrinks, and
+	// the remaining sets are skipped as soon as it
+	// becomes empty.
+	//
+	// The arguments to IntersectAll may be any Set
+	// implementation; the returned set uses
+	// the implementation of the receiver.
+	IntersectAll(others ...Set) Set
+
 	// Determines if every element in this set is in
 	// the other set but the two sets are not equal.
 	//
-	// Note that the argument to IsProperSubset
-	// must be of the same type as the receiver
-	// of the method. Otherwise, IsProperSubset
-	// will panic.
+	// The argument to IsProperSubset may be any Set
+	// implementation.
 	IsProperSubset(other Set) bool
 
 	// Determines if every element in the other set
 	// is in this set but the two sets are not
 	// equal.
 	//
-	// Note that the argument to IsSuperset
-	// must be of the same type as the receiver
-	// of the method. Otherwise, IsSuperset will
-	// panic.
+	// The argument to IsProperSuperset may be any Set
+	// implementation.
 	IsProperSuperset(other Set) bool
 
 	// Determines if every element in this set is in
 	// the other set.
 	//
-	// Note that the argument to IsSubset
-	// must be of the same type as the receiver
-	// of the method. Otherwise, IsSubset will
-	// panic.
+	// The argument to IsSubset may be any Set
+	// implementation.
 	IsSubset(other Set) bool
 
 	// Determines if every element in the other set
 	// is in this set.
 	//
-	// Note that the argument to IsSuperset
-	// must be of the same type as the receiver
-	// of the method. Otherwise, IsSuperset will
-	// panic.
+	// The argument to IsSuperset may be any Set
+	// implementation.
 	IsSuperset(other Set) bool
 
+	// Returns whether the set and other have at
+	// least one element in common. It iterates the
+	// smaller set, stops at the first common
+	// element and does not allocate.
+	//
+	// The argument to Intersects may be any Set
+	// implementation.
+	Intersects(other Set) bool
+
+	// Returns the number of elements the set and
+	// other have in common, the cardinality of
+	// their intersection, without building it. It
+	// iterates the smaller set.
+	//
+	// The argument to IntersectCardinality may be
+	// any Set implementation.
+	IntersectCardinality(other Set) int
+
+	// Returns the cardinality of the union of the
+	// set and other without building it, as the
+	// sum of both cardinalities minus that of their
+	// intersection, with both sets locked for
+	// reading.
+	//
+	// The argument to UnionCardinality may be any
+	// Set implementation.
+	UnionCardinality(other Set) int
+
+	// Returns whether the set and other have no
+	// element in common, the negation of Intersects.
+	//
+	// The argument to IsDisjoint may be any Set
+	// implementation.
+	IsDisjoint(other Set) bool
+
+	// Returns the Jaccard similarity of the set and
+	// other, the cardinality of their intersection
+	// divided by the cardinality of their union. It
+	// counts the common elements in a single pass,
+	// without building either set. By convention, two
+	// empty sets have a similarity of 1.
+	//
+	// The argument to JaccardSimilarity may be any
+	// Set implementation.
+	JaccardSimilarity(other Set) float64
+
+	// Returns the overlap coefficient of the set and
+	// other, the cardinality of their intersection
+	// divided by the cardinality of the smaller one,
+	// so it is 1 whenever one set is a subset of the
+	// other. It counts the common elements in a
+	// single pass over the smaller set. Two empty sets
+	// have a coefficient of 1, like for
+	// JaccardSimilarity, while an empty set and a
+	// non-empty one have a coefficient of 0.
+	//
+	// The argument to OverlapCoefficient may be any
+	// Set implementation.
+	OverlapCoefficient(other Set) float64
+
 	// Iterates over elements and executes the passed func against each element.
 	// If passed func returns true, stop iteration at the time.
+	//
+	// Note that returning true means stop, the opposite
+	// of most Go iteration callbacks. ForEach and
+	// ForEachUntil follow the common convention and are
+	// preferred in new code; Each is kept for
+	// compatibility.
 	Each(func(interface{}) bool)
 
+	// Iterates over elements and executes the passed
+	// func against every element.
+	ForEach(callback func(interface{}))
+
+	// Iterates over elements and executes the passed
+	// func against each element for as long as it
+	// returns true: returning false stops the
+	// iteration.
+	ForEachUntil(callback func(interface{}) bool)
+
+	// Iterates over elements like ForEachUntil, also
+	// passing the position of each element in the
+	// iteration, starting at 0. Returning false stops
+	// the iteration. The index only reflects the
+	// order of this iteration: unless the set keeps
+	// an order, like an ordered set, elements are
+	// not guaranteed to get the same index between
+	// calls.
+	IterateIndexed(callback func(index int, elem interface{}) bool)
+
+	// Calls callback on every element from a pool of
+	// workers goroutines, and returns once all calls
+	// are done, so callback must be safe for
+	// concurrent use. The elements are snapshotted
+	// first, thread-safe sets are not locked during
+	// the calls and callback may modify them. A
+	// workers value below 1 is treated as 1.
+	EachParallel(workers int, callback func(interface{}))
+
+	// Behaves like EachParallel, but returns the
+	// errors returned by callback, in no particular
+	// order, or nil if every call succeeded. Every
+	// element is visited even if some calls fail.
+	EachParallelErr(workers int, callback func(interface{}) error) []error
+
+	// Returns a new set containing the result of
+	// applying transform to every element of this set.
+	// The returned set uses the same implementation
+	// as the receiver.
+	//
+	// Note that the returned set may be smaller than
+	// this set: if transform maps several elements to
+	// the same value, that value is only stored once.
+	Map(transform func(interface{}) interface{}) Set
+
+	// Splits the set in a single pass into the
+	// elements for which predicate returns true and
+	// the rest. Both returned sets use the same
+	// implementation as the receiver, which is left
+	// unchanged.
+	Partition(predicate func(interface{}) bool) (matched Set, rest Set)
+
+	// Buckets the elements of the set by the key
+	// keyFunc derives from them, in a single pass.
+	// Each key maps to the set of elements producing
+	// it, using the same implementation as the
+	// receiver.
+	GroupBy(keyFunc func(interface{}) interface{}) map[interface{}]Set
+
+	// Folds every element of the set into an
+	// accumulator, starting from initial, and returns
+	// the final accumulator.
+	//
+	// Since the iteration order of a set is undefined,
+	// accumulate should be associative and commutative
+	// for the result to be deterministic.
+	Reduce(initial interface{}, accumulate func(acc, elem interface{}) interface{}) interface{}
+
+	// Returns whether at least one element of the
+	// set satisfies predicate. Iteration stops at the
+	// first match. Any on an empty set returns false.
+	Any(predicate func(interface{}) bool) bool
+
+	// Returns whether every element of the set
+	// satisfies predicate. Iteration stops at the
+	// first failure. All on an empty set returns true.
+	All(predicate func(interface{}) bool) bool
+
+	// Returns the smallest element of the set
+	// according to less, found in a single pass, and
+	// whether the set was non-empty. Among equal
+	// elements, the first one visited is returned.
+	Min(less func(a, b interface{}) bool) (interface{}, bool)
+
+	// Returns the largest element of the set
+	// according to less, found in a single pass, and
+	// whether the set was non-empty. Among equal
+	// elements, the first one visited is returned.
+	Max(less func(a, b interface{}) bool) (interface{}, bool)
+
+	// Returns how many elements of the set satisfy
+	// predicate, without building a set of them as
+	// Partition does.
+	Count(predicate func(interface{}) bool) int
+
 	// Returns a channel of elements that you can
-	// range over.
+	// range over. The channel is buffered, so the
+	// producer may run a few elements ahead of the
+	// consumer; the read lock of a thread-safe set is
+	// held until every element has been sent.
+	//
+	// The channel must be drained: if the consumer
+	// stops ranging over it early, the producer
+	// goroutine blocks forever, leaking it and, for
+	// thread-safe sets, never releasing the read lock,
+	// so every later write deadlocks. Use IterContext,
+	// or Iterator and its Stop method, when the loop
+	// may exit early.
 	Iter() <-chan interface{}
 
+	// Returns a channel of elements like Iter, whose
+	// producer goroutine stops, releasing the read
+	// lock of a thread-safe set, and closes the
+	// channel as soon as ctx is done. Cancelling ctx
+	// is therefore enough to abandon the iteration
+	// without leaking.
+	IterContext(ctx context.Context) <-chan interface{}
+
+	// Returns a channel of elements like Iter, fed
+	// from a snapshot of the elements taken under
+	// the read lock of a thread-safe set, which is
+	// released before the first element is sent.
+	// A slow consumer therefore never blocks
+	// writers, at the cost of copying the elements.
+	// The channel yields the set as it was when
+	// IterSnapshot was called: elements added or
+	// removed afterwards are not seen. The channel
+	// must still be drained, or the producer
+	// goroutine leaks, though it holds no lock.
+	IterSnapshot() <-chan interface{}
+
+	// Returns a channel of elements like
+	// IterSnapshot, sorted using the given less
+	// function, so that tests iterating a set see
+	// its elements in a deterministic order. The
+	// snapshot is taken like with ToSortedSlice,
+	// and the channel must be drained likewise.
+	IterSorted(less func(a, b interface{}) bool) <-chan interface{}
+
+	// Returns two channels that both yield every
+	// element, from a single snapshot taken like
+	// with IterSnapshot, so that a thread-safe set
+	// is only read and locked once, e.g. to feed two
+	// independent consumers. Each channel is fed by
+	// its own goroutine, so a slow consumer does not
+	// hold up the other, but both channels must be
+	// drained, or their producer goroutines leak.
+	Tee() (<-chan interface{}, <-chan interface{})
+
 	// Returns an Iterator object that you can
-	// use to range over the set.
+	// use to range over the set. Unlike Iter, it
+	// can be abandoned safely: calling Stop ends
+	// the producer goroutine and releases the
+	// read lock of a thread-safe set.
 	Iterator() *Iterator
 
+	// Returns the members of the set as a slice,
+	// like ToSlice, so that they can be ranged over
+	// without the goroutine and channel Iter and
+	// Iterator start on every call, which dominate
+	// the cost of iterating small sets in a tight
+	// loop. The slice is a snapshot taken under the
+	// read lock of a thread-safe set: it always
+	// holds every element, so it costs memory
+	// proportional to the size of the set and does
+	// not see changes made while it is used. Prefer
+	// Iter or Iterator to stream very large sets.
+	Elements() []interface{}
+
 	// Remove a single element from the set.
 	Remove(i interface{})
 
+	// Removes all of the given elements from the set.
+	// Returns the number of elements that were
+	// actually removed.
+	RemoveAll(i ...interface{}) int
+
+	// Removes every element of this set that is not
+	// also in other, keeping only the intersection of
+	// the two sets without allocating a new set.
+	// Returns the number of elements removed.
+	// Thread-safe sets lock the receiver for writing
+	// and other for reading in a fixed order; other
+	// implementations of other are copied before
+	// the receiver is locked.
+	//
+	// The arguments to RetainAll may be any Set
+	// implementation.
+	RetainAll(other Set) int
+
+	// Adds every element of other to this set,
+	// turning it into the union of the two sets
+	// without allocating a new set. Returns the
+	// number of elements that were actually added.
+	// Thread-safe sets lock the receiver for writing
+	// and other for reading in a fixed order; other
+	// implementations of other are copied before
+	// the receiver is locked.
+	//
+	// The arguments to AddSet may be any Set
+	// implementation.
+	AddSet(other Set) int
+
+	// Removes every element of this set that is also
+	// in other, keeping only the difference of the
+	// two sets without allocating a new set. Returns
+	// the number of elements removed, other is left
+	// untouched. Thread-safe sets lock the receiver
+	// for writing and other for reading in order of
+	// increasing address, like every operation
+	// locking several sets, so that two goroutines
+	// subtracting the sets from each other cannot
+	// deadlock; other implementations of other are
+	// copied before the receiver is locked.
+	//
+	// The arguments to SubtractSet may be any Set
+	// implementation.
+	SubtractSet(other Set) int
+
 	// Provides a convenient string representation
 	// of the current state of the set.
 	String() string
 
+	// Returns a hash of the elements of the set that
+	// does not depend on their order, so that equal
+	// sets always hash to the same value and sets can
+	// be keyed by content, e.g. in a map from hash to
+	// sets. Different sets may share a hash. The hash
+	// is stable within a process run, but not across
+	// runs or versions of this package.
+	Hash() uint64
+
+	// Provides the same representation as String,
+	// but with the formatted elements sorted so the
+	// output is deterministic, e.g. for comparing
+	// log lines.
+	StringSorted() string
+
 	// Returns a new set with all elements which are
 	// in either this set or the other set but not in both.
 	//
-	// Note that the argument to SymmetricDifference
-	// must be of the same type as the receiver
-	// of the method. Otherwise, SymmetricDifference
-	// will panic.
+	// The argument to SymmetricDifference may be any Set
+	// implementation; the returned set uses
+	// the implementation of the receiver.
 	SymmetricDifference(other Set) Set
 
 	// Returns a new set with all elements in both sets.
 	//
-	// Note that the argument to Union must be of the
-	// same type as the receiver of the method.
-	// Otherwise, IsSuperset will panic.
+	// The argument to Union may be any Set
+	// implementation; the returned set uses
+	// the implementation of the receiver.
 	Union(other Set) Set
 
+	// Returns a new set with all elements of this set
+	// and of every one of the others, computed in a
+	// single pass without intermediate sets.
+	//
+	// The arguments to UnionAll may be any Set
+	// implementation; the returned set uses
+	// the implementation of the receiver.
+	UnionAll(others ...Set) Set
+
 	// Pop removes and returns an arbitrary item from the set.
 	Pop() interface{}
 
+	// TryPop removes and returns an arbitrary item from
+	// the set, along with whether an item was removed.
+	// Unlike Pop, it lets callers tell an empty set
+	// apart from a popped nil element.
+	TryPop() (interface{}, bool)
+
+	// Returns an arbitrary item of the set without
+	// removing it, along with whether the set was
+	// non-empty.
+	Peek() (interface{}, bool)
+
+	// Returns a new set of up to n elements chosen
+	// pseudo-randomly from the set in a single,
+	// unbiased pass (reservoir sampling). If n is at
+	// least the cardinality of the set, the whole set
+	// is copied. The returned set uses the same
+	// implementation as the receiver, which is left
+	// unchanged.
+	Sample(n int) Set
+
+	// Behaves like Sample, but draws the random
+	// numbers from r. Note that the sample also
+	// depends on the iteration order of the set, so
+	// only sets with a stable order, like ordered
+	// sets, sample reproducibly for a seeded r.
+	SampleWithRand(n int, r *rand.Rand) Set
+
 	// Returns all subsets of a given set (Power Set).
+	// Every element of the returned set is itself
+	// a Set, of the same kind as the receiver, so
+	// it can be type asserted to Set.
 	PowerSet() Set
 
+	// Returns an Iterator over all subsets of the
+	// set, like PowerSet, except that the subsets
+	// are built one at a time as they are received
+	// rather than all up front. The iterator ranges
+	// over a snapshot of the elements, so the set
+	// is not locked while it is used.
+	PowerSetIterator() *Iterator
+
 	// Returns the Cartesian Product of two sets.
 	CartesianProduct(other Set) Set
 
+	// Returns a PairIterator over the Cartesian
+	// Product of two sets, like CartesianProduct,
+	// except that the pairs are sent as they are
+	// received rather than collected in a set. It
+	// ranges over snapshots of the elements of both
+	// sets, so neither is locked while it is used.
+	CartesianProductIterator(other Set) *PairIterator
+
 	// Returns the members of the set as a slice.
 	ToSlice() []interface{}
 
+	// Returns the members of the set as the keys of
+	// a map. The map is a copy, modifying it does not
+	// modify the set.
+	ToMap() map[interface{}]struct{}
+
+	// Returns the members of the set split into
+	// slices of at most size elements each, e.g. to
+	// send them in batches. Only the last slice may
+	// be shorter. A size below 1 is treated as 1.
+	Chunk(size int) [][]interface{}
+
+	// Removes all elements from the set and returns
+	// them as a slice, in a single atomic step for
+	// thread-safe sets: unlike ToSlice followed by
+	// Clear, no element added in between is lost.
+	Drain() []interface{}
+
+	// Returns the members of the set as a slice,
+	// sorted using the given less function. For
+	// thread-safe sets the elements are copied
+	// under the read lock, which is released before
+	// sorting: less is called without the lock, and
+	// sees the set as it was when ToSortedSlice was
+	// called. Elements that less does not order come
+	// out in an unspecified order, except in ordered
+	// sets, where they keep their insertion order.
+	ToSortedSlice(less func(a, b interface{}) bool) []interface{}
+
 	// Returns the string members of the set as a slice
 	Strings() []string
+
+	// Returns every member of the set formatted with
+	// fmt's %v verb, so non-string elements are
+	// converted rather than skipped.
+	StringsWithConversion() []string
+
+	// Returns the int members of the set as a slice.
+	// Elements of any other type, including
+	// json.Number, are skipped.
+	Ints() []int
+
+	// Returns the float64 members of the set as a slice.
+	// Elements of any other type, including
+	// json.Number, are skipped.
+	Float64s() []float64
+
+	// Returns the set as a JSON array like MarshalJSON,
+	// but with the elements sorted lexicographically by
+	// their JSON encoding, so equal sets always produce
+	// the same output.
+	MarshalJSONSorted() ([]byte, error)
+
+	// Adds the elements of a JSON array to the set,
+	// decoding each of them with encoding/json into
+	// a new value returned by prototype, which must
+	// be a non-nil pointer. The value it points to
+	// is added, unless the pointer itself is a Set,
+	// which is then added as is: nested sets decode
+	// with a prototype returning NewSet(), and
+	// coordinate pairs with one returning new([2]int).
+	// Decoding fails for elements that cannot be
+	// stored in a set, such as maps and slices.
+	// Nothing is added if any element fails.
+	UnmarshalJSONInto(b []byte, prototype func() interface{}) error
+
+	// Adds the elements of a JSON array to the set
+	// like UnmarshalJSON, but passes every number
+	// through convertNumber instead of adding it as a
+	// json.Number. With ConvertJSONNumber, a set of
+	// ints or float64s equals its decoded form.
+	UnmarshalJSONWith(b []byte, convertNumber func(json.Number) interface{}) error
+
+	// Returns a canonical text form of the set: its
+	// elements, formatted like StringsWithConversion,
+	// sorted and joined with '|', with any '|' or
+	// backslash inside an element escaped by a
+	// backslash, e.g. "a|b|c". It makes Set an
+	// encoding.TextMarshaler, so sets can be used as
+	// JSON object keys. Note that the empty set and a
+	// set holding only the empty string share the
+	// same text form.
+	MarshalText() ([]byte, error)
+
+	// Adds the elements of the text form produced
+	// by MarshalText to the set, as strings. An
+	// empty text adds nothing.
+	UnmarshalText(text []byte) error
+
+	// Writes every element as a single-column CSV
+	// row, quoting fields as needed. String elements
+	// are written as is, any other element is
+	// formatted with fmt like StringsWithConversion.
+	WriteCSV(w io.Writer) error
+
+	// Reads CSV rows and adds every field to the set
+	// as a string element, the inverse of WriteCSV
+	// for sets of strings.
+	ReadCSV(r io.Reader) error
+}
+
+// ConvertJSONNumber converts a number decoded by UnmarshalJSONWith to the
+// Go type a literal of it would have: numbers without a fraction or an
+// exponent become an int, others a float64. Integers too large for an int
+// are kept as a json.Number rather than losing precision as a float64.
+func ConvertJSONNumber(n json.Number) interface{} {
+	s := n.String()
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.Atoi(s); err == nil {
+			return i
+		}
+		return n
+	}
+
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n
+}
+
+// Union returns a new set with the elements of every one of sets, using
+// the implementation of the first set like sets[0].UnionAll(sets[1:]...).
+// Without sets, or when the first set is nil, it returns a new
+// thread-safe set.
+func Union(sets ...Set) Set {
+	if len(sets) == 0 || sets[0] == nil {
+		return NewSet().UnionAll(sets...)
+	}
+	return sets[0].UnionAll(sets[1:]...)
+}
+
+// Intersect returns a new set with the elements common to all of sets,
+// using the implementation of the first set like
+// sets[0].IntersectAll(sets[1:]...). Without sets, or when the first set
+// is nil, it returns a new empty thread-safe set.
+func Intersect(sets ...Set) Set {
+	if len(sets) == 0 || sets[0] == nil {
+		return NewSet()
+	}
+	return sets[0].IntersectAll(sets[1:]...)
+}
+
+// Difference returns a new set with the elements of base that are in none
+// of others, using the implementation of base like
+// base.DifferenceAll(others...). A nil base gives a new empty thread-safe
+// set.
+func Difference(base Set, others ...Set) Set {
+	if base == nil {
+		return NewSet()
+	}
+	return base.DifferenceAll(others...)
+}
+
+// Move removes elem from from and adds it to to, and returns whether elem
+// was in from; if it was not, to is left unchanged. When both sets are
+// plain thread-safe, thread-unsafe, sharded or ordered sets, such as those
+// returned by NewSet, NewThreadUnsafeSet, NewShardedSet and NewOrderedSet,
+// Move write-locks both sets in the order used by every operation locking
+// several sets, so that no other goroutine sees elem in neither or both
+// of them, e.g. for state machine transitions. Only the shard holding
+// elem is locked in a sharded set. For any other implementation, such as
+// bounded, LRU or observed sets, Move removes elem from from and then
+// adds it to to, and other goroutines may see elem in neither set in
+// between.
+func Move(from, to Set, elem interface{}) bool {
+	src, srcOK := moveSideOf(from, elem)
+	dst, dstOK := moveSideOf(to, elem)
+	if !srcOK || !dstOK {
+		if from.RemoveAll(elem) == 0 {
+			return false
+		}
+		to.Add(elem)
+		return true
+	}
+
+	var mutexes []*sync.RWMutex
+	for _, m := range []*sync.RWMutex{src.mutex, dst.mutex} {
+		if m != nil {
+			mutexes = append(mutexes, m)
+		}
+	}
+	unlock := lockAll(mutexes...)
+	defer unlock()
+
+	if !src.remove(elem) {
+		return false
+	}
+	dst.insert(elem)
+	return true
+}
+
+// Swap exchanges the elements of a and b, e.g. to flip the buffers of a
+// double-buffered set. When a and b are both thread-safe, thread-unsafe,
+// or ordered sets, Swap exchanges their backing maps in O(1), under both
+// write locks taken in the order used by every operation locking several
+// sets, so that it cannot deadlock; two sharded sets with the same number
+// of shards exchange the map of each shard, under the locks of every
+// shard. For any other pair of sets, Swap copies the elements of each set
+// and replaces the elements of the other with them, and other goroutines
+// may see the sets in between.
+func Swap(a, b Set) {
+	switch x := a.(type) {
+	case *threadUnsafeSet:
+		if y, ok := b.(*threadUnsafeSet); ok {
+			*x, *y = *y, *x
+			return
+		}
+	case *threadSafeSet:
+		if y, ok := b.(*threadSafeSet); ok {
+			unlock := lockAll(&x.mutex, &y.mutex)
+			defer unlock()
+
+			x.objects, y.objects = y.objects, x.objects
+			return
+		}
+	case *shardedSet:
+		if y, ok := b.(*shardedSet); ok && len(x.shards) == len(y.shards) {
+			unlock := lockAll(append(x.mutexes(), y.mutexes()...)...)
+			defer unlock()
+
+			// Both sets place an element in the same shard index.
+			for i := range x.shards {
+				x.shards[i].objects, y.shards[i].objects = y.shards[i].objects, x.shards[i].objects
+			}
+			return
+		}
+	case *orderedSet:
+		if y, ok := b.(*orderedSet); ok {
+			unlock := lockAll(&x.mutex, &y.mutex)
+			defer unlock()
+
+			x.index, y.index = y.index, x.index
+			x.order, y.order = y.order, x.order
+			return
+		}
+	}
+
+	itemsA, itemsB := a.ToSlice(), b.ToSlice()
+	a.ReplaceAll(itemsB...)
+	b.ReplaceAll(itemsA...)
+}
+
+// AtLeastK returns a new thread-safe set of the elements held by at least
+// k of sets, e.g. the options picked by a quorum of voters. A k of 1 or
+// less gives the union of sets, a k of len(sets) their intersection, and a
+// k above len(sets) the empty set. A set given several times counts once
+// per occurrence. The elements of every set are counted in a single pass,
+// so AtLeastK runs in O(total elements) whatever k is.
+func AtLeastK(k int, sets ...Set) Set {
+	if k < 1 {
+		k = 1
+	}
+
+	result := NewSet()
+	if k > len(sets) {
+		return result
+	}
+
+	counts := make(map[interface{}]int)
+	for _, set := range nonNilAll(sets) {
+		for _, elem := range set.ToSlice() {
+			counts[elem]++
+			if counts[elem] == k {
+				result.Add(elem)
+			}
+		}
+	}
+	return result
 }
 
 // NewSet creates and returns a reference to an empty set.  Operations
 // on the resulting set are thread-safe.
 func NewSet(objects ...interface{}) Set {
-	set := newThreadSafeSet()
+	set := newThreadSafeSetWithSize(len(objects))
 	for _, item := range objects {
 		set.Add(item)
 	}
 	return &set
 }
 
+// NewThreadSafeSetWithSize creates and returns a reference to an empty
+// set with enough space preallocated to hold size elements. A negative
+// size is treated as 0. Operations on the resulting set are thread-safe.
+func NewThreadSafeSetWithSize(size int) Set {
+	set := newThreadSafeSetWithSize(size)
+	return &set
+}
+
 // NewSetWith creates and returns a new set with the given elements.
 // Operations on the resulting set are thread-safe.
 func NewSetWith(objects ...interface{}) Set {
@@ -197,7 +989,8 @@ func NewSetWith(objects ...interface{}) Set {
 }
 
 // NewSetFromSlice creates and returns a reference to a set from an
-// existing slice.  Operations on the resulting set are thread-safe.
+// existing slice, preallocated to hold len(objects) elements.
+// Operations on the resulting set are thread-safe.
 func NewSetFromSlice(objects []interface{}) Set {
 	return NewSet(objects...)
 }
@@ -205,13 +998,198 @@ func NewSetFromSlice(objects []interface{}) Set {
 // NewSetFromStrings creates and returns a reference to a set from an
 // existing string array.  Operations on the resulting set are thread-safe.
 func NewSetFromStrings(objects []string) Set {
-	set := newThreadSafeSet()
+	set := newThreadSafeSetWithSize(len(objects))
 	for _, object := range objects {
 		set.Add(object)
 	}
 	return &set
 }
 
+// NewSetFromMap creates and returns a reference to a set holding the keys
+// of m, the counterpart of ToMap. The keys are copied, so modifying m
+// afterwards does not modify the set. Operations on the resulting set
+// are thread-safe.
+func NewSetFromMap(m map[interface{}]struct{}) Set {
+	set := newThreadSafeSetWithSize(len(m))
+	for elem := range m {
+		set.objects[elem] = struct{}{}
+	}
+	return &set
+}
+
+// NewSetFromMapKeys creates and returns a reference to a set holding the
+// keys of m, which must be a map of any type; it panics with a
+// *TypeMismatchError otherwise. The keys are read through reflection,
+// which costs an allocation and a dynamic call per key, so a plain loop
+// over a map of a known type is faster. Operations on the resulting set
+// are thread-safe.
+func NewSetFromMapKeys(m interface{}) Set {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map {
+		panic(newTypeMismatchError("NewSetFromMapKeys", "a map", m))
+	}
+
+	set := newThreadSafeSetWithSize(v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		set.objects[iter.Key().Interface()] = struct{}{}
+	}
+	return &set
+}
+
+// CollectChan creates and returns a reference to a set holding every
+// element received from ch, the inverse of Iter. It returns once ch is
+// closed, so ch must be closed by its producer. Operations on the
+// resulting set are thread-safe.
+func CollectChan(ch <-chan interface{}) Set {
+	set := newThreadSafeSet()
+	for elem := range ch {
+		set.objects[elem] = struct{}{}
+	}
+	return &set
+}
+
+// CollectFunc creates and returns a reference to a set holding every
+// element returned by next, which is called until it returns false, such
+// as the Next method of a SliceIterator. Operations on the resulting set
+// are thread-safe.
+func CollectFunc(next func() (interface{}, bool)) Set {
+	set := newThreadSafeSet()
+	for elem, ok := next(); ok; elem, ok = next() {
+		set.objects[elem] = struct{}{}
+	}
+	return &set
+}
+
+// NewShardedSet creates and returns a reference to an empty set whose
+// elements are spread over the given number of shards, each guarded by
+// its own lock. Operations on the resulting set are thread-safe, and
+// goroutines adding or looking up elements that fall into different
+// shards don't contend with each other, which makes it a better fit
+// than NewSet for heavy concurrent writes. Operations spanning the whole
+// set, such as Cardinality or Union, lock every shard.
+//
+// Binary operations on a sharded set accept any Set implementation; an
+// argument that isn't a sharded set is copied into one first. A shards
+// value below 1 is treated as 1.
+func NewShardedSet(shards int) Set {
+	return newShardedSet(shards)
+}
+
+// NewOrderedSet creates and returns a reference to a set holding the
+// given elements that remembers the order in which elements were first
+// added. Iter, Iterator, Each, ToSlice, String and MarshalJSON all yield
+// elements in that order, Pop removes the oldest element, and removing
+// an element splices it out of the order. Operations on the resulting
+// set are thread-safe.
+//
+// Binary operations on an ordered set accept any Set implementation; an
+// argument that isn't an ordered set is copied into one first. Their
+// results list the receiver's elements first.
+func NewOrderedSet(objects ...interface{}) Set {
+	set := newOrderedSet()
+	for _, item := range objects {
+		set.insert(item)
+	}
+	return set
+}
+
+// NewSeededSet creates and returns a reference to an ordered set holding
+// the given elements, see NewOrderedSet, whose Sample draws its random
+// numbers from a source seeded with seed. Since Pop removes the oldest
+// element and the iteration order is the insertion order, every operation
+// on the set is deterministic, which makes it suitable for reproducible
+// tests. Sets derived from it, such as clones, are not seeded.
+// Operations on the resulting set are thread-safe.
+func NewSeededSet(seed int64, objects ...interface{}) Set {
+	set := newOrderedSet()
+	set.rand = rand.New(rand.NewSource(seed))
+	for _, item := range objects {
+		set.insert(item)
+	}
+	return set
+}
+
+// NewBoundedSet creates and returns a reference to an empty set holding
+// at most max distinct elements. Once it is full, Add returns false and
+// leaves the set unchanged, and AddAll and the decoders only insert
+// elements while there is room; adding an element that is already in
+// the set is always allowed. Removing elements makes room again.
+// Operations on the resulting set are thread-safe.
+//
+// Clone and Snapshot return a bounded set with the same max, other
+// operations deriving a new set, such as Union or Map, return ordinary
+// thread-safe sets, which may exceed the bound. A negative max is
+// treated as 0.
+func NewBoundedSet(max int) Set {
+	return newBoundedSet(max)
+}
+
+// NewNonNilSet creates and returns a reference to an empty set that never
+// holds nil: Add returns false and leaves the set unchanged for nil, or a
+// nil pointer, map, slice, channel or func, and AddAll, ReplaceAll and the
+// decoders skip them, so Contains(nil) is always false. The other sets
+// still accept nil as an element, for compatibility. Operations on the
+// resulting set are thread-safe.
+//
+// Clone and Snapshot return a set rejecting nil too, other operations
+// deriving a new set, such as Union or Map, return ordinary thread-safe
+// sets.
+func NewNonNilSet() Set {
+	return newNonNilSet()
+}
+
+// NewSetByKey creates and returns a reference to an empty set that
+// treats two elements as the same when keyFunc returns the same key for
+// them, and keeps the first element added for each key. This lets it
+// hold elements that should be compared by value rather than identity,
+// such as sets: keying each set by its StringSorted form deduplicates
+// sets holding the same elements.
+// keyFunc must return a comparable value and is called under the lock,
+// so it must not use the set itself. Operations on the resulting set
+// are thread-safe.
+//
+// Adding, looking up and removing elements, as well as RetainAll and
+// SubtractSet, go through keyFunc. Clone and Snapshot return a set with
+// the same keyFunc, other operations, such as Equal, IsSubset, Union or
+// Map, see the stored elements, compare them with == and return
+// ordinary thread-safe sets.
+func NewSetByKey(keyFunc func(interface{}) interface{}) Set {
+	return newKeyedSet(keyFunc)
+}
+
+// NewObservedSet returns a set wrapping inner that calls the hooks of
+// observer after every operation adding, removing or looking up elements,
+// e.g. to count them with metrics. Every operation is performed by inner,
+// so the resulting set is thread-safe if inner is. Sets derived from it,
+// such as clones or unions, are created by inner and are not observed.
+// Changes made through inner directly are not observed.
+func NewObservedSet(inner Set, observer SetObserver) Set {
+	return newObservedSet(inner, observer)
+}
+
+// NewLRUSet creates and returns a reference to an empty set holding at
+// most max distinct elements. When it is full, adding a new element
+// evicts the least recently used one; both Add and Contains count as
+// uses, so Contains takes the write lock, while reading the set as the
+// argument of an operation such as Intersects does not. Iteration,
+// ToSlice, Pop and Peek go from the least to the most recently used
+// element. Operations on the resulting set are thread-safe.
+//
+// Clone and Snapshot return an LRU set with the same max, other
+// operations deriving a new set, such as Union or Map, return ordered
+// sets. A max below 1 is treated as 1.
+func NewLRUSet(max int) Set {
+	return newLRUSet(max, nil)
+}
+
+// NewLRUSetWithEvict is like NewLRUSet, but calls onEvict with every
+// element evicted to make room for a new one. onEvict is called after
+// the set is unlocked, so it may use the set.
+func NewLRUSetWithEvict(max int, onEvict func(elem interface{})) Set {
+	return newLRUSet(max, onEvict)
+}
+
 // NewThreadUnsafeSet creates and returns a reference to an empty set.
 // Operations on the resulting set are not thread-safe.
 func NewThreadUnsafeSet() Set {
@@ -219,22 +1197,37 @@ func NewThreadUnsafeSet() Set {
 	return &set
 }
 
+// NewThreadUnsafeSetWithSize creates and returns a reference to an
+// empty set with enough space preallocated to hold size elements. A
+// negative size is treated as 0. Operations on the resulting set are not
+// thread-safe.
+func NewThreadUnsafeSetWithSize(size int) Set {
+	set := newThreadUnsafeSetWithSize(size)
+	return &set
+}
+
+// NewThreadUnsafeSetWith creates and returns a new set with the given
+// elements. Operations on the resulting set are not thread-safe.
+func NewThreadUnsafeSetWith(objects ...interface{}) Set {
+	return NewThreadUnsafeSetFromSlice(objects)
+}
+
 // NewThreadUnsafeSetFromSlice creates and returns a reference to a
-// set from an existing slice.  Operations on the resulting set are
-// not thread-safe.
+// set from an existing slice, preallocated to hold len(objects)
+// elements.  Operations on the resulting set are not thread-safe.
 func NewThreadUnsafeSetFromSlice(objects []interface{}) Set {
-	set := NewThreadUnsafeSet()
+	set := NewThreadUnsafeSetWithSize(len(objects))
 	for _, item := range objects {
 		set.Add(item)
 	}
 	return set
 }
 
-// NewThreadUnsafeSetFromSlice creates and returns a reference to a
-// set from an existing slice.  Operations on the resulting set are
-// not thread-safe.
+// NewThreadUnsafeSetFromStrings creates and returns a reference to a
+// set from an existing string array.  Operations on the resulting set
+// are not thread-safe.
 func NewThreadUnsafeSetFromStrings(objects []string) Set {
-	set := NewThreadUnsafeSet()
+	set := NewThreadUnsafeSetWithSize(len(objects))
 	for _, item := range objects {
 		set.Add(item)
 	}
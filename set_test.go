@@ -25,7 +25,18 @@ SOFTWARE.
 
 package mapset
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
 
 func makeSet(ints []int) Set {
 	set := NewSet()
@@ -70,6 +81,38 @@ func Test_NewUnsafeSet(t *testing.T) {
 	}
 }
 
+func Test_NewSetWith(t *testing.T) {
+	assertEqual(NewSetWith(), NewSet(), t)
+	assertEqual(NewSetWith(1, 2, 2), makeSet([]int{1, 2}), t)
+}
+
+func Test_NewUnsafeSetWith(t *testing.T) {
+	assertEqual(NewThreadUnsafeSetWith(), NewThreadUnsafeSet(), t)
+	assertEqual(NewThreadUnsafeSetWith(1, 2, 2), makeUnsafeSet([]int{1, 2}), t)
+}
+
+func Test_NewSetWithSize(t *testing.T) {
+	a := NewThreadSafeSetWithSize(10)
+	if a.Cardinality() != 0 {
+		t.Error("NewThreadSafeSetWithSize should start out as an empty set")
+	}
+
+	a.AddAll(1, 2, 3)
+	assertEqual(a, NewSet(1, 2, 3), t)
+	assertEqual(NewThreadSafeSetWithSize(-1), NewSet(), t)
+}
+
+func Test_NewUnsafeSetWithSize(t *testing.T) {
+	a := NewThreadUnsafeSetWithSize(10)
+	if a.Cardinality() != 0 {
+		t.Error("NewThreadUnsafeSetWithSize should start out as an empty set")
+	}
+
+	a.AddAll(1, 2, 3)
+	assertEqual(a, makeUnsafeSet([]int{1, 2, 3}), t)
+	assertEqual(NewThreadUnsafeSetWithSize(-1), NewThreadUnsafeSet(), t)
+}
+
 func Test_AddSet(t *testing.T) {
 	a := makeSet([]int{1, 2, 3})
 
@@ -102,6 +145,49 @@ func Test_SafeStrings(t *testing.T) {
 	}
 }
 
+func Test_StringsWithConversion(t *testing.T) {
+	for _, set := range []Set{
+		NewSet(1, true, "a"),
+		NewThreadUnsafeSetWith(1, true, "a"),
+	} {
+		strs := NewSetFromStrings(set.StringsWithConversion())
+		if !strs.Equal(NewSet("1", "true", "a")) {
+			t.Errorf("StringsWithConversion should return 1, true and a, got %v", strs)
+		}
+
+		if len(set.Strings()) != 1 {
+			t.Errorf("Strings should still only return a, got %v", set.Strings())
+		}
+	}
+}
+
+func Test_StringSorted(t *testing.T) {
+	for name, newSet := range setConstructors {
+		set := newSet(3, "b", 1, "a", 2)
+		if s := set.StringSorted(); s != "Set{1, 2, 3, a, b}" {
+			t.Errorf("%s: unexpected sorted string %q", name, s)
+		}
+		if s := newSet().StringSorted(); s != "Set{}" {
+			t.Errorf("%s: unexpected sorted string for the empty set %q", name, s)
+		}
+	}
+}
+
+func Test_IntsFloat64s(t *testing.T) {
+	for _, set := range []Set{
+		NewSet(1, 2, 1.5, "a", json.Number("3")),
+		NewThreadUnsafeSetWith(1, 2, 1.5, "a", json.Number("3")),
+	} {
+		if ints := set.Ints(); len(ints) != 2 {
+			t.Errorf("Ints should only return 1 and 2, got %v", ints)
+		}
+
+		if floats := set.Float64s(); len(floats) != 1 || floats[0] != 1.5 {
+			t.Errorf("Float64s should only return 1.5, got %v", floats)
+		}
+	}
+}
+
 func Test_AddUnsafeSet(t *testing.T) {
 	a := makeUnsafeSet([]int{1, 2, 3})
 
@@ -176,6 +262,54 @@ func Test_RemoveUnsafeSet(t *testing.T) {
 	}
 }
 
+func Test_AddAllSet(t *testing.T) {
+	a := NewSet(1)
+
+	if added := a.AddAll(1, 2, 3, 3); added != 2 {
+		t.Errorf("AddAll should report 2 new items added, got %d", added)
+	}
+
+	if a.Cardinality() != 3 || !a.Contains(1, 2, 3) {
+		t.Error("AddAll set should contain 1, 2 and 3")
+	}
+}
+
+func Test_AddAllUnsafeSet(t *testing.T) {
+	a := makeUnsafeSet([]int{1})
+
+	if added := a.AddAll(1, 2, 3, 3); added != 2 {
+		t.Errorf("AddAll should report 2 new items added, got %d", added)
+	}
+
+	if a.Cardinality() != 3 || !a.Contains(1, 2, 3) {
+		t.Error("AddAll set should contain 1, 2 and 3")
+	}
+}
+
+func Test_RemoveAllSet(t *testing.T) {
+	a := makeSet([]int{6, 3, 1})
+
+	if removed := a.RemoveAll(3, 6, 9); removed != 2 {
+		t.Errorf("RemoveAll should report 2 items removed, got %d", removed)
+	}
+
+	if a.Cardinality() != 1 || !a.Contains(1) {
+		t.Error("RemoveAll set should only contain 1")
+	}
+}
+
+func Test_RemoveAllUnsafeSet(t *testing.T) {
+	a := makeUnsafeSet([]int{6, 3, 1})
+
+	if removed := a.RemoveAll(3, 6, 9); removed != 2 {
+		t.Errorf("RemoveAll should report 2 items removed, got %d", removed)
+	}
+
+	if a.Cardinality() != 1 || !a.Contains(1) {
+		t.Error("RemoveAll set should only contain 1")
+	}
+}
+
 func Test_ContainsSet(t *testing.T) {
 	a := NewSet()
 
@@ -248,6 +382,90 @@ func Test_ContainsMultipleUnsafeSet(t *testing.T) {
 	}
 }
 
+func Test_AddIfNotContains(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2)
+
+		if a.AddIfNotContains(3, 1) || a.Contains(3) {
+			t.Errorf("%s: AddIfNotContains should not add 3 while 1 is in the set", name)
+		}
+		if !a.AddIfNotContains(3, 4) || !a.Contains(3) {
+			t.Errorf("%s: AddIfNotContains should add 3 while 4 is not in the set", name)
+		}
+		if a.AddIfNotContains(3, 4) {
+			t.Errorf("%s: AddIfNotContains should report false for an element already in the set", name)
+		}
+	}
+}
+
+func Test_Has(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2, nil)
+
+		if !a.Has(1) || !a.Has(nil) {
+			t.Errorf("%s: Has should find the elements of the set", name)
+		}
+		if a.Has(3) || a.Has("1") {
+			t.Errorf("%s: Has should not find elements missing from the set", name)
+		}
+		a.Remove(1)
+		if a.Has(1) {
+			t.Errorf("%s: Has should not find a removed element", name)
+		}
+	}
+}
+
+func Test_ContainsAll(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(8, 6, 7, 5, 3, 0, 9)
+
+		if !a.ContainsAll(8, 6, 7, 5, 3, 0, 9) || !a.ContainsAll() {
+			t.Errorf("%s: ContainsAll should contain Jenny's phone number", name)
+		}
+		if a.ContainsAll(8, 6, 11) {
+			t.Errorf("%s: ContainsAll should not have all of these numbers", name)
+		}
+	}
+}
+
+func Test_ContainsAny(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(8, 6, 7, 5, 3, 0, 9)
+
+		if !a.ContainsAny(11, 12, 9) {
+			t.Errorf("%s: ContainsAny should find 9", name)
+		}
+		if a.ContainsAny(11, 12, 13) {
+			t.Errorf("%s: ContainsAny should not find any of these numbers", name)
+		}
+		if a.ContainsAny() {
+			t.Errorf("%s: ContainsAny with no items should be false", name)
+		}
+	}
+}
+
+func Test_ContainsBy(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	byID := func(i interface{}) interface{} { return i.(user).ID }
+
+	for name, newSet := range setConstructors {
+		a := newSet(user{1, "ann"}, user{2, "bob"})
+
+		if !a.ContainsBy(user{ID: 2}, byID) {
+			t.Errorf("%s: ContainsBy should find the user with ID 2", name)
+		}
+		if a.ContainsBy(user{3, "ann"}, byID) {
+			t.Errorf("%s: ContainsBy should not find a user with ID 3", name)
+		}
+		if a.Contains(user{ID: 2}) {
+			t.Errorf("%s: Contains should still compare whole elements", name)
+		}
+	}
+}
+
 func Test_ClearSet(t *testing.T) {
 	a := makeSet([]int{2, 5, 9, 10})
 
@@ -268,6 +486,46 @@ func Test_ClearUnsafeSet(t *testing.T) {
 	}
 }
 
+func Test_ClearRetainingCapacity(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(2, 5, 9, 10)
+
+		a.ClearRetainingCapacity()
+		if a.Cardinality() != 0 {
+			t.Errorf("%s: ClearRetainingCapacity should leave an empty set, got %v", name, a)
+		}
+		if a.Contains(2) {
+			t.Errorf("%s: a cleared set should not contain its old elements", name)
+		}
+
+		a.Add(3)
+		if !a.Equal(NewSet(3)) {
+			t.Errorf("%s: a cleared set should be reusable, got %v", name, a)
+		}
+	}
+}
+
+func Test_ReplaceAll(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2, 3)
+
+		a.ReplaceAll(3, 4, 4, 5)
+		if !a.Equal(NewSet(3, 4, 5)) {
+			t.Errorf("%s: ReplaceAll should leave only the given elements, got %v", name, a)
+		}
+
+		a.ReplaceAll(a.ToSlice()...)
+		if !a.Equal(NewSet(3, 4, 5)) {
+			t.Errorf("%s: replacing the elements with themselves should keep them, got %v", name, a)
+		}
+
+		a.ReplaceAll()
+		if a.Cardinality() != 0 {
+			t.Errorf("%s: ReplaceAll with no elements should clear the set, got %v", name, a)
+		}
+	}
+}
+
 func Test_CardinalitySet(t *testing.T) {
 	a := NewSet()
 
@@ -611,6 +869,26 @@ func Test_UnsafeSetUnion(t *testing.T) {
 	}
 }
 
+func Test_SetUnionAll(t *testing.T) {
+	a := makeSet([]int{1, 2})
+	b := makeSet([]int{2, 3})
+	c := makeSet([]int{4})
+
+	assertEqual(a.UnionAll(b, c, a), makeSet([]int{1, 2, 3, 4}), t)
+	assertEqual(a.UnionAll(), a, t)
+	assertEqual(a, makeSet([]int{1, 2}), t)
+}
+
+func Test_UnsafeSetUnionAll(t *testing.T) {
+	a := makeUnsafeSet([]int{1, 2})
+	b := makeUnsafeSet([]int{2, 3})
+	c := makeUnsafeSet([]int{4})
+
+	assertEqual(a.UnionAll(b, c, a), makeUnsafeSet([]int{1, 2, 3, 4}), t)
+	assertEqual(a.UnionAll(), a, t)
+	assertEqual(a, makeUnsafeSet([]int{1, 2}), t)
+}
+
 func Test_SetIntersect(t *testing.T) {
 	a := NewSet()
 	a.Add(1)
@@ -665,141 +943,708 @@ func Test_UnsafeSetIntersect(t *testing.T) {
 	}
 }
 
-func Test_SetDifference(t *testing.T) {
-	a := NewSet()
-	a.Add(1)
-	a.Add(2)
-	a.Add(3)
+func Test_SetRetainAll(t *testing.T) {
+	a := makeSet([]int{1, 2, 3, 4})
+	b := makeSet([]int{2, 4, 6})
 
-	b := NewSet()
-	b.Add(1)
-	b.Add(3)
-	b.Add(4)
-	b.Add(5)
-	b.Add(6)
-	b.Add(99)
+	if removed := a.RetainAll(b); removed != 2 {
+		t.Errorf("RetainAll should report 2 items removed, got %d", removed)
+	}
 
-	c := a.Difference(b)
+	assertEqual(a, makeSet([]int{2, 4}), t)
+	assertEqual(b, makeSet([]int{2, 4, 6}), t)
 
-	if !(c.Cardinality() == 1 && c.Contains(2)) {
-		t.Error("the difference of set a to b is the set of 1 item: 2")
+	if removed := a.RetainAll(a); removed != 0 {
+		t.Errorf("RetainAll with itself should remove nothing, got %d", removed)
 	}
 }
 
-func Test_UnsafeSetDifference(t *testing.T) {
-	a := NewThreadUnsafeSet()
-	a.Add(1)
-	a.Add(2)
-	a.Add(3)
+func Test_UnsafeSetRetainAll(t *testing.T) {
+	a := makeUnsafeSet([]int{1, 2, 3, 4})
+	b := makeUnsafeSet([]int{2, 4, 6})
 
-	b := NewThreadUnsafeSet()
-	b.Add(1)
-	b.Add(3)
-	b.Add(4)
-	b.Add(5)
-	b.Add(6)
-	b.Add(99)
+	if removed := a.RetainAll(b); removed != 2 {
+		t.Errorf("RetainAll should report 2 items removed, got %d", removed)
+	}
 
-	c := a.Difference(b)
+	assertEqual(a, makeUnsafeSet([]int{2, 4}), t)
+	assertEqual(b, makeUnsafeSet([]int{2, 4, 6}), t)
 
-	if !(c.Cardinality() == 1 && c.Contains(2)) {
-		t.Error("the difference of set a to b is the set of 1 item: 2")
+	if removed := a.RetainAll(a); removed != 0 {
+		t.Errorf("RetainAll with itself should remove nothing, got %d", removed)
 	}
 }
 
-func Test_SetSymmetricDifference(t *testing.T) {
-	a := NewSet()
-	a.Add(1)
-	a.Add(2)
-	a.Add(3)
-	a.Add(45)
+func Test_IntersectUnionCardinality(t *testing.T) {
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			a, b := newA(1, 2, 3, 4), newB(3, 4, 5)
+			if n := a.IntersectCardinality(b); n != 2 {
+				t.Errorf("%s IntersectCardinality %s should be 2, got %d", an, bn, n)
+			}
+			if n := b.IntersectCardinality(a); n != 2 {
+				t.Errorf("%s IntersectCardinality %s should be 2, got %d", bn, an, n)
+			}
+			if n := a.UnionCardinality(b); n != 5 {
+				t.Errorf("%s UnionCardinality %s should be 5, got %d", an, bn, n)
+			}
+			if n := a.UnionCardinality(newB()); n != 4 {
+				t.Errorf("%s UnionCardinality with an empty %s should be 4, got %d", an, bn, n)
+			}
+		}
 
-	b := NewSet()
-	b.Add(1)
-	b.Add(3)
-	b.Add(4)
-	b.Add(5)
-	b.Add(6)
-	b.Add(99)
+		a := newA(1, 2)
+		if a.IntersectCardinality(a) != 2 || a.UnionCardinality(a) != 2 {
+			t.Errorf("%s: the intersection and union of a set with itself should have its cardinality", an)
+		}
+	}
+}
 
-	c := a.SymmetricDifference(b)
+func Test_SetAddSet(t *testing.T) {
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			a, b := newA(1, 2, 3), newB(3, 4, 5)
+			if added := a.AddSet(b); added != 2 {
+				t.Errorf("%s AddSet %s should report 2 items added, got %d", an, bn, added)
+			}
+			if !a.Equal(NewSet(1, 2, 3, 4, 5)) {
+				t.Errorf("%s AddSet %s should hold the union, got %v", an, bn, a)
+			}
+			if !b.Equal(NewSet(3, 4, 5)) {
+				t.Errorf("%s AddSet %s should not modify its argument, got %v", an, bn, b)
+			}
+		}
 
-	if !(c.Cardinality() == 6 && c.Contains(2) && c.Contains(45) && c.Contains(4) && c.Contains(5) && c.Contains(6) && c.Contains(99)) {
-		t.Error("the symmetric difference of set a to b is the set of 6 items: 2, 45, 4, 5, 6, 99")
+		a := newA(1, 2)
+		if added := a.AddSet(a); added != 0 || a.Cardinality() != 2 {
+			t.Errorf("%s AddSet with itself should add nothing, got %d", an, added)
+		}
 	}
 }
 
-func Test_UnsafeSetSymmetricDifference(t *testing.T) {
-	a := NewThreadUnsafeSet()
-	a.Add(1)
-	a.Add(2)
-	a.Add(3)
-	a.Add(45)
+func Test_SubtractSet(t *testing.T) {
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			a, b := newA(1, 2, 3, 4), newB(3, 4, 5)
+			if removed := a.SubtractSet(b); removed != 2 {
+				t.Errorf("%s SubtractSet %s should report 2 items removed, got %d", an, bn, removed)
+			}
+			if !a.Equal(NewSet(1, 2)) {
+				t.Errorf("%s SubtractSet %s should hold the difference, got %v", an, bn, a)
+			}
+			if !b.Equal(NewSet(3, 4, 5)) {
+				t.Errorf("%s SubtractSet %s should not modify its argument, got %v", an, bn, b)
+			}
+		}
 
-	b := NewThreadUnsafeSet()
-	b.Add(1)
-	b.Add(3)
-	b.Add(4)
-	b.Add(5)
-	b.Add(6)
-	b.Add(99)
+		a := newA(1, 2)
+		if removed := a.SubtractSet(a); removed != 2 || a.Cardinality() != 0 {
+			t.Errorf("%s SubtractSet with itself should remove everything, got %d removed and %v", an, removed, a)
+		}
+	}
+}
 
-	c := a.SymmetricDifference(b)
+func Test_Diff(t *testing.T) {
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			old, current := newA(1, 2, 3), newB(2, 3, 4, 5)
+			added, removed := old.Diff(current)
+			if !added.Equal(NewSet(4, 5)) {
+				t.Errorf("%s Diff %s should add 4 and 5, got %v", an, bn, added)
+			}
+			if !removed.Equal(NewSet(1)) {
+				t.Errorf("%s Diff %s should remove 1, got %v", an, bn, removed)
+			}
+			if reflect.TypeOf(added) != reflect.TypeOf(old) || reflect.TypeOf(removed) != reflect.TypeOf(old) {
+				t.Errorf("%s Diff %s returned %T and %T, want %T", an, bn, added, removed, old)
+			}
+		}
 
-	if !(c.Cardinality() == 6 && c.Contains(2) && c.Contains(45) && c.Contains(4) && c.Contains(5) && c.Contains(6) && c.Contains(99)) {
-		t.Error("the symmetric difference of set a to b is the set of 6 items: 2, 45, 4, 5, 6, 99")
+		a := newA(1, 2)
+		if added, removed := a.Diff(a); added.Cardinality() != 0 || removed.Cardinality() != 0 {
+			t.Errorf("%s: Diff with itself should be empty, got %v and %v", an, added, removed)
+		}
 	}
 }
 
-func Test_SetEqual(t *testing.T) {
-	a := NewSet()
-	b := NewSet()
+func Test_Merge(t *testing.T) {
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			a, b := newA(1, 2, 3), newB(2, 3, 4, 5)
+			union, common := a.Merge(b)
+			if !union.Equal(NewSet(1, 2, 3, 4, 5)) {
+				t.Errorf("%s Merge %s should return the union, got %v", an, bn, union)
+			}
+			if !common.Equal(NewSet(2, 3)) {
+				t.Errorf("%s Merge %s should return the common elements, got %v", an, bn, common)
+			}
+			if reflect.TypeOf(union) != reflect.TypeOf(a) || reflect.TypeOf(common) != reflect.TypeOf(a) {
+				t.Errorf("%s Merge %s returned %T and %T, want %T", an, bn, union, common, a)
+			}
+			if !a.Equal(NewSet(1, 2, 3)) || !b.Equal(NewSet(2, 3, 4, 5)) {
+				t.Errorf("%s Merge %s should not modify the sets", an, bn)
+			}
+		}
 
-	if !a.Equal(b) {
-		t.Error("Both a and b are empty sets, and should be equal")
+		a := newA(1, 2)
+		if union, common := a.Merge(a); !union.Equal(a) || !common.Equal(a) {
+			t.Errorf("%s: Merge with itself should return the set twice, got %v and %v", an, union, common)
+		}
 	}
+}
 
-	a.Add(10)
+// assertSelfArgument fails if the operations taking another set do not
+// work with the set itself as the argument, rather than deadlocking.
+func assertSelfArgument(t *testing.T, name string, newSet func() Set) {
+	t.Helper()
 
-	if a.Equal(b) {
-		t.Error("a should not be equal to b because b is empty and a has item 1 in it")
-	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
 
-	b.Add(10)
+		a := newSet()
+		a.AddAll(1, 2)
+		if !a.Equal(a) || !a.IsSubset(a) || !a.Union(a).Equal(NewSet(1, 2)) {
+			t.Errorf("%s: a set should equal itself, got %v", name, a)
+		}
+		if a.RetainAll(a) != 0 || a.Cardinality() != 2 {
+			t.Errorf("%s: retaining a set in itself should keep every element, got %v", name, a)
+		}
+		if a.SubtractSet(a) != 2 || a.Cardinality() != 0 {
+			t.Errorf("%s: subtracting a set from itself should empty it, got %v", name, a)
+		}
+	}()
 
-	if !a.Equal(b) {
-		t.Error("a is now equal again to b because both have the item 10 in them")
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("%s: an operation with the set itself as the argument deadlocked", name)
 	}
+}
 
-	b.Add(8)
-	b.Add(3)
-	b.Add(47)
+func Test_NilSetArguments(t *testing.T) {
+	var none Set
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2)
+		empty := NewSet()
+
+		sets := map[string]Set{
+			"Union":               a.Union(none),
+			"UnionAll":            a.UnionAll(none, NewSet(3)),
+			"Intersect":           a.Intersect(none),
+			"IntersectAll":        a.IntersectAll(NewSet(1), none),
+			"Difference":          a.Difference(none),
+			"DifferenceAll":       a.DifferenceAll(none, NewSet(1)),
+			"SymmetricDifference": a.SymmetricDifference(none),
+			"CartesianProduct":    a.CartesianProduct(none),
+		}
+		want := map[string]Set{
+			"Union":               NewSet(1, 2),
+			"UnionAll":            NewSet(1, 2, 3),
+			"Intersect":           empty,
+			"IntersectAll":        empty,
+			"Difference":          NewSet(1, 2),
+			"DifferenceAll":       NewSet(2),
+			"SymmetricDifference": NewSet(1, 2),
+			"CartesianProduct":    empty,
+		}
+		for op, got := range sets {
+			if !got.Equal(want[op]) {
+				t.Errorf("%s: %s with a nil set should treat it as empty, got %v", name, op, got)
+			}
+		}
 
-	if a.Equal(b) {
-		t.Error("b has 3 more elements in it so therefore should not be equal to a")
+		if added, removed := a.Diff(none); added.Cardinality() != 0 || !removed.Equal(a) {
+			t.Errorf("%s: Diff with a nil set should remove every element, got %v and %v", name, added, removed)
+		}
+		if union, common := a.Merge(none); !union.Equal(a) || common.Cardinality() != 0 {
+			t.Errorf("%s: Merge with a nil set should treat it as empty, got %v and %v", name, union, common)
+		}
+		if _, ok := <-a.CartesianProductIterator(none).C; ok {
+			t.Errorf("%s: CartesianProductIterator with a nil set should yield no pairs", name)
+		}
+
+		if a.Equal(none) || !newSet().Equal(none) || a.EqualFunc(none, func(x, y interface{}) bool { return x == y }) ||
+			a.EqualUsing(none, func(x, y interface{}) bool { return x == y }) {
+			t.Errorf("%s: Equal should treat a nil set as empty", name)
+		}
+		if a.IsSubset(none) || a.IsProperSubset(none) || !a.IsSuperset(none) || !a.IsProperSuperset(none) {
+			t.Errorf("%s: the subset relations should treat a nil set as empty", name)
+		}
+		if a.Intersects(none) || !a.IsDisjoint(none) {
+			t.Errorf("%s: a set should not intersect a nil set", name)
+		}
+		if a.IntersectCardinality(none) != 0 || a.UnionCardinality(none) != 2 {
+			t.Errorf("%s: the cardinalities should treat a nil set as empty", name)
+		}
+		if a.JaccardSimilarity(none) != 0 || a.OverlapCoefficient(none) != 0 {
+			t.Errorf("%s: the similarities with a nil set should be 0", name)
+		}
+
+		if a.AddSet(none) != 0 || a.SubtractSet(none) != 0 || !a.Equal(NewSet(1, 2)) {
+			t.Errorf("%s: AddSet and SubtractSet with a nil set should not change the set, got %v", name, a)
+		}
+		if a.RetainAll(none) != 2 || a.Cardinality() != 0 {
+			t.Errorf("%s: RetainAll with a nil set should remove every element, got %v", name, a)
+		}
 	}
 
-	a.Add(8)
-	a.Add(3)
-	a.Add(47)
+	wrappers := map[string]Set{
+		"bounded":  NewBoundedSet(2),
+		"keyed":    NewSetByKey(func(i interface{}) interface{} { return i }),
+		"ttl":      NewTTLSet(0),
+		"lru":      NewLRUSet(2),
+		"hashed":   NewHashedSet(),
+		"observed": NewObservedSet(NewSet(), &countingObserver{}),
+	}
+	for name, a := range wrappers {
+		a.Add(1)
+		if a.AddSet(none) != 0 || a.SubtractSet(none) != 0 || a.RetainAll(none) != 1 {
+			t.Errorf("%s: AddSet, SubtractSet and RetainAll should treat a nil set as empty", name)
+		}
+	}
 
-	if !a.Equal(b) {
-		t.Error("a and b should be equal with the same number of elements")
+	if Union(none, NewSet(1)).Cardinality() != 1 || Intersect(none, NewSet(1)).Cardinality() != 0 ||
+		Difference(none, NewSet(1)).Cardinality() != 0 || AtLeastK(1, none, NewSet(1)).Cardinality() != 1 {
+		t.Error("the package-level operations should treat a nil set as empty")
 	}
 }
 
-func Test_UnsafeSetEqual(t *testing.T) {
-	a := NewThreadUnsafeSet()
-	b := NewThreadUnsafeSet()
+func Test_PackageUnionIntersectDifference(t *testing.T) {
+	for name, newSet := range setConstructors {
+		sets := []Set{newSet(1, 2, 3), NewSet(2, 3, 4), NewOrderedSet(3, 4, 5)}
 
-	if !a.Equal(b) {
-		t.Error("Both a and b are empty sets, and should be equal")
+		union := Union(sets...)
+		if !union.Equal(NewSet(1, 2, 3, 4, 5)) {
+			t.Errorf("%s: Union should hold the elements of every set, got %v", name, union)
+		}
+		intersection := Intersect(sets...)
+		if !intersection.Equal(NewSet(3)) {
+			t.Errorf("%s: Intersect should hold the common elements, got %v", name, intersection)
+		}
+		difference := Difference(sets[0], sets[1:]...)
+		if !difference.Equal(NewSet(1)) {
+			t.Errorf("%s: Difference should drop the elements of the others, got %v", name, difference)
+		}
+
+		want := reflect.TypeOf(sets[0])
+		for op, got := range map[string]Set{"Union": union, "Intersect": intersection, "Difference": difference} {
+			if reflect.TypeOf(got) != want {
+				t.Errorf("%s: %s should use the type of the first set, got %T", name, op, got)
+			}
+		}
+		if got := Union(sets[0]); !got.Equal(sets[0]) || got == sets[0] {
+			t.Errorf("%s: Union of one set should be a copy of it, got %v", name, got)
+		}
 	}
 
-	a.Add(10)
+	if Union().Cardinality() != 0 || Intersect().Cardinality() != 0 {
+		t.Error("Union and Intersect without sets should return an empty set")
+	}
+}
 
-	if a.Equal(b) {
-		t.Error("a should not be equal to b because b is empty and a has item 1 in it")
+func Test_Move(t *testing.T) {
+	for fromName, newFrom := range setConstructors {
+		for toName, newTo := range setConstructors {
+			name := fromName + " to " + toName
+			from, to := newFrom(1, 2), newTo(2, 3)
+
+			if !Move(from, to, 1) || from.Has(1) || !to.Has(1) {
+				t.Errorf("%s: Move should move 1, got %v and %v", name, from, to)
+			}
+			if !Move(from, to, 2) || from.Has(2) || !to.Equal(NewSet(1, 2, 3)) {
+				t.Errorf("%s: Move should remove an element already in to from from, got %v and %v", name, from, to)
+			}
+			if Move(from, to, 4) || to.Has(4) {
+				t.Errorf("%s: Move should not add an element missing from from", name)
+			}
+		}
+	}
+
+	a := NewSet(1)
+	if !Move(a, a, 1) || !a.Has(1) {
+		t.Errorf("moving an element within the same set should keep it, got %v", a)
+	}
+
+	from, to := NewBoundedSet(2), NewLRUSet(2)
+	from.Add(1)
+	if !Move(from, to, 1) || from.Has(1) || !to.Has(1) {
+		t.Errorf("Move should move elements between other implementations, got %v and %v", from, to)
+	}
+}
+
+func Test_Swap(t *testing.T) {
+	for aName, newA := range setConstructors {
+		for bName, newB := range setConstructors {
+			name := aName + " and " + bName
+			a, b := newA(1, 2), newB(3)
+
+			Swap(a, b)
+			if !a.Equal(NewSet(3)) || !b.Equal(NewSet(1, 2)) {
+				t.Errorf("%s: Swap should exchange the elements, got %v and %v", name, a, b)
+			}
+			a.Add(4)
+			if b.Has(4) {
+				t.Errorf("%s: swapped sets should not share their elements", name)
+			}
+		}
+	}
+
+	a, b := NewOrderedSet(1, 2, 3), NewOrderedSet(4, 5)
+	Swap(a, b)
+	assertOrder(a, []interface{}{4, 5}, t)
+	assertOrder(b, []interface{}{1, 2, 3}, t)
+
+	c := NewSet(1)
+	Swap(c, c)
+	if !c.Equal(NewSet(1)) {
+		t.Errorf("swapping a set with itself should keep its elements, got %v", c)
+	}
+
+	d, e := NewShardedSet(2), NewShardedSet(3)
+	d.AddAll(1, 2, 3)
+	Swap(d, e)
+	if d.Cardinality() != 0 || !e.Equal(NewSet(1, 2, 3)) {
+		t.Errorf("Swap should exchange sharded sets with different shard counts, got %v and %v", d, e)
+	}
+}
+
+func Test_AtLeastK(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewThreadUnsafeSetFromSlice([]interface{}{2, 3, 4, 5})
+	c := NewOrderedSet(3, 4, 5, 6)
+
+	if got := AtLeastK(1, a, b, c); !got.Equal(a.Union(b).Union(c)) {
+		t.Errorf("AtLeastK(1) should be the union, got %v", got)
+	}
+	if got := AtLeastK(2, a, b, c); !got.Equal(NewSet(2, 3, 4, 5)) {
+		t.Errorf("AtLeastK(2) should keep the elements of two sets or more, got %v", got)
+	}
+	if got := AtLeastK(3, a, b, c); !got.Equal(a.IntersectAll(b, c)) {
+		t.Errorf("AtLeastK(3) should be the intersection, got %v", got)
+	}
+	if got := AtLeastK(4, a, b, c); got.Cardinality() != 0 {
+		t.Errorf("AtLeastK above the number of sets should be empty, got %v", got)
+	}
+	if got := AtLeastK(0, a); !got.Equal(a) || got == a {
+		t.Errorf("AtLeastK(0) should be a new union, got %v", got)
+	}
+	if got := AtLeastK(2, a, a); !got.Equal(a) {
+		t.Errorf("a set given twice should count twice, got %v", got)
+	}
+	if got := AtLeastK(1); got.Cardinality() != 0 {
+		t.Errorf("AtLeastK without sets should be empty, got %v", got)
+	}
+}
+
+func Test_SetIntersects(t *testing.T) {
+	a := makeSet([]int{1, 2, 3})
+	b := makeSet([]int{3, 4, 5, 6})
+	c := makeSet([]int{7, 8})
+
+	if !a.Intersects(b) || !b.Intersects(a) {
+		t.Error("a and b share 3 and should intersect")
+	}
+	if a.IsDisjoint(b) {
+		t.Error("a and b share 3 and should not be disjoint")
+	}
+	if a.Intersects(c) || !a.IsDisjoint(c) {
+		t.Error("a and c share nothing and should be disjoint")
+	}
+
+	empty := makeSet([]int{})
+	if empty.Intersects(empty) || !empty.IsDisjoint(a) {
+		t.Error("the empty set should be disjoint from every set, itself included")
+	}
+}
+
+func Test_UnsafeSetIntersects(t *testing.T) {
+	a := makeUnsafeSet([]int{1, 2, 3})
+	b := makeUnsafeSet([]int{3, 4, 5, 6})
+	c := makeUnsafeSet([]int{7, 8})
+
+	if !a.Intersects(b) || !b.Intersects(a) {
+		t.Error("a and b share 3 and should intersect")
+	}
+	if a.IsDisjoint(b) {
+		t.Error("a and b share 3 and should not be disjoint")
+	}
+	if a.Intersects(c) || !a.IsDisjoint(c) {
+		t.Error("a and c share nothing and should be disjoint")
+	}
+
+	empty := makeUnsafeSet([]int{})
+	if empty.Intersects(empty) || !empty.IsDisjoint(a) {
+		t.Error("the empty set should be disjoint from every set, itself included")
+	}
+}
+
+func Test_EqualFunc(t *testing.T) {
+	sameNumber := func(a, b interface{}) bool {
+		return fmt.Sprint(a) == fmt.Sprint(b)
+	}
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			a := newA(1, 2, 5)
+			if !a.EqualFunc(newB(json.Number("5"), json.Number("1"), json.Number("2")), sameNumber) {
+				t.Errorf("%s EqualFunc %s should consider 5 and json.Number(\"5\") equal", an, bn)
+			}
+			if a.Equal(newB(json.Number("5"), json.Number("1"), json.Number("2"))) {
+				t.Errorf("%s Equal %s should not consider 5 and json.Number(\"5\") equal", an, bn)
+			}
+			if a.EqualFunc(newB(json.Number("1"), json.Number("2")), sameNumber) {
+				t.Errorf("%s EqualFunc %s should fail on sets of different cardinality", an, bn)
+			}
+			if a.EqualFunc(newB(json.Number("1"), json.Number("2"), json.Number("6")), sameNumber) {
+				t.Errorf("%s EqualFunc %s should fail when an element has no match", an, bn)
+			}
+		}
+	}
+}
+
+func Test_EqualUsing(t *testing.T) {
+	type point struct{ X, Y int }
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			a := newA(&point{1, 2}, &point{1, 2})
+			if !a.EqualUsing(newB(&point{1, 2}, &point{1, 2}), reflect.DeepEqual) {
+				t.Errorf("%s EqualUsing %s should compare pointers to structs with reflect.DeepEqual", an, bn)
+			}
+			if a.Equal(newB(&point{1, 2}, &point{1, 2})) {
+				t.Errorf("%s Equal %s should compare pointers with ==", an, bn)
+			}
+			if a.EqualUsing(newB(&point{1, 2}, &point{3, 4}), reflect.DeepEqual) {
+				t.Errorf("%s EqualUsing %s should match every element of other at most once", an, bn)
+			}
+			if !a.EqualFunc(newB(&point{1, 2}, &point{3, 4}), reflect.DeepEqual) {
+				t.Errorf("%s EqualFunc %s should let several elements match the same element of other", an, bn)
+			}
+			if a.EqualUsing(newB(&point{1, 2}), reflect.DeepEqual) {
+				t.Errorf("%s EqualUsing %s should fail on sets of different cardinality", an, bn)
+			}
+		}
+	}
+}
+
+func Test_JaccardSimilarity(t *testing.T) {
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			a := newA(1, 2, 3, 4)
+			if j := a.JaccardSimilarity(newB(3, 4, 5, 6)); j != 2.0/6 {
+				t.Errorf("%s JaccardSimilarity %s should be 1/3, got %v", an, bn, j)
+			}
+			if j := a.JaccardSimilarity(newB(4, 3, 2, 1)); j != 1 {
+				t.Errorf("%s JaccardSimilarity %s of equal sets should be 1, got %v", an, bn, j)
+			}
+			if j := a.JaccardSimilarity(newB()); j != 0 {
+				t.Errorf("%s JaccardSimilarity %s with the empty set should be 0, got %v", an, bn, j)
+			}
+			if j := newA().JaccardSimilarity(newB()); j != 1 {
+				t.Errorf("%s JaccardSimilarity %s of two empty sets should be 1, got %v", an, bn, j)
+			}
+		}
+	}
+}
+
+func Test_OverlapCoefficient(t *testing.T) {
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			a := newA(1, 2, 3, 4, 5, 6)
+			if c := a.OverlapCoefficient(newB(5, 6, 7, 8)); c != 0.5 {
+				t.Errorf("%s OverlapCoefficient %s should be 1/2, got %v", an, bn, c)
+			}
+			if c := a.OverlapCoefficient(newB(2, 3)); c != 1 {
+				t.Errorf("%s OverlapCoefficient %s of a subset should be 1, got %v", an, bn, c)
+			}
+			if c := a.OverlapCoefficient(newB()); c != 0 {
+				t.Errorf("%s OverlapCoefficient %s with the empty set should be 0, got %v", an, bn, c)
+			}
+			if c := newA().OverlapCoefficient(newB()); c != 1 {
+				t.Errorf("%s OverlapCoefficient %s of two empty sets should be 1, got %v", an, bn, c)
+			}
+		}
+	}
+}
+
+func Test_SetIntersectAll(t *testing.T) {
+	a := makeSet([]int{1, 2, 3, 4})
+	b := makeSet([]int{2, 3, 4})
+	c := makeSet([]int{3, 4, 5})
+
+	assertEqual(a.IntersectAll(b, c), makeSet([]int{3, 4}), t)
+	assertEqual(a.IntersectAll(b, NewSet(), c), NewSet(), t)
+	assertEqual(a.IntersectAll(), a, t)
+	assertEqual(a, makeSet([]int{1, 2, 3, 4}), t)
+}
+
+func Test_UnsafeSetIntersectAll(t *testing.T) {
+	a := makeUnsafeSet([]int{1, 2, 3, 4})
+	b := makeUnsafeSet([]int{2, 3, 4})
+	c := makeUnsafeSet([]int{3, 4, 5})
+
+	assertEqual(a.IntersectAll(b, c), makeUnsafeSet([]int{3, 4}), t)
+	assertEqual(a.IntersectAll(b, NewThreadUnsafeSet(), c), NewThreadUnsafeSet(), t)
+	assertEqual(a.IntersectAll(), a, t)
+	assertEqual(a, makeUnsafeSet([]int{1, 2, 3, 4}), t)
+}
+
+func Test_SetDifference(t *testing.T) {
+	a := NewSet()
+	a.Add(1)
+	a.Add(2)
+	a.Add(3)
+
+	b := NewSet()
+	b.Add(1)
+	b.Add(3)
+	b.Add(4)
+	b.Add(5)
+	b.Add(6)
+	b.Add(99)
+
+	c := a.Difference(b)
+
+	if !(c.Cardinality() == 1 && c.Contains(2)) {
+		t.Error("the difference of set a to b is the set of 1 item: 2")
+	}
+}
+
+func Test_UnsafeSetDifference(t *testing.T) {
+	a := NewThreadUnsafeSet()
+	a.Add(1)
+	a.Add(2)
+	a.Add(3)
+
+	b := NewThreadUnsafeSet()
+	b.Add(1)
+	b.Add(3)
+	b.Add(4)
+	b.Add(5)
+	b.Add(6)
+	b.Add(99)
+
+	c := a.Difference(b)
+
+	if !(c.Cardinality() == 1 && c.Contains(2)) {
+		t.Error("the difference of set a to b is the set of 1 item: 2")
+	}
+}
+
+func Test_SetDifferenceAll(t *testing.T) {
+	a := makeSet([]int{1, 2, 3, 4, 5})
+	b := makeSet([]int{1, 9})
+	c := makeSet([]int{3, 4})
+
+	assertEqual(a.DifferenceAll(b, c), makeSet([]int{2, 5}), t)
+	assertEqual(a.DifferenceAll(), a, t)
+	assertEqual(a.DifferenceAll(a), NewSet(), t)
+	assertEqual(a, makeSet([]int{1, 2, 3, 4, 5}), t)
+}
+
+func Test_UnsafeSetDifferenceAll(t *testing.T) {
+	a := makeUnsafeSet([]int{1, 2, 3, 4, 5})
+	b := makeUnsafeSet([]int{1, 9})
+	c := makeUnsafeSet([]int{3, 4})
+
+	assertEqual(a.DifferenceAll(b, c), makeUnsafeSet([]int{2, 5}), t)
+	assertEqual(a.DifferenceAll(), a, t)
+	assertEqual(a.DifferenceAll(a), NewThreadUnsafeSet(), t)
+	assertEqual(a, makeUnsafeSet([]int{1, 2, 3, 4, 5}), t)
+}
+
+func Test_SetSymmetricDifference(t *testing.T) {
+	a := NewSet()
+	a.Add(1)
+	a.Add(2)
+	a.Add(3)
+	a.Add(45)
+
+	b := NewSet()
+	b.Add(1)
+	b.Add(3)
+	b.Add(4)
+	b.Add(5)
+	b.Add(6)
+	b.Add(99)
+
+	c := a.SymmetricDifference(b)
+
+	if !(c.Cardinality() == 6 && c.Contains(2) && c.Contains(45) && c.Contains(4) && c.Contains(5) && c.Contains(6) && c.Contains(99)) {
+		t.Error("the symmetric difference of set a to b is the set of 6 items: 2, 45, 4, 5, 6, 99")
+	}
+}
+
+func Test_UnsafeSetSymmetricDifference(t *testing.T) {
+	a := NewThreadUnsafeSet()
+	a.Add(1)
+	a.Add(2)
+	a.Add(3)
+	a.Add(45)
+
+	b := NewThreadUnsafeSet()
+	b.Add(1)
+	b.Add(3)
+	b.Add(4)
+	b.Add(5)
+	b.Add(6)
+	b.Add(99)
+
+	c := a.SymmetricDifference(b)
+
+	if !(c.Cardinality() == 6 && c.Contains(2) && c.Contains(45) && c.Contains(4) && c.Contains(5) && c.Contains(6) && c.Contains(99)) {
+		t.Error("the symmetric difference of set a to b is the set of 6 items: 2, 45, 4, 5, 6, 99")
+	}
+}
+
+func Test_SetEqual(t *testing.T) {
+	a := NewSet()
+	b := NewSet()
+
+	if !a.Equal(b) {
+		t.Error("Both a and b are empty sets, and should be equal")
+	}
+
+	a.Add(10)
+
+	if a.Equal(b) {
+		t.Error("a should not be equal to b because b is empty and a has item 1 in it")
+	}
+
+	b.Add(10)
+
+	if !a.Equal(b) {
+		t.Error("a is now equal again to b because both have the item 10 in them")
+	}
+
+	b.Add(8)
+	b.Add(3)
+	b.Add(47)
+
+	if a.Equal(b) {
+		t.Error("b has 3 more elements in it so therefore should not be equal to a")
+	}
+
+	a.Add(8)
+	a.Add(3)
+	a.Add(47)
+
+	if !a.Equal(b) {
+		t.Error("a and b should be equal with the same number of elements")
+	}
+}
+
+func Test_UnsafeSetEqual(t *testing.T) {
+	a := NewThreadUnsafeSet()
+	b := NewThreadUnsafeSet()
+
+	if !a.Equal(b) {
+		t.Error("Both a and b are empty sets, and should be equal")
+	}
+
+	a.Add(10)
+
+	if a.Equal(b) {
+		t.Error("a should not be equal to b because b is empty and a has item 1 in it")
 	}
 
 	b.Add(10)
@@ -904,6 +1749,269 @@ func Test_Each(t *testing.T) {
 	}
 }
 
+func Test_ForEach(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet("Z", "Y", "X", "W")
+
+		b := NewSet()
+		a.ForEach(func(elem interface{}) {
+			b.Add(elem)
+		})
+		if !a.Equal(b) {
+			t.Errorf("%s: ForEach should visit every element", name)
+		}
+
+		var count int
+		a.ForEachUntil(func(elem interface{}) bool {
+			count++
+			return count < 2
+		})
+		if count != 2 {
+			t.Errorf("%s: ForEachUntil should stop once the callback returns false, visited %d", name, count)
+		}
+	}
+}
+
+func Test_IterateIndexed(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet("Z", "Y", "X", "W")
+
+		b := NewSet()
+		var indexes []int
+		a.IterateIndexed(func(index int, elem interface{}) bool {
+			indexes = append(indexes, index)
+			b.Add(elem)
+			return true
+		})
+		if !a.Equal(b) || !reflect.DeepEqual(indexes, []int{0, 1, 2, 3}) {
+			t.Errorf("%s: IterateIndexed should visit every element with increasing indexes, got %v", name, indexes)
+		}
+
+		last := -1
+		a.IterateIndexed(func(index int, elem interface{}) bool {
+			last = index
+			return index < 1
+		})
+		if last != 1 {
+			t.Errorf("%s: IterateIndexed should stop once the callback returns false, stopped at %d", name, last)
+		}
+	}
+}
+
+func Test_MapSet(t *testing.T) {
+	a := makeSet([]int{1, 2, 3, 4})
+
+	b := a.Map(func(i interface{}) interface{} {
+		return i.(int) % 2
+	})
+
+	assertEqual(b, makeSet([]int{0, 1}), t)
+	if a.Cardinality() != 4 {
+		t.Error("Map should leave the original set unchanged")
+	}
+}
+
+func Test_MapUnsafeSet(t *testing.T) {
+	a := makeUnsafeSet([]int{1, 2, 3, 4})
+
+	b := a.Map(func(i interface{}) interface{} {
+		return i.(int) % 2
+	})
+
+	assertEqual(b, makeUnsafeSet([]int{0, 1}), t)
+	if a.Cardinality() != 4 {
+		t.Error("Map should leave the original set unchanged")
+	}
+}
+
+func sum(acc, elem interface{}) interface{} {
+	return acc.(int) + elem.(int)
+}
+
+func Test_PartitionSet(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2, 3, 4, 5)
+
+		even, odd := a.Partition(isEven)
+
+		if !even.Equal(NewSet(2, 4)) || !odd.Equal(NewSet(1, 3, 5)) {
+			t.Errorf("%s: unexpected partition %v and %v", name, even, odd)
+		}
+		if reflect.TypeOf(even) != reflect.TypeOf(a) || reflect.TypeOf(odd) != reflect.TypeOf(a) {
+			t.Errorf("%s: Partition returned %T and %T, want %T", name, even, odd, a)
+		}
+		if a.Cardinality() != 5 {
+			t.Errorf("%s: Partition should leave the original set unchanged", name)
+		}
+	}
+}
+
+func Test_GroupBySet(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2, 3, 4, 5, 6, 7)
+
+		groups := a.GroupBy(func(i interface{}) interface{} {
+			return i.(int) % 3
+		})
+
+		if len(groups) != 3 {
+			t.Fatalf("%s: expected 3 groups, got %d", name, len(groups))
+		}
+		for key, expected := range map[int]Set{0: NewSet(3, 6), 1: NewSet(1, 4, 7), 2: NewSet(2, 5)} {
+			group := groups[key]
+			if !group.Equal(expected) {
+				t.Errorf("%s: group %d should be %v, got %v", name, key, expected, group)
+			}
+			if reflect.TypeOf(group) != reflect.TypeOf(a) {
+				t.Errorf("%s: group %d is %T, want %T", name, key, group, a)
+			}
+		}
+	}
+
+	if groups := NewSet().GroupBy(func(i interface{}) interface{} { return i }); len(groups) != 0 {
+		t.Errorf("GroupBy on the empty set should return no groups, got %v", groups)
+	}
+}
+
+func Test_ReduceSet(t *testing.T) {
+	a := makeSet([]int{1, 2, 3, 4})
+
+	if total := a.Reduce(0, sum); total != 10 {
+		t.Errorf("Reduce should sum the set to 10, got %v", total)
+	}
+
+	if total := NewSet().Reduce(5, sum); total != 5 {
+		t.Errorf("Reduce over an empty set should return the initial value, got %v", total)
+	}
+}
+
+func Test_ReduceUnsafeSet(t *testing.T) {
+	a := makeUnsafeSet([]int{1, 2, 3, 4})
+
+	if total := a.Reduce(0, sum); total != 10 {
+		t.Errorf("Reduce should sum the set to 10, got %v", total)
+	}
+
+	if total := NewThreadUnsafeSet().Reduce(5, sum); total != 5 {
+		t.Errorf("Reduce over an empty set should return the initial value, got %v", total)
+	}
+}
+
+func isEven(i interface{}) bool {
+	return i.(int)%2 == 0
+}
+
+func Test_AnyAllSet(t *testing.T) {
+	a := makeSet([]int{2, 4, 5})
+
+	if !a.Any(isEven) {
+		t.Error("Any should find an even element")
+	}
+	if a.All(isEven) {
+		t.Error("All should fail since 5 is odd")
+	}
+
+	a.Remove(5)
+	if !a.All(isEven) {
+		t.Error("All should succeed once 5 is removed")
+	}
+
+	empty := NewSet()
+	if empty.Any(isEven) || !empty.All(isEven) {
+		t.Error("Any should be false and All should be true on an empty set")
+	}
+}
+
+func Test_AnyAllUnsafeSet(t *testing.T) {
+	a := makeUnsafeSet([]int{2, 4, 5})
+
+	if !a.Any(isEven) {
+		t.Error("Any should find an even element")
+	}
+	if a.All(isEven) {
+		t.Error("All should fail since 5 is odd")
+	}
+
+	a.Remove(5)
+	if !a.All(isEven) {
+		t.Error("All should succeed once 5 is removed")
+	}
+
+	empty := NewThreadUnsafeSet()
+	if empty.Any(isEven) || !empty.All(isEven) {
+		t.Error("Any should be false and All should be true on an empty set")
+	}
+}
+
+func Test_MinMax(t *testing.T) {
+	less := func(a, b interface{}) bool { return a.(int) < b.(int) }
+	for name, newSet := range setConstructors {
+		a := newSet(5, 3, 9, 1, 7)
+		if min, ok := a.Min(less); !ok || min != 1 {
+			t.Errorf("%s: Min should return 1, got %v, %v", name, min, ok)
+		}
+		if max, ok := a.Max(less); !ok || max != 9 {
+			t.Errorf("%s: Max should return 9, got %v, %v", name, max, ok)
+		}
+
+		if _, ok := newSet().Min(less); ok {
+			t.Errorf("%s: Min on an empty set should report false", name)
+		}
+		if _, ok := newSet().Max(less); ok {
+			t.Errorf("%s: Max on an empty set should report false", name)
+		}
+	}
+}
+
+func Test_Count(t *testing.T) {
+	for name, newSet := range setConstructors {
+		if n := newSet(1, 2, 3, 4, 5).Count(isEven); n != 2 {
+			t.Errorf("%s: Count should find 2 even elements, got %d", name, n)
+		}
+		if n := newSet().Count(isEven); n != 0 {
+			t.Errorf("%s: Count on an empty set should be 0, got %d", name, n)
+		}
+	}
+}
+
+func Test_EachParallel(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet()
+		for i := 0; i < 100; i++ {
+			a.Add(i)
+		}
+
+		var mutex sync.Mutex
+		sum := 0
+		a.EachParallel(4, func(elem interface{}) {
+			// Thread-safe sets are not locked during the calls.
+			if name != "unsafe" {
+				a.Add(elem.(int) + 100)
+			}
+
+			mutex.Lock()
+			sum += elem.(int)
+			mutex.Unlock()
+		})
+		if sum != 4950 {
+			t.Errorf("%s: EachParallel should visit every element once, got a sum of %d", name, sum)
+		}
+
+		errs := a.EachParallelErr(0, func(elem interface{}) error {
+			if elem.(int)%50 == 0 {
+				return fmt.Errorf("%v", elem)
+			}
+			return nil
+		})
+		if expected := a.Count(func(elem interface{}) bool { return elem.(int)%50 == 0 }); len(errs) != expected {
+			t.Errorf("%s: EachParallelErr should return %d errors, got %v", name, expected, errs)
+		}
+		if errs := newSet().EachParallelErr(4, func(interface{}) error { return nil }); errs != nil {
+			t.Errorf("%s: EachParallelErr should return nil without errors, got %v", name, errs)
+		}
+	}
+}
+
 func Test_Iter(t *testing.T) {
 	a := NewSet()
 
@@ -1006,52 +2114,171 @@ func Test_PopSafe(t *testing.T) {
 	captureSet.Add(a.Pop())
 	finalNil := a.Pop()
 
-	if captureSet.Cardinality() != 4 {
-		t.Error("unexpected captureSet cardinality; should be 4")
+	if captureSet.Cardinality() != 4 {
+		t.Error("unexpected captureSet cardinality; should be 4")
+	}
+
+	if a.Cardinality() != 0 {
+		t.Error("unepxected a cardinality; should be zero")
+	}
+
+	if !captureSet.Contains("c", "a", "d", "b") {
+		t.Error("unexpected result set; should be a,b,c,d (any order is fine")
+	}
+
+	if finalNil != nil {
+		t.Error("when original set is empty, further pops should result in nil")
+	}
+}
+
+func Test_PopUnsafe(t *testing.T) {
+	a := NewThreadUnsafeSet()
+
+	a.Add("a")
+	a.Add("b")
+	a.Add("c")
+	a.Add("d")
+
+	captureSet := NewThreadUnsafeSet()
+	captureSet.Add(a.Pop())
+	captureSet.Add(a.Pop())
+	captureSet.Add(a.Pop())
+	captureSet.Add(a.Pop())
+	finalNil := a.Pop()
+
+	if captureSet.Cardinality() != 4 {
+		t.Error("unexpected captureSet cardinality; should be 4")
+	}
+
+	if a.Cardinality() != 0 {
+		t.Error("unepxected a cardinality; should be zero")
+	}
+
+	if !captureSet.Contains("c", "a", "d", "b") {
+		t.Error("unexpected result set; should be a,b,c,d (any order is fine")
+	}
+
+	if finalNil != nil {
+		t.Error("when original set is empty, further pops should result in nil")
+	}
+}
+
+func Test_TryPopSafe(t *testing.T) {
+	a := NewSet(nil, "a")
+
+	captureSet := NewSet()
+	for {
+		item, ok := a.TryPop()
+		if !ok {
+			break
+		}
+		captureSet.Add(item)
+	}
+
+	if !captureSet.Contains(nil, "a") || captureSet.Cardinality() != 2 {
+		t.Error("TryPop should drain both nil and a from the set")
+	}
+
+	if a.Cardinality() != 0 {
+		t.Error("unexpected a cardinality; should be zero")
+	}
+}
+
+func Test_TryPopUnsafe(t *testing.T) {
+	a := NewThreadUnsafeSetWith(nil, "a")
+
+	captureSet := NewThreadUnsafeSet()
+	for {
+		item, ok := a.TryPop()
+		if !ok {
+			break
+		}
+		captureSet.Add(item)
+	}
+
+	if !captureSet.Contains(nil, "a") || captureSet.Cardinality() != 2 {
+		t.Error("TryPop should drain both nil and a from the set")
 	}
 
 	if a.Cardinality() != 0 {
-		t.Error("unepxected a cardinality; should be zero")
+		t.Error("unexpected a cardinality; should be zero")
 	}
+}
 
-	if !captureSet.Contains("c", "a", "d", "b") {
-		t.Error("unexpected result set; should be a,b,c,d (any order is fine")
+func Test_Sample(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+
+		sample := a.Sample(3)
+		if sample.Cardinality() != 3 || !sample.IsSubset(a) {
+			t.Errorf("%s: Sample(3) should return 3 elements of the set, got %v", name, sample)
+		}
+		if reflect.TypeOf(sample) != reflect.TypeOf(a) {
+			t.Errorf("%s: Sample returned %T, want %T", name, sample, a)
+		}
+		if a.Cardinality() != 10 {
+			t.Errorf("%s: Sample should leave the original set unchanged", name)
+		}
+
+		if !a.Sample(20).Equal(a) {
+			t.Errorf("%s: sampling more elements than the set holds should copy it", name)
+		}
+		if a.Sample(0).Cardinality() != 0 || a.Sample(-1).Cardinality() != 0 {
+			t.Errorf("%s: sampling no elements should return the empty set", name)
+		}
 	}
 
-	if finalNil != nil {
-		t.Error("when original set is empty, further pops should result in nil")
+	counts := make(map[interface{}]int)
+	a, r := makeUnsafeSet([]int{0, 1, 2, 3, 4}), rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		counts[a.SampleWithRand(1, r).Pop()]++
+	}
+	for elem, count := range counts {
+		if count < 800 || count > 1200 {
+			t.Errorf("element %v was sampled %d times out of 5000, expected about 1000", elem, count)
+		}
 	}
 }
 
-func Test_PopUnsafe(t *testing.T) {
-	a := NewThreadUnsafeSet()
+func Test_SampleWithRandReproducible(t *testing.T) {
+	a := NewOrderedSet(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
 
-	a.Add("a")
-	a.Add("b")
-	a.Add("c")
-	a.Add("d")
+	first := a.SampleWithRand(4, rand.New(rand.NewSource(42)))
+	second := a.SampleWithRand(4, rand.New(rand.NewSource(42)))
+	if !reflect.DeepEqual(first.ToSlice(), second.ToSlice()) {
+		t.Errorf("equally seeded samples of an ordered set should match, got %v and %v", first, second)
+	}
+}
 
-	captureSet := NewThreadUnsafeSet()
-	captureSet.Add(a.Pop())
-	captureSet.Add(a.Pop())
-	captureSet.Add(a.Pop())
-	captureSet.Add(a.Pop())
-	finalNil := a.Pop()
+func Test_PeekSafe(t *testing.T) {
+	a := NewSet("a", "b")
 
-	if captureSet.Cardinality() != 4 {
-		t.Error("unexpected captureSet cardinality; should be 4")
+	item, ok := a.Peek()
+	if !ok || !a.Contains(item) {
+		t.Errorf("Peek should return an element of the set, got %v", item)
+	}
+	if a.Cardinality() != 2 {
+		t.Error("Peek should not remove the element")
 	}
 
-	if a.Cardinality() != 0 {
-		t.Error("unepxected a cardinality; should be zero")
+	if item, ok := NewSet().Peek(); ok || item != nil {
+		t.Error("Peek on the empty set should return nil and false")
 	}
+}
 
-	if !captureSet.Contains("c", "a", "d", "b") {
-		t.Error("unexpected result set; should be a,b,c,d (any order is fine")
+func Test_PeekUnsafe(t *testing.T) {
+	a := NewThreadUnsafeSetWith("a", "b")
+
+	item, ok := a.Peek()
+	if !ok || !a.Contains(item) {
+		t.Errorf("Peek should return an element of the set, got %v", item)
+	}
+	if a.Cardinality() != 2 {
+		t.Error("Peek should not remove the element")
 	}
 
-	if finalNil != nil {
-		t.Error("when original set is empty, further pops should result in nil")
+	if item, ok := NewThreadUnsafeSetWith().Peek(); ok || item != nil {
+		t.Error("Peek on the empty set should return nil and false")
 	}
 }
 
@@ -1083,6 +2310,93 @@ func Test_PowerSetThreadSafe(t *testing.T) {
 	}
 }
 
+func Test_PowerSetSubsetsAreSets(t *testing.T) {
+	for name, newSet := range setConstructors {
+		cardinalities := 0
+		for elem := range newSet(1, 2, 3).PowerSet().Iter() {
+			subset, ok := elem.(Set)
+			if !ok {
+				t.Fatalf("%s: PowerSet subset %v is not a Set", name, elem)
+			}
+			cardinalities += subset.Cardinality()
+		}
+		if cardinalities != 12 {
+			t.Errorf("%s: subsets should hold 12 elements in total, got %d", name, cardinalities)
+		}
+	}
+}
+
+func Test_SliceIterator(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2, 3)
+		b := newSet()
+
+		it := NewSliceIterator(a)
+		a.Add(4)
+		for elem, ok := it.Next(); ok; elem, ok = it.Next() {
+			b.Add(elem)
+		}
+		if !b.Equal(newSet(1, 2, 3)) {
+			t.Errorf("%s: expected the elements when the iterator was created, got %v", name, b)
+		}
+		if elem, ok := it.Next(); ok || elem != nil {
+			t.Errorf("%s: an exhausted iterator should return nil and false, got %v", name, elem)
+		}
+
+		// Abandoning an iterator holds no lock of the set.
+		NewSliceIterator(a).Next()
+		a.Add(5)
+	}
+}
+
+func Test_PowerSetIterator(t *testing.T) {
+	for name, newSet := range setConstructors {
+		set := newSet(1, 2, 3)
+		subsets := make(map[string]bool)
+		for elem := range set.PowerSetIterator().C {
+			subset := elem.(Set)
+			if !subset.IsSubset(set) {
+				t.Errorf("%s: %v is not a subset of %v", name, subset, set)
+			}
+			subsets[subset.StringSorted()] = true
+		}
+		if len(subsets) != 8 {
+			t.Errorf("%s: expected 8 distinct subsets, got %v", name, subsets)
+		}
+
+		var empty []Set
+		for elem := range newSet().PowerSetIterator().C {
+			empty = append(empty, elem.(Set))
+		}
+		if len(empty) != 1 || empty[0].Cardinality() != 0 {
+			t.Errorf("%s: the power set of the empty set should only hold the empty set, got %v", name, empty)
+		}
+	}
+}
+
+func Test_PowerSetIteratorStop(t *testing.T) {
+	set := NewSet()
+	for i := 0; i < 64; i++ {
+		set.Add(i)
+	}
+
+	it := set.PowerSetIterator()
+	received := 0
+	for range it.C {
+		received++
+		if received == 1 {
+			// The iterator ranges over a snapshot, so the set is not locked.
+			set.Add(64)
+		}
+		if received == 3 {
+			it.Stop()
+		}
+	}
+	if received != 3 {
+		t.Errorf("PowerSetIterator kept sending after Stop, received %d subsets", received)
+	}
+}
+
 func Test_EmptySetProperties(t *testing.T) {
 	empty := NewSet()
 
@@ -1168,6 +2482,147 @@ func Test_CartesianProduct(t *testing.T) {
 	}
 }
 
+func Test_CartesianProductIterator(t *testing.T) {
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			a, b := newA(1, 2, 3), newB("one", "two")
+			product := a.CartesianProduct(b)
+
+			received := 0
+			for pair := range a.CartesianProductIterator(b).C {
+				received++
+				if !product.Contains(pair) {
+					t.Errorf("%s x %s: unexpected pair %v", an, bn, pair)
+				}
+			}
+			if received != product.Cardinality() {
+				t.Errorf("%s x %s: expected %d pairs, got %d", an, bn, product.Cardinality(), received)
+			}
+
+			for pair := range a.CartesianProductIterator(newB()).C {
+				t.Errorf("%s x %s: the product with the empty set should be empty, got %v", an, bn, pair)
+			}
+		}
+	}
+}
+
+func Test_CartesianProductIteratorStop(t *testing.T) {
+	a, b := NewSet(), NewSet()
+	for i := 0; i < 100; i++ {
+		a.Add(i)
+		b.Add(i)
+	}
+
+	it := a.CartesianProductIterator(b)
+	received := 0
+	for range it.C {
+		received++
+		if received == 1 {
+			// The iterator ranges over snapshots, so neither set is locked.
+			a.Add(100)
+			b.Add(100)
+		}
+		if received == 3 {
+			it.Stop()
+		}
+	}
+	if received != 3 {
+		t.Errorf("CartesianProductIterator kept sending after Stop, received %d pairs", received)
+	}
+	it.Stop()
+}
+
+func Test_NewSetFromMap(t *testing.T) {
+	m := map[interface{}]struct{}{1: {}, "a": {}}
+	s := NewSetFromMap(m)
+	assertEqual(s, NewSet(1, "a"), t)
+
+	m[2] = struct{}{}
+	if s.Contains(2) {
+		t.Error("modifying the map should not modify the set")
+	}
+
+	assertEqual(NewSetFromMap(s.ToMap()), s, t)
+}
+
+func Test_NewSetFromMapKeys(t *testing.T) {
+	s := NewSetFromMapKeys(map[string]int{"a": 1, "b": 2})
+	assertEqual(s, NewSet("a", "b"), t)
+
+	if s := NewSetFromMapKeys(map[int]struct{}{}); s.Cardinality() != 0 {
+		t.Errorf("the keys of an empty map should give the empty set, got %v", s)
+	}
+
+	assertPanics(t, "NewSetFromMapKeys", func() { NewSetFromMapKeys([]string{"a"}) })
+}
+
+func Test_Chunk(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2, 3, 4, 5, 6, 7)
+		chunks := a.Chunk(3)
+		if len(chunks) != 3 || len(chunks[0]) != 3 || len(chunks[1]) != 3 || len(chunks[2]) != 1 {
+			t.Fatalf("%s: expected chunks of 3, 3 and 1 elements, got %v", name, chunks)
+		}
+
+		b := newSet()
+		for _, c := range chunks {
+			b.AddAll(c...)
+		}
+		if !b.Equal(a) {
+			t.Errorf("%s: the chunks should hold every element, got %v", name, chunks)
+		}
+
+		if chunks := a.Chunk(0); len(chunks) != 7 {
+			t.Errorf("%s: a size of 0 should be treated as 1, got %v", name, chunks)
+		}
+		if chunks := newSet().Chunk(3); len(chunks) != 0 {
+			t.Errorf("%s: the empty set should have no chunks, got %v", name, chunks)
+		}
+	}
+}
+
+func Test_Elements(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2, 3)
+		elements := a.Elements()
+		if len(elements) != 3 || !newSet(elements...).Equal(a) {
+			t.Errorf("%s: expected every element, got %v", name, elements)
+		}
+
+		a.Add(4)
+		if len(elements) != 3 {
+			t.Errorf("%s: the elements should be a snapshot, got %v", name, elements)
+		}
+		if elements := newSet().Elements(); len(elements) != 0 {
+			t.Errorf("%s: the empty set should have no elements, got %v", name, elements)
+		}
+	}
+}
+
+func Test_ToMap(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2, 3)
+		m := a.ToMap()
+		if !reflect.DeepEqual(m, map[interface{}]struct{}{1: {}, 2: {}, 3: {}}) {
+			t.Errorf("%s: unexpected map %v", name, m)
+		}
+
+		m[4] = struct{}{}
+		delete(m, 1)
+		if !a.Equal(NewSet(1, 2, 3)) {
+			t.Errorf("%s: modifying the map should not modify the set, got %v", name, a)
+		}
+	}
+}
+
+func Test_SliceRoundTrip(t *testing.T) {
+	a := makeSet([]int{1, 2, 3})
+	assertEqual(NewSetFromSlice(a.ToSlice()), a, t)
+
+	b := makeUnsafeSet([]int{1, 2, 3})
+	assertEqual(NewThreadUnsafeSetFromSlice(b.ToSlice()), b, t)
+}
+
 func Test_ToSliceUnthreadsafe(t *testing.T) {
 	s := makeUnsafeSet([]int{1, 2, 3})
 	setAsSlice := s.ToSlice()
@@ -1182,6 +2637,41 @@ func Test_ToSliceUnthreadsafe(t *testing.T) {
 	}
 }
 
+func lessInt(a, b interface{}) bool {
+	return a.(int) < b.(int)
+}
+
+func Test_Drain(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2, 3)
+
+		drained := NewSetFromSlice(a.Drain())
+		if !drained.Equal(NewSet(1, 2, 3)) {
+			t.Errorf("%s: Drain should return every element, got %v", name, drained)
+		}
+		if a.Cardinality() != 0 {
+			t.Errorf("%s: Drain should leave the set empty, got %v", name, a)
+		}
+
+		a.Add(4)
+		if !a.Equal(NewSet(4)) {
+			t.Errorf("%s: the set should still be usable after Drain, got %v", name, a)
+		}
+		if items := newSet().Drain(); len(items) != 0 {
+			t.Errorf("%s: draining the empty set should return no elements, got %v", name, items)
+		}
+	}
+}
+
+func Test_ToSortedSlice(t *testing.T) {
+	for _, s := range []Set{makeSet([]int{3, 1, 2}), makeUnsafeSet([]int{3, 1, 2})} {
+		sorted := s.ToSortedSlice(lessInt)
+		if len(sorted) != 3 || sorted[0] != 1 || sorted[1] != 2 || sorted[2] != 3 {
+			t.Errorf("ToSortedSlice should return [1 2 3], got %v", sorted)
+		}
+	}
+}
+
 func Test_Example(t *testing.T) {
 	/*
 	   requiredClasses := NewSet()
@@ -1222,3 +2712,362 @@ func Test_Example(t *testing.T) {
 	   fmt.Println(allClasses.ContainsAll("Welding", "Automotive", "English"))
 	*/
 }
+
+var setConstructors = map[string]func(...interface{}) Set{
+	"safe":    NewSet,
+	"unsafe":  NewThreadUnsafeSetWith,
+	"sharded": func(i ...interface{}) Set { s := NewShardedSet(4); s.AddAll(i...); return s },
+	"ordered": NewOrderedSet,
+}
+
+func Test_CrossTypeOperations(t *testing.T) {
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			a, b := newA(1, 2, 3), newB(3, 4)
+			check := func(op string, actual Set, expected ...interface{}) {
+				if !actual.Equal(NewSet(expected...)) {
+					t.Errorf("%s %s %s: unexpected result %v", an, op, bn, actual)
+				}
+			}
+
+			check("Union", a.Union(b), 1, 2, 3, 4)
+			check("UnionAll", a.UnionAll(b, b), 1, 2, 3, 4)
+			check("Intersect", a.Intersect(b), 3)
+			check("IntersectAll", a.IntersectAll(b, b), 3)
+			check("Difference", a.Difference(b), 1, 2)
+			check("DifferenceAll", a.DifferenceAll(b, b), 1, 2)
+			check("SymmetricDifference", a.SymmetricDifference(b), 1, 2, 4)
+
+			if a.CartesianProduct(b).Cardinality() != 6 {
+				t.Errorf("%s CartesianProduct %s should have 6 pairs", an, bn)
+			}
+
+			if !a.Equal(newB(3, 2, 1)) || a.Equal(b) {
+				t.Errorf("%s Equal %s is wrong", an, bn)
+			}
+
+			sub := newB(1, 2)
+			if !a.IsSuperset(sub) || !a.IsProperSuperset(sub) || a.IsSubset(sub) || a.IsProperSubset(sub) {
+				t.Errorf("%s superset relations against %s are wrong", an, bn)
+			}
+			if !newA(1, 2).IsSubset(newB(1, 2)) || newA(1, 2).IsProperSubset(newB(1, 2)) {
+				t.Errorf("%s subset relations against %s are wrong", an, bn)
+			}
+
+			if !a.Intersects(b) || a.IsDisjoint(b) || a.Intersects(newB(5)) || !a.IsDisjoint(newB(5)) {
+				t.Errorf("%s Intersects %s is wrong", an, bn)
+			}
+
+			if removed := a.RetainAll(b); removed != 2 {
+				t.Errorf("%s RetainAll %s should remove 2 elements, removed %d", an, bn, removed)
+			}
+			check("RetainAll", a, 3)
+		}
+	}
+}
+
+func Test_CrossTypeSubsetRelations(t *testing.T) {
+	identity := func(i interface{}) interface{} { return i }
+	constructors := map[string]func(...interface{}) Set{
+		"frozen":  func(i ...interface{}) Set { return NewSet(i...).Freeze() },
+		"bounded": func(i ...interface{}) Set { s := NewBoundedSet(10); s.AddAll(i...); return s },
+		"lru":     func(i ...interface{}) Set { s := NewLRUSet(10); s.AddAll(i...); return s },
+		"keyed":   func(i ...interface{}) Set { s := NewSetByKey(identity); s.AddAll(i...); return s },
+	}
+	for name, newSet := range setConstructors {
+		constructors[name] = newSet
+	}
+
+	cases := []struct {
+		a, b                                  []interface{}
+		subset, proper, superset, properSuper bool
+	}{
+		{nil, nil, true, false, true, false},
+		{nil, []interface{}{1}, true, true, false, false},
+		{[]interface{}{1}, nil, false, false, true, true},
+		{[]interface{}{1, 2}, []interface{}{2, 1}, true, false, true, false},
+		{[]interface{}{1}, []interface{}{1, 2}, true, true, false, false},
+		{[]interface{}{1, 2}, []interface{}{1}, false, false, true, true},
+		{[]interface{}{1, 2}, []interface{}{2, 3}, false, false, false, false},
+	}
+
+	for an, newA := range constructors {
+		for bn, newB := range constructors {
+			for _, c := range cases {
+				a, b := newA(c.a...), newB(c.b...)
+				if a.IsSubset(b) != c.subset {
+					t.Errorf("%s %v IsSubset %s %v should be %v", an, c.a, bn, c.b, c.subset)
+				}
+				if a.IsProperSubset(b) != c.proper {
+					t.Errorf("%s %v IsProperSubset %s %v should be %v", an, c.a, bn, c.b, c.proper)
+				}
+				if a.IsSuperset(b) != c.superset {
+					t.Errorf("%s %v IsSuperset %s %v should be %v", an, c.a, bn, c.b, c.superset)
+				}
+				if a.IsProperSuperset(b) != c.properSuper {
+					t.Errorf("%s %v IsProperSuperset %s %v should be %v", an, c.a, bn, c.b, c.properSuper)
+				}
+			}
+		}
+
+		a := newA(1, 2)
+		if !a.IsSubset(a) || a.IsProperSubset(a) || !a.IsSuperset(a) || a.IsProperSuperset(a) {
+			t.Errorf("%s: subset relations of a set with itself are wrong", an)
+		}
+	}
+}
+
+func Test_BinaryOperationResultType(t *testing.T) {
+	constructors := map[string]func(...interface{}) Set{
+		"safe":   NewSet,
+		"unsafe": NewThreadUnsafeSetWith,
+	}
+
+	for an, newA := range constructors {
+		for bn, newB := range constructors {
+			a, b := newA(1, 2, 3), newB(3, 4)
+			check := func(op string, actual, expected Set) {
+				if !actual.Equal(expected) {
+					t.Errorf("%s %s %s: unexpected result %v", an, op, bn, actual)
+				}
+				if reflect.TypeOf(actual) != reflect.TypeOf(a) {
+					t.Errorf("%s %s %s: result is %T, want %T", an, op, bn, actual, a)
+				}
+			}
+
+			check("Union", a.Union(b), NewSet(1, 2, 3, 4))
+			check("Intersect", a.Intersect(b), NewSet(3))
+			check("Difference", a.Difference(b), NewSet(1, 2))
+			check("SymmetricDifference", a.SymmetricDifference(b), NewSet(1, 2, 4))
+		}
+	}
+}
+
+func Test_CopyTo(t *testing.T) {
+	for an, newA := range setConstructors {
+		for bn, newB := range setConstructors {
+			a, dest := newA(1, 2, 3), newB(4, 5)
+			a.CopyTo(dest)
+
+			if !dest.Equal(NewSet(1, 2, 3)) {
+				t.Errorf("%s CopyTo %s: unexpected result %v", an, bn, dest)
+			}
+
+			dest.Add(4)
+			if a.Contains(4) {
+				t.Errorf("%s CopyTo %s: changing dest should not affect the set", an, bn)
+			}
+		}
+	}
+
+	s := makeSet([]int{1, 2, 3})
+	s.CopyTo(s)
+	if !s.Equal(NewSet(1, 2, 3)) {
+		t.Error("CopyTo onto the set itself should leave it unchanged")
+	}
+}
+
+func Test_CSV(t *testing.T) {
+	for name, newSet := range setConstructors {
+		set := newSet("a", "b,c", `say "hi"`, 42)
+
+		var buf bytes.Buffer
+		if err := set.WriteCSV(&buf); err != nil {
+			t.Fatalf("%s: WriteCSV failed: %v", name, err)
+		}
+
+		decoded := newSet()
+		if err := decoded.ReadCSV(&buf); err != nil {
+			t.Fatalf("%s: ReadCSV failed: %v", name, err)
+		}
+		if !decoded.Equal(NewSet("a", "b,c", `say "hi"`, "42")) {
+			t.Errorf("%s: CSV should round-trip strings and format other elements, got %v", name, decoded)
+		}
+	}
+
+	set := NewSet()
+	if err := set.ReadCSV(strings.NewReader("a,b\nc\n")); err != nil {
+		t.Fatal(err)
+	}
+	if !set.Equal(NewSet("a", "b", "c")) {
+		t.Errorf("ReadCSV should add every field of every row, got %v", set)
+	}
+
+	if err := set.ReadCSV(strings.NewReader("\"unterminated\n")); err == nil {
+		t.Error("ReadCSV should report malformed CSV")
+	}
+}
+
+func Test_IterSnapshot(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet("Z", "Y", "X", "W")
+
+		b := NewSet()
+		for elem := range a.IterSnapshot() {
+			b.Add(elem)
+		}
+		if !a.Equal(b) {
+			t.Errorf("%s: IterSnapshot should yield every element", name)
+		}
+	}
+}
+
+func Test_IterSorted(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet(3, 1, 4, 2)
+
+		var got []interface{}
+		for elem := range a.IterSorted(lessInt) {
+			got = append(got, elem)
+		}
+		if !reflect.DeepEqual(got, []interface{}{1, 2, 3, 4}) {
+			t.Errorf("%s: IterSorted should yield the elements in order, got %v", name, got)
+		}
+	}
+}
+
+func Test_CollectChan(t *testing.T) {
+	ch := make(chan interface{})
+	go func() {
+		for _, v := range []interface{}{1, 2, 2, "a", 1} {
+			ch <- v
+		}
+		close(ch)
+	}()
+
+	if a := CollectChan(ch); !a.Equal(NewSet(1, 2, "a")) {
+		t.Errorf("CollectChan should deduplicate the elements received, got %v", a)
+	}
+	for name, newSet := range setConstructors {
+		a := newSet(1, 2, 3)
+		if b := CollectChan(a.Iter()); !b.Equal(a) {
+			t.Errorf("%s: CollectChan should invert Iter, got %v", name, b)
+		}
+	}
+}
+
+func Test_CollectFunc(t *testing.T) {
+	i := 0
+	a := CollectFunc(func() (interface{}, bool) {
+		i++
+		return i % 3, i <= 6
+	})
+	if !a.Equal(NewSet(0, 1, 2)) || i != 7 {
+		t.Errorf("CollectFunc should stop once next returns false, got %v after %d calls", a, i)
+	}
+
+	b := NewSet("x", "y")
+	if c := CollectFunc(NewSliceIterator(b).Next); !c.Equal(b) {
+		t.Errorf("CollectFunc should collect from a SliceIterator, got %v", c)
+	}
+	if d := CollectFunc(func() (interface{}, bool) { return nil, false }); d.Cardinality() != 0 {
+		t.Errorf("CollectFunc should return an empty set for an empty generator, got %v", d)
+	}
+}
+
+func Test_Tee(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet()
+		for i := 0; i < 4*iterBufferSize; i++ {
+			a.Add(i)
+		}
+
+		first, second := a.Tee()
+		// Drain the second channel first: it must not wait on the first.
+		b := CollectChan(second)
+		c := CollectChan(first)
+		if !a.Equal(b) || !a.Equal(c) {
+			t.Errorf("%s: both channels of Tee should yield every element, got %v and %v", name, c, b)
+		}
+	}
+}
+
+func Test_IterContext(t *testing.T) {
+	for name, newSet := range setConstructors {
+		a := newSet("Z", "Y", "X", "W")
+
+		b := NewSet()
+		for elem := range a.IterContext(context.Background()) {
+			b.Add(elem)
+		}
+		if !a.Equal(b) {
+			t.Errorf("%s: IterContext should yield every element", name)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		count := 0
+		for range a.IterContext(ctx) {
+			count++
+		}
+		if count > 4 {
+			t.Errorf("%s: IterContext yielded %d elements out of 4", name, count)
+		}
+	}
+}
+
+func Test_Hash(t *testing.T) {
+	expected := NewSet(1, "a", 2.5, true).Hash()
+	for name, newSet := range setConstructors {
+		if h := newSet(true, 2.5, "a", 1).Hash(); h != expected {
+			t.Errorf("%s: equal sets should hash to the same value, got %x and %x", name, h, expected)
+		}
+		if newSet(1, 2).Hash() == newSet(3).Hash() || newSet(1).Hash() == newSet().Hash() {
+			t.Errorf("%s: different sets should hash to different values", name)
+		}
+	}
+
+	byHash := make(map[uint64]Set)
+	byHash[NewSet("x", "y").Hash()] = NewSet("x", "y")
+	if s, ok := byHash[NewThreadUnsafeSetWith("y", "x").Hash()]; !ok || !s.Equal(NewSet("x", "y")) {
+		t.Error("a set should be found by the hash of an equal set")
+	}
+}
+
+func Test_MarshalText(t *testing.T) {
+	for name, newSet := range setConstructors {
+		set := newSet("c", "a", "b|d", `e\f`)
+
+		text, err := set.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(text) != `a|b\|d|c|e\\f` {
+			t.Errorf("%s: unexpected text form %q", name, text)
+		}
+
+		decoded := newSet()
+		if err := decoded.UnmarshalText(text); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !decoded.Equal(set) {
+			t.Errorf("%s: the text form should round-trip, got %v", name, decoded)
+		}
+	}
+
+	if text, _ := NewSet(2, 1, true).MarshalText(); string(text) != "1|2|true" {
+		t.Errorf("non-string elements should be formatted, got %q", text)
+	}
+
+	s := NewSet()
+	if err := s.UnmarshalText(nil); err != nil || s.Cardinality() != 0 {
+		t.Error("an empty text should decode into the empty set")
+	}
+	for _, invalid := range []string{`a\`, `a\b`} {
+		if err := s.UnmarshalText([]byte(invalid)); err == nil {
+			t.Errorf("UnmarshalText should reject the invalid escape in %q", invalid)
+		}
+	}
+}
+
+func Test_MarshalTextMapKeys(t *testing.T) {
+	counts := map[Set]int{NewSet("a", "b"): 1, NewSet("c"): 2}
+
+	b, err := json.Marshal(counts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"a|b":1,"c":2}` {
+		t.Errorf("sets should be usable as JSON object keys, got %s", b)
+	}
+}
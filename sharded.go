@@ -0,0 +1,1434 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// shardedSet spreads its elements over several thread-safe shards, each
+// guarded by its own mutex, so that goroutines working on elements that
+// hash to different shards never contend. Operations spanning every shard
+// lock the shards in order of increasing address, which is also the order
+// of their index.
+type shardedSet struct {
+	shards []shard
+}
+
+type shard struct {
+	threadSafeSet
+
+	// Keeps neighbouring shards off the same cache line.
+	_ [64]byte
+}
+
+func newShardedSet(shards int) *shardedSet {
+	if shards < 1 {
+		shards = 1
+	}
+
+	set := &shardedSet{shards: make([]shard, shards)}
+	for i := range set.shards {
+		set.shards[i].objects = newThreadUnsafeSet()
+	}
+
+	return set
+}
+
+// hashElement returns a hash of elem such that elements which are equal
+// according to == always hash to the same value. Numbers, strings and
+// booleans are hashed directly, pointers and channels by address, and
+// structs, arrays and interfaces field by field, see hashValue.
+func hashElement(elem interface{}) uint64 {
+	var n uint64
+	switch e := elem.(type) {
+	case int:
+		n = uint64(e)
+	case int8:
+		n = uint64(e)
+	case int16:
+		n = uint64(e)
+	case int32:
+		n = uint64(e)
+	case int64:
+		n = uint64(e)
+	case uint:
+		n = uint64(e)
+	case uint8:
+		n = uint64(e)
+	case uint16:
+		n = uint64(e)
+	case uint32:
+		n = uint64(e)
+	case uint64:
+		n = e
+	case uintptr:
+		n = uint64(e)
+	case float32:
+		n = hashFloat(float64(e))
+	case float64:
+		n = hashFloat(e)
+	case bool:
+		if e {
+			n = 1
+		}
+	case string:
+		return hashString(e)
+	default:
+		return hashValue(reflect.ValueOf(elem))
+	}
+
+	// Spread consecutive numbers over all shards.
+	return n * 0x9e3779b97f4a7c15
+}
+
+// hashValue is hashElement for values of any comparable type, named types
+// and composites included. Structs and arrays are hashed field by field
+// and interfaces by their dynamic value, so that == values such as structs
+// holding +0 and -0 hash identically, which their fmt representations
+// would not.
+func hashValue(v reflect.Value) uint64 {
+	var n uint64
+	switch v.Kind() {
+	case reflect.Invalid:
+		return 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = v.Uint()
+	case reflect.Float32, reflect.Float64:
+		n = hashFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		n = hashFloat(real(c))*31 + hashFloat(imag(c))
+	case reflect.Bool:
+		if v.Bool() {
+			n = 1
+		}
+	case reflect.String:
+		return hashString(v.String())
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		// Pointers compare by address, not by the value they point to,
+		// which may change.
+		n = uint64(v.Pointer())
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return hashValue(v.Elem())
+	case reflect.Struct:
+		h := uint64(fnvOffset)
+		for i := 0; i < v.NumField(); i++ {
+			// == ignores blank fields, so must the hash.
+			if v.Type().Field(i).Name == "_" {
+				continue
+			}
+			h = (h ^ hashValue(v.Field(i))) * fnvPrime
+		}
+		return h
+	case reflect.Array:
+		h := uint64(fnvOffset)
+		for i := 0; i < v.Len(); i++ {
+			h = (h ^ hashValue(v.Index(i))) * fnvPrime
+		}
+		return h
+	default:
+		// Only funcs, maps and slices are left, which cannot be stored
+		// in a set as they are not comparable.
+		return 0
+	}
+
+	return n * 0x9e3779b97f4a7c15
+}
+
+// The 64-bit FNV-1 parameters, used to combine the hashes of the fields of
+// a struct or the elements of an array.
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func hashFloat(f float64) uint64 {
+	if f == 0 {
+		// -0 == +0, so both must hash identically.
+		return 0
+	}
+	return math.Float64bits(f)
+}
+
+func (set *shardedSet) shard(elem interface{}) *threadSafeSet {
+	return &set.shards[hashElement(elem)%uint64(len(set.shards))].threadSafeSet
+}
+
+// mutexes returns the mutex of every shard, in index order.
+func (set *shardedSet) mutexes() []*sync.RWMutex {
+	mutexes := make([]*sync.RWMutex, len(set.shards))
+	for i := range set.shards {
+		mutexes[i] = &set.shards[i].mutex
+	}
+	return mutexes
+}
+
+// rlock read-locks every shard and returns a func releasing them.
+func (set *shardedSet) rlock() func() {
+	return rlockAll(set.mutexes()...)
+}
+
+// lock write-locks every shard and returns a func releasing them.
+func (set *shardedSet) lock() func() {
+	for i := range set.shards {
+		set.shards[i].mutex.Lock()
+	}
+
+	return func() {
+		for i := range set.shards {
+			set.shards[i].mutex.Unlock()
+		}
+	}
+}
+
+// rlockSharded read-locks every shard of all the given sets, in the
+// global address order used by rlockAll.
+func rlockSharded(sets ...*shardedSet) func() {
+	var mutexes []*sync.RWMutex
+	for _, s := range sets {
+		mutexes = append(mutexes, s.mutexes()...)
+	}
+	return rlockAll(mutexes...)
+}
+
+// The following helpers do not lock, callers must hold the shard locks.
+
+func (set *shardedSet) has(elem interface{}) bool {
+	_, found := set.shard(elem).objects[elem]
+	return found
+}
+
+func (set *shardedSet) insert(elem interface{}) bool {
+	return set.shard(elem).objects.Add(elem)
+}
+
+func (set *shardedSet) size() int {
+	n := 0
+	for i := range set.shards {
+		n += len(set.shards[i].objects)
+	}
+	return n
+}
+
+// each calls callback on every element until it returns true.
+func (set *shardedSet) each(callback func(interface{}) bool) {
+	for i := range set.shards {
+		for elem := range set.shards[i].objects {
+			if callback(elem) {
+				return
+			}
+		}
+	}
+}
+
+// flatten copies every element into a single thread-unsafe set.
+func (set *shardedSet) flatten() threadUnsafeSet {
+	flat := newThreadUnsafeSetWithSize(set.size())
+	set.each(func(elem interface{}) bool {
+		flat.Add(elem)
+		return false
+	})
+	return flat
+}
+
+// coerce returns other if it is a sharded set, and otherwise a private
+// sharded copy of it, so that binary operations accept any Set.
+func (set *shardedSet) coerce(other Set) *shardedSet {
+	if o, ok := other.(*shardedSet); ok {
+		return o
+	}
+
+	o := set.derive()
+	other.Each(func(elem interface{}) bool {
+		o.insert(elem)
+		return false
+	})
+	return o
+}
+
+// derive returns an empty set with the same number of shards.
+func (set *shardedSet) derive() *shardedSet {
+	return newShardedSet(len(set.shards))
+}
+
+func (set *shardedSet) Add(i interface{}) bool {
+	return set.shard(i).Add(i)
+}
+
+func (set *shardedSet) AddAll(i ...interface{}) int {
+	added := 0
+	for shard, items := range set.group(i) {
+		added += set.shards[shard].AddAll(items...)
+	}
+
+	return added
+}
+
+// AddIfNotContains write-locks the shard of candidate and read-locks the
+// shard of guard, in order of increasing address when they differ.
+func (set *shardedSet) AddIfNotContains(candidate, guard interface{}) bool {
+	c, g := set.shard(candidate), set.shard(guard)
+	if c == g {
+		return c.AddIfNotContains(candidate, guard)
+	}
+
+	if lessAddress(&c.mutex, &g.mutex) {
+		c.mutex.Lock()
+		g.mutex.RLock()
+	} else {
+		g.mutex.RLock()
+		c.mutex.Lock()
+	}
+	defer c.mutex.Unlock()
+	defer g.mutex.RUnlock()
+
+	if _, found := g.objects[guard]; found {
+		return false
+	}
+	return c.objects.Add(candidate)
+}
+
+// group buckets items by the index of the shard they belong to, so that
+// every shard is only locked once.
+func (set *shardedSet) group(items []interface{}) map[int][]interface{} {
+	groups := make(map[int][]interface{})
+	for _, item := range items {
+		shard := int(hashElement(item) % uint64(len(set.shards)))
+		groups[shard] = append(groups[shard], item)
+	}
+	return groups
+}
+
+func (set *shardedSet) Cardinality() int {
+	unlock := set.rlock()
+	defer unlock()
+
+	return set.size()
+}
+
+func (set *shardedSet) Length() int {
+	return set.Cardinality()
+}
+
+func (set *shardedSet) Clear() {
+	unlock := set.lock()
+	defer unlock()
+
+	for i := range set.shards {
+		set.shards[i].objects = newThreadUnsafeSet()
+	}
+}
+
+func (set *shardedSet) ClearRetainingCapacity() {
+	unlock := set.lock()
+	defer unlock()
+
+	for i := range set.shards {
+		set.shards[i].objects.clear()
+	}
+}
+
+func (set *shardedSet) ReplaceAll(i ...interface{}) {
+	set.reset(i)
+}
+
+func (set *shardedSet) Clone() Set {
+	unlock := set.rlock()
+	defer unlock()
+
+	clone := set.derive()
+	for i := range set.shards {
+		clone.shards[i].objects = *set.shards[i].objects.Clone().(*threadUnsafeSet)
+	}
+
+	return clone
+}
+
+func (set *shardedSet) Snapshot() Set {
+	return set.Clone()
+}
+
+func (set *shardedSet) reset(items []interface{}) {
+	unlock := set.lock()
+	defer unlock()
+
+	for i := range set.shards {
+		set.shards[i].objects.clear()
+	}
+	for _, item := range items {
+		set.insert(item)
+	}
+}
+
+func (set *shardedSet) CopyTo(dest Set) {
+	if dest == Set(set) {
+		return
+	}
+
+	copyItems(dest, set.ToSlice())
+}
+
+// Freeze wraps the set itself, reads on the view still take its locks.
+func (set *shardedSet) Freeze() Set {
+	return freeze(set)
+}
+
+func (set *shardedSet) Contains(i ...interface{}) bool {
+	for _, item := range i {
+		if !set.shard(item).Contains(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (set *shardedSet) Has(i interface{}) bool {
+	return set.shard(i).Has(i)
+}
+
+func (set *shardedSet) ContainsAll(i ...interface{}) bool {
+	return set.Contains(i...)
+}
+
+func (set *shardedSet) ContainsAny(i ...interface{}) bool {
+	for _, item := range i {
+		if set.shard(item).Contains(item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (set *shardedSet) ContainsBy(value interface{}, keyFunc func(interface{}) interface{}) bool {
+	unlock := set.rlock()
+	defer unlock()
+
+	key := keyFunc(value)
+	found := false
+	set.each(func(elem interface{}) bool {
+		found = keyFunc(elem) == key
+		return found
+	})
+	return found
+}
+
+func (set *shardedSet) Difference(other Set) Set {
+	other = nonNil(other)
+	return set.DifferenceAll(other)
+}
+
+func (set *shardedSet) DifferenceAll(others ...Set) Set {
+	others = nonNilAll(others)
+	os := make([]*shardedSet, len(others))
+	for i, other := range others {
+		os[i] = set.coerce(other)
+	}
+
+	unlock := rlockSharded(append(os, set)...)
+	defer unlock()
+
+	difference := set.derive()
+	set.each(func(elem interface{}) bool {
+		for _, o := range os {
+			if o.has(elem) {
+				return false
+			}
+		}
+		difference.insert(elem)
+		return false
+	})
+
+	return difference
+}
+
+func (set *shardedSet) Diff(other Set) (added Set, removed Set) {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockSharded(set, o)
+	defer unlock()
+
+	a, r := set.derive(), set.derive()
+	o.each(func(elem interface{}) bool {
+		if !set.has(elem) {
+			a.insert(elem)
+		}
+		return false
+	})
+	set.each(func(elem interface{}) bool {
+		if !o.has(elem) {
+			r.insert(elem)
+		}
+		return false
+	})
+
+	return a, r
+}
+
+func (set *shardedSet) Merge(other Set) (union Set, common Set) {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockSharded(set, o)
+	defer unlock()
+
+	u, c := set.derive(), set.derive()
+	set.each(func(elem interface{}) bool {
+		u.insert(elem)
+		if o.has(elem) {
+			c.insert(elem)
+		}
+		return false
+	})
+	o.each(func(elem interface{}) bool {
+		u.insert(elem)
+		return false
+	})
+
+	return u, c
+}
+
+func (set *shardedSet) Equal(other Set) bool {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockSharded(set, o)
+	defer unlock()
+
+	return set.equal(o)
+}
+
+func (set *shardedSet) EqualFunc(other Set, eq func(a, b interface{}) bool) bool {
+	other = nonNil(other)
+	return equalFunc(set.ToSlice(), other.ToSlice(), eq)
+}
+
+func (set *shardedSet) EqualUsing(other Set, eq func(a, b interface{}) bool) bool {
+	other = nonNil(other)
+	return equalUsing(set.ToSlice(), other.ToSlice(), eq)
+}
+
+func (set *shardedSet) equal(o *shardedSet) bool {
+	return set.size() == o.size() && set.subset(o)
+}
+
+func (set *shardedSet) subset(o *shardedSet) bool {
+	if set.size() > o.size() {
+		return false
+	}
+
+	isSubset := true
+	set.each(func(elem interface{}) bool {
+		isSubset = o.has(elem)
+		return !isSubset
+	})
+	return isSubset
+}
+
+func (set *shardedSet) Intersect(other Set) Set {
+	other = nonNil(other)
+	return set.IntersectAll(other)
+}
+
+func (set *shardedSet) IntersectAll(others ...Set) Set {
+	others = nonNilAll(others)
+	sets := make([]*shardedSet, 0, len(others)+1)
+	sets = append(sets, set)
+	for _, other := range others {
+		sets = append(sets, set.coerce(other))
+	}
+
+	unlock := rlockSharded(sets...)
+	defer unlock()
+
+	// Probe the larger sets with the elements of the smallest one.
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].size() < sets[j].size()
+	})
+
+	intersection := set.derive()
+	sets[0].each(func(elem interface{}) bool {
+		for _, o := range sets[1:] {
+			if !o.has(elem) {
+				return false
+			}
+		}
+		intersection.insert(elem)
+		return false
+	})
+
+	return intersection
+}
+
+func (set *shardedSet) IsProperSubset(other Set) bool {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockSharded(set, o)
+	defer unlock()
+
+	return set.subset(o) && !set.equal(o)
+}
+
+func (set *shardedSet) IsProperSuperset(other Set) bool {
+	return nonNil(other).IsProperSubset(set)
+}
+
+func (set *shardedSet) IsSubset(other Set) bool {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockSharded(set, o)
+	defer unlock()
+
+	return set.subset(o)
+}
+
+func (set *shardedSet) IsSuperset(other Set) bool {
+	return nonNil(other).IsSubset(set)
+}
+
+// Intersects coerces other before taking any lock, probing it through its
+// interface under the lock of the receiver could lock both sets in either
+// order.
+func (set *shardedSet) Intersects(other Set) bool {
+	o := set.coerce(nonNil(other))
+
+	unlock := rlockSharded(set, o)
+	defer unlock()
+
+	small, large := set, o
+	if small.size() > large.size() {
+		small, large = large, small
+	}
+
+	found := false
+	small.each(func(elem interface{}) bool {
+		found = large.has(elem)
+		return found
+	})
+	return found
+}
+
+func (set *shardedSet) IntersectCardinality(other Set) int {
+	o := set.coerce(nonNil(other))
+
+	unlock := rlockSharded(set, o)
+	defer unlock()
+
+	return set.intersectSize(o)
+}
+
+// intersectSize returns how many elements set and other have in common,
+// iterating the smaller of both, callers must hold the shard locks.
+func (set *shardedSet) intersectSize(other *shardedSet) int {
+	small, large := set, other
+	if small.size() > large.size() {
+		small, large = large, small
+	}
+
+	n := 0
+	small.each(func(elem interface{}) bool {
+		if large.has(elem) {
+			n++
+		}
+		return false
+	})
+	return n
+}
+
+func (set *shardedSet) UnionCardinality(other Set) int {
+	o := set.coerce(nonNil(other))
+
+	unlock := rlockSharded(set, o)
+	defer unlock()
+
+	return set.size() + o.size() - set.intersectSize(o)
+}
+
+func (set *shardedSet) IsDisjoint(other Set) bool {
+	other = nonNil(other)
+	return !set.Intersects(other)
+}
+
+func (set *shardedSet) JaccardSimilarity(other Set) float64 {
+	other = nonNil(other)
+	return jaccard(set.overlap(other))
+}
+
+func (set *shardedSet) OverlapCoefficient(other Set) float64 {
+	other = nonNil(other)
+	return overlapCoefficient(set.overlap(other))
+}
+
+// overlap is threadUnsafeSet.overlap for sharded sets, other is coerced
+// before taking any lock.
+func (set *shardedSet) overlap(other Set) (common, size, otherSize int) {
+	o := set.coerce(other)
+
+	unlock := rlockSharded(set, o)
+	defer unlock()
+
+	small, large := set, o
+	if small.size() > large.size() {
+		small, large = large, small
+	}
+	small.each(func(elem interface{}) bool {
+		if large.has(elem) {
+			common++
+		}
+		return false
+	})
+	return common, set.size(), o.size()
+}
+
+func (set *shardedSet) Each(callback func(interface{}) bool) {
+	unlock := set.rlock()
+	defer unlock()
+
+	set.each(callback)
+}
+
+func (set *shardedSet) ForEach(callback func(interface{})) {
+	set.Each(func(elem interface{}) bool {
+		callback(elem)
+		return false
+	})
+}
+
+func (set *shardedSet) ForEachUntil(callback func(interface{}) bool) {
+	set.Each(func(elem interface{}) bool {
+		return !callback(elem)
+	})
+}
+
+func (set *shardedSet) IterateIndexed(callback func(index int, elem interface{}) bool) {
+	index := 0
+	set.Each(func(elem interface{}) bool {
+		stop := !callback(index, elem)
+		index++
+		return stop
+	})
+}
+
+func (set *shardedSet) EachParallel(workers int, callback func(interface{})) {
+	eachParallel(set.ToSlice(), workers, func(elem interface{}) error {
+		callback(elem)
+		return nil
+	})
+}
+
+func (set *shardedSet) EachParallelErr(workers int, callback func(interface{}) error) []error {
+	return eachParallel(set.ToSlice(), workers, callback)
+}
+
+func (set *shardedSet) Map(transform func(interface{}) interface{}) Set {
+	unlock := set.rlock()
+	defer unlock()
+
+	mapped := set.derive()
+	set.each(func(elem interface{}) bool {
+		mapped.insert(transform(elem))
+		return false
+	})
+
+	return mapped
+}
+
+func (set *shardedSet) Partition(predicate func(interface{}) bool) (Set, Set) {
+	unlock := set.rlock()
+	defer unlock()
+
+	matched, rest := set.derive(), set.derive()
+	set.each(func(elem interface{}) bool {
+		if predicate(elem) {
+			matched.insert(elem)
+		} else {
+			rest.insert(elem)
+		}
+		return false
+	})
+
+	return matched, rest
+}
+
+func (set *shardedSet) GroupBy(keyFunc func(interface{}) interface{}) map[interface{}]Set {
+	unlock := set.rlock()
+	defer unlock()
+
+	groups := make(map[interface{}]*shardedSet)
+	set.each(func(elem interface{}) bool {
+		key := keyFunc(elem)
+		group, found := groups[key]
+		if !found {
+			group = set.derive()
+			groups[key] = group
+		}
+		group.insert(elem)
+		return false
+	})
+
+	result := make(map[interface{}]Set, len(groups))
+	for key, group := range groups {
+		result[key] = group
+	}
+	return result
+}
+
+func (set *shardedSet) Reduce(initial interface{}, accumulate func(acc, elem interface{}) interface{}) interface{} {
+	unlock := set.rlock()
+	defer unlock()
+
+	acc := initial
+	set.each(func(elem interface{}) bool {
+		acc = accumulate(acc, elem)
+		return false
+	})
+
+	return acc
+}
+
+func (set *shardedSet) Any(predicate func(interface{}) bool) bool {
+	unlock := set.rlock()
+	defer unlock()
+
+	found := false
+	set.each(func(elem interface{}) bool {
+		found = predicate(elem)
+		return found
+	})
+
+	return found
+}
+
+func (set *shardedSet) All(predicate func(interface{}) bool) bool {
+	unlock := set.rlock()
+	defer unlock()
+
+	all := true
+	set.each(func(elem interface{}) bool {
+		all = predicate(elem)
+		return !all
+	})
+
+	return all
+}
+
+func (set *shardedSet) Min(less func(a, b interface{}) bool) (interface{}, bool) {
+	unlock := set.rlock()
+	defer unlock()
+
+	return minimum(less, set.each)
+}
+
+func (set *shardedSet) Max(less func(a, b interface{}) bool) (interface{}, bool) {
+	unlock := set.rlock()
+	defer unlock()
+
+	return minimum(func(a, b interface{}) bool { return less(b, a) }, set.each)
+}
+
+func (set *shardedSet) Count(predicate func(interface{}) bool) int {
+	unlock := set.rlock()
+	defer unlock()
+
+	count := 0
+	set.each(func(elem interface{}) bool {
+		if predicate(elem) {
+			count++
+		}
+		return false
+	})
+
+	return count
+}
+
+func (set *shardedSet) Iter() <-chan interface{} {
+	ch := make(chan interface{}, iterBufferSize)
+	go func() {
+		unlock := set.rlock()
+		set.each(func(elem interface{}) bool {
+			ch <- elem
+			return false
+		})
+		close(ch)
+		unlock()
+	}()
+
+	return ch
+}
+
+func (set *shardedSet) IterContext(ctx context.Context) <-chan interface{} {
+	ch := make(chan interface{}, iterBufferSize)
+	go func() {
+		unlock := set.rlock()
+		set.each(func(elem interface{}) bool {
+			select {
+			case <-ctx.Done():
+				return true
+			case ch <- elem:
+				return false
+			}
+		})
+		close(ch)
+		unlock()
+	}()
+
+	return ch
+}
+
+func (set *shardedSet) IterSnapshot() <-chan interface{} {
+	return iterSlice(set.Elements())
+}
+
+func (set *shardedSet) IterSorted(less func(a, b interface{}) bool) <-chan interface{} {
+	return iterSlice(set.ToSortedSlice(less))
+}
+
+func (set *shardedSet) Tee() (<-chan interface{}, <-chan interface{}) {
+	return teeSlice(set.Elements())
+}
+
+func (set *shardedSet) Iterator() *Iterator {
+	iterator, ch, stopCh := newIterator()
+
+	go func() {
+		unlock := set.rlock()
+		set.each(func(elem interface{}) bool {
+			select {
+			case <-stopCh:
+				return true
+			case ch <- elem:
+				return false
+			}
+		})
+		close(ch)
+		unlock()
+	}()
+
+	return iterator
+}
+
+func (set *shardedSet) Elements() []interface{} {
+	return set.ToSlice()
+}
+
+func (set *shardedSet) Remove(i interface{}) {
+	set.shard(i).Remove(i)
+}
+
+func (set *shardedSet) RemoveAll(i ...interface{}) int {
+	removed := 0
+	for shard, items := range set.group(i) {
+		removed += set.shards[shard].RemoveAll(items...)
+	}
+
+	return removed
+}
+
+func (set *shardedSet) RetainAll(other Set) int {
+	other = nonNil(other)
+	o := set.coerce(other)
+	if o == set {
+		return 0
+	}
+
+	var unlockSet, unlockOther func()
+	if lessAddress(&set.shards[0].mutex, &o.shards[0].mutex) {
+		unlockSet = set.lock()
+		unlockOther = o.rlock()
+	} else {
+		unlockOther = o.rlock()
+		unlockSet = set.lock()
+	}
+	defer unlockSet()
+	defer unlockOther()
+
+	removed := 0
+	for i := range set.shards {
+		for elem := range set.shards[i].objects {
+			if !o.has(elem) {
+				delete(set.shards[i].objects, elem)
+				removed++
+			}
+		}
+	}
+
+	return removed
+}
+
+func (set *shardedSet) AddSet(other Set) int {
+	other = nonNil(other)
+	o := set.coerce(other)
+	if o == set {
+		return 0
+	}
+
+	var unlockSet, unlockOther func()
+	if lessAddress(&set.shards[0].mutex, &o.shards[0].mutex) {
+		unlockSet = set.lock()
+		unlockOther = o.rlock()
+	} else {
+		unlockOther = o.rlock()
+		unlockSet = set.lock()
+	}
+	defer unlockSet()
+	defer unlockOther()
+
+	added := 0
+	o.each(func(elem interface{}) bool {
+		if set.insert(elem) {
+			added++
+		}
+		return false
+	})
+
+	return added
+}
+
+func (set *shardedSet) SubtractSet(other Set) int {
+	other = nonNil(other)
+	o := set.coerce(other)
+	if o == set {
+		unlock := set.lock()
+		defer unlock()
+
+		removed := set.size()
+		for i := range set.shards {
+			set.shards[i].objects = newThreadUnsafeSet()
+		}
+		return removed
+	}
+
+	var unlockSet, unlockOther func()
+	if lessAddress(&set.shards[0].mutex, &o.shards[0].mutex) {
+		unlockSet = set.lock()
+		unlockOther = o.rlock()
+	} else {
+		unlockOther = o.rlock()
+		unlockSet = set.lock()
+	}
+	defer unlockSet()
+	defer unlockOther()
+
+	removed := 0
+	for i := range set.shards {
+		for elem := range set.shards[i].objects {
+			if o.has(elem) {
+				delete(set.shards[i].objects, elem)
+				removed++
+			}
+		}
+	}
+
+	return removed
+}
+
+func (set *shardedSet) String() string {
+	unlock := set.rlock()
+	defer unlock()
+
+	flat := set.flatten()
+	return flat.String()
+}
+
+func (set *shardedSet) Hash() uint64 {
+	unlock := set.rlock()
+	defer unlock()
+
+	var h uint64
+	set.each(func(elem interface{}) bool {
+		h ^= mixHash(hashElement(elem))
+		return false
+	})
+	return h
+}
+
+func (set *shardedSet) StringSorted() string {
+	unlock := set.rlock()
+	defer unlock()
+
+	flat := set.flatten()
+	return flat.StringSorted()
+}
+
+func (set *shardedSet) SymmetricDifference(other Set) Set {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockSharded(set, o)
+	defer unlock()
+
+	diff := set.derive()
+	set.each(func(elem interface{}) bool {
+		if !o.has(elem) {
+			diff.insert(elem)
+		}
+		return false
+	})
+	o.each(func(elem interface{}) bool {
+		if !set.has(elem) {
+			diff.insert(elem)
+		}
+		return false
+	})
+
+	return diff
+}
+
+func (set *shardedSet) Union(other Set) Set {
+	other = nonNil(other)
+	return set.UnionAll(other)
+}
+
+func (set *shardedSet) UnionAll(others ...Set) Set {
+	others = nonNilAll(others)
+	sets := make([]*shardedSet, 0, len(others)+1)
+	sets = append(sets, set)
+	for _, other := range others {
+		sets = append(sets, set.coerce(other))
+	}
+
+	unlock := rlockSharded(sets...)
+	defer unlock()
+
+	union := set.derive()
+	for _, s := range sets {
+		s.each(func(elem interface{}) bool {
+			union.insert(elem)
+			return false
+		})
+	}
+
+	return union
+}
+
+func (set *shardedSet) Pop() interface{} {
+	item, _ := set.TryPop()
+	return item
+}
+
+func (set *shardedSet) TryPop() (interface{}, bool) {
+	for i := range set.shards {
+		if item, ok := set.shards[i].TryPop(); ok {
+			return item, true
+		}
+	}
+
+	return nil, false
+}
+
+func (set *shardedSet) Peek() (interface{}, bool) {
+	for i := range set.shards {
+		if item, ok := set.shards[i].Peek(); ok {
+			return item, true
+		}
+	}
+
+	return nil, false
+}
+
+func (set *shardedSet) Sample(n int) Set {
+	return set.SampleWithRand(n, nil)
+}
+
+func (set *shardedSet) SampleWithRand(n int, r *rand.Rand) Set {
+	unlock := set.rlock()
+	defer unlock()
+
+	sample := set.derive()
+	for _, elem := range reservoir(n, r, set.each) {
+		sample.insert(elem)
+	}
+	return sample
+}
+
+func (set *shardedSet) PowerSet() Set {
+	unlock := set.rlock()
+	flat := set.flatten()
+	unlock()
+
+	powSet := set.derive()
+	for subset := range *flat.PowerSet().(*threadUnsafeSet) {
+		unsafeSubset := subset.(*threadUnsafeSet)
+		shardedSubset := set.derive()
+		for elem := range *unsafeSubset {
+			shardedSubset.insert(elem)
+		}
+		powSet.insert(shardedSubset)
+	}
+
+	return powSet
+}
+
+func (set *shardedSet) PowerSetIterator() *Iterator {
+	return powerSetIterator(set.ToSlice(), func(items []interface{}) Set {
+		subset := set.derive()
+		for _, item := range items {
+			subset.insert(item)
+		}
+		return subset
+	})
+}
+
+func (set *shardedSet) CartesianProduct(other Set) Set {
+	other = nonNil(other)
+	o := set.coerce(other)
+
+	unlock := rlockSharded(set, o)
+	defer unlock()
+
+	cartProduct := set.derive()
+	set.each(func(i interface{}) bool {
+		o.each(func(j interface{}) bool {
+			cartProduct.insert(OrderedPair{First: i, Second: j})
+			return false
+		})
+		return false
+	})
+
+	return cartProduct
+}
+
+func (set *shardedSet) CartesianProductIterator(other Set) *PairIterator {
+	other = nonNil(other)
+	return cartesianProductIterator(set.ToSlice(), other.ToSlice())
+}
+
+func (set *shardedSet) ToSlice() []interface{} {
+	unlock := set.rlock()
+	defer unlock()
+
+	flat := set.flatten()
+	return flat.ToSlice()
+}
+
+func (set *shardedSet) Chunk(size int) [][]interface{} {
+	return chunk(set.ToSlice(), size)
+}
+
+func (set *shardedSet) ToMap() map[interface{}]struct{} {
+	unlock := set.rlock()
+	defer unlock()
+
+	m := make(map[interface{}]struct{}, set.size())
+	set.each(func(elem interface{}) bool {
+		m[elem] = struct{}{}
+		return false
+	})
+	return m
+}
+
+func (set *shardedSet) Drain() []interface{} {
+	unlock := set.lock()
+	defer unlock()
+
+	items := make([]interface{}, 0, set.size())
+	for i := range set.shards {
+		items = append(items, set.shards[i].objects.Drain()...)
+	}
+
+	return items
+}
+
+func (set *shardedSet) ToSortedSlice(less func(a, b interface{}) bool) []interface{} {
+	unlock := set.rlock()
+	flat := set.flatten()
+	unlock()
+
+	return flat.ToSortedSlice(less)
+}
+
+func (set *shardedSet) Strings() []string {
+	unlock := set.rlock()
+	defer unlock()
+
+	flat := set.flatten()
+	return flat.Strings()
+}
+
+func (set *shardedSet) StringsWithConversion() []string {
+	unlock := set.rlock()
+	defer unlock()
+
+	flat := set.flatten()
+	return flat.StringsWithConversion()
+}
+
+func (set *shardedSet) Ints() []int {
+	unlock := set.rlock()
+	defer unlock()
+
+	flat := set.flatten()
+	return flat.Ints()
+}
+
+func (set *shardedSet) Float64s() []float64 {
+	unlock := set.rlock()
+	defer unlock()
+
+	flat := set.flatten()
+	return flat.Float64s()
+}
+
+func (set *shardedSet) MarshalJSON() ([]byte, error) {
+	unlock := set.rlock()
+	defer unlock()
+
+	flat := set.flatten()
+	return flat.MarshalJSON()
+}
+
+func (set *shardedSet) MarshalJSONSorted() ([]byte, error) {
+	unlock := set.rlock()
+	defer unlock()
+
+	flat := set.flatten()
+	return flat.MarshalJSONSorted()
+}
+
+func (set *shardedSet) UnmarshalJSON(p []byte) error {
+	items, err := unmarshalJSONElements(p)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *shardedSet) GobEncode() ([]byte, error) {
+	return gobEncodeElements(set.ToSlice())
+}
+
+func (set *shardedSet) UnmarshalJSONWith(b []byte, convertNumber func(json.Number) interface{}) error {
+	items, err := unmarshalJSONElementsWith(b, convertNumber)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *shardedSet) UnmarshalJSONInto(b []byte, prototype func() interface{}) error {
+	items, err := unmarshalJSONElementsInto(b, prototype)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *shardedSet) GobDecode(b []byte) error {
+	items, err := gobDecodeElements(b)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *shardedSet) MarshalBinary() ([]byte, error) {
+	return marshalBinaryElements(set.ToSlice())
+}
+
+func (set *shardedSet) UnmarshalBinary(data []byte) error {
+	items, err := unmarshalBinaryElements(data)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *shardedSet) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalXMLElements(e, start, set.ToSlice())
+}
+
+func (set *shardedSet) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	items, err := unmarshalXMLElements(d, start)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *shardedSet) MarshalText() ([]byte, error) {
+	return marshalTextElements(set.StringsWithConversion()), nil
+}
+
+func (set *shardedSet) UnmarshalText(text []byte) error {
+	items, err := unmarshalTextElements(text)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *shardedSet) WriteCSV(w io.Writer) error {
+	return writeCSVElements(w, set.StringsWithConversion())
+}
+
+func (set *shardedSet) ReadCSV(r io.Reader) error {
+	items, err := readCSVElements(r)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
@@ -0,0 +1,192 @@
+package mapset
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func makeShardedSet(ints []int) Set {
+	set := NewShardedSet(4)
+	for _, i := range ints {
+		set.Add(i)
+	}
+	return set
+}
+
+func Test_ShardedSetBasics(t *testing.T) {
+	a := NewShardedSet(4)
+	if a.Cardinality() != 0 {
+		t.Error("NewShardedSet should start out as an empty set")
+	}
+
+	if added := a.AddAll(1, 2, 3, 3, "a", 1.5); added != 5 {
+		t.Errorf("AddAll should report 5 new items added, got %d", added)
+	}
+
+	if !a.Contains(1, 2, 3, "a", 1.5) || a.Contains(4) {
+		t.Error("ShardedSet has unexpected members")
+	}
+
+	if removed := a.RemoveAll(1, 4); removed != 1 {
+		t.Errorf("RemoveAll should report 1 item removed, got %d", removed)
+	}
+
+	a.Remove("a")
+	if a.Cardinality() != 3 {
+		t.Errorf("ShardedSet should have 3 elements, got %d", a.Cardinality())
+	}
+
+	if item, ok := a.TryPop(); !ok || a.Contains(item) {
+		t.Error("TryPop should remove an element")
+	}
+
+	a.Clear()
+	if _, ok := a.TryPop(); ok || a.Cardinality() != 0 {
+		t.Error("Clear should leave an empty set")
+	}
+}
+
+func Test_ShardedSetOperations(t *testing.T) {
+	a := makeShardedSet([]int{1, 2, 3})
+	b := makeShardedSet([]int{3, 4})
+
+	assertEqual(a.Union(b), makeShardedSet([]int{1, 2, 3, 4}), t)
+	assertEqual(a.Intersect(b), makeShardedSet([]int{3}), t)
+	assertEqual(a.Difference(b), makeShardedSet([]int{1, 2}), t)
+	assertEqual(a.SymmetricDifference(b), makeShardedSet([]int{1, 2, 4}), t)
+	assertEqual(a.Clone(), a, t)
+
+	if !makeShardedSet([]int{1, 2}).IsProperSubset(a) || !a.IsSuperset(makeShardedSet([]int{3})) {
+		t.Error("subset relations are wrong")
+	}
+
+	if a.Equal(b) || !a.Equal(makeShardedSet([]int{3, 2, 1})) {
+		t.Error("Equal is wrong")
+	}
+
+	if removed := a.RetainAll(b); removed != 2 {
+		t.Errorf("RetainAll should report 2 items removed, got %d", removed)
+	}
+	assertEqual(a, makeShardedSet([]int{3}), t)
+
+	if a.CartesianProduct(b).Cardinality() != 2 {
+		t.Error("CartesianProduct should have 2 pairs")
+	}
+
+	if a.PowerSet().Cardinality() != 2 {
+		t.Error("PowerSet of a single element set should have 2 subsets")
+	}
+}
+
+func Test_ShardedSetReadOnly(t *testing.T) {
+	a := makeShardedSet([]int{1, 2, 3, 4})
+
+	if total := a.Reduce(0, sum); total != 10 {
+		t.Errorf("Reduce should sum the set to 10, got %v", total)
+	}
+
+	if !a.Any(isEven) || a.All(isEven) {
+		t.Error("Any or All is wrong")
+	}
+
+	assertEqual(a.Map(func(i interface{}) interface{} { return i.(int) % 2 }), makeShardedSet([]int{0, 1}), t)
+
+	sorted := a.ToSortedSlice(lessInt)
+	if len(sorted) != 4 || sorted[0] != 1 || sorted[3] != 4 {
+		t.Errorf("ToSortedSlice should return [1 2 3 4], got %v", sorted)
+	}
+
+	count := 0
+	for range a.Iter() {
+		count++
+	}
+	if count != 4 {
+		t.Errorf("Iter should yield 4 elements, got %d", count)
+	}
+
+	it := a.Iterator()
+	<-it.C
+	it.Stop()
+	a.Add(5)
+}
+
+func Test_ShardedSetNegativeZero(t *testing.T) {
+	a := NewShardedSet(8)
+	a.Add(0.0)
+
+	if !a.Contains(math.Copysign(0, -1)) {
+		t.Error("-0 and +0 are equal and should be found in the same shard")
+	}
+}
+
+func Test_ShardedSetCompositeNegativeZero(t *testing.T) {
+	type point struct {
+		x    float64
+		tag  interface{}
+		_    int
+		pair [2]float64
+	}
+	negZero := math.Copysign(0, -1)
+
+	for _, elems := range [][2]interface{}{
+		{point{x: 0, tag: 0.0, pair: [2]float64{1, 0}}, point{x: negZero, tag: negZero, pair: [2]float64{1, negZero}}},
+		{[2]float64{0, 1}, [2]float64{negZero, 1}},
+		{complex(0, 0), complex(negZero, negZero)},
+	} {
+		a := NewShardedSet(64)
+		a.Add(elems[0])
+
+		if !a.Contains(elems[1]) {
+			t.Errorf("%#v and %#v are equal and should be found in the same shard", elems[0], elems[1])
+		}
+	}
+}
+
+func Test_ShardedSetConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s, ss := NewShardedSet(8), NewShardedSet(8)
+
+	var wg sync.WaitGroup
+	wg.Add(4 * N)
+	for i := 0; i < N; i++ {
+		go func(i int) {
+			s.Add(i)
+			wg.Done()
+		}(i)
+		go func(i int) {
+			ss.AddAll(i, i+N)
+			wg.Done()
+		}(i)
+		go func() {
+			s.Union(ss)
+			wg.Done()
+		}()
+		go func() {
+			ss.RetainAll(s)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	if s.Cardinality() != N {
+		t.Errorf("ShardedSet should have %d elements, got %d", N, s.Cardinality())
+	}
+}
+
+func Test_ShardedSetPointerElements(t *testing.T) {
+	type point struct{ x, y int }
+
+	a := NewShardedSet(16)
+	p := &point{1, 2}
+	a.Add(p)
+
+	// Pointers are equal by address, changing what they point to must
+	// not move them to another shard.
+	p.x = 100
+	if !a.Contains(p) || a.Add(p) {
+		t.Error("a pointer element should still be found after its target changed")
+	}
+}
@@ -0,0 +1,425 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// StringSet is a set of strings. It mirrors Set, but stores its elements
+// in a map[string]struct{}, so it needs no type assertions to read the
+// elements back and cannot accidentally hold an element of another type.
+type StringSet interface {
+	// Adds an element to the set. Returns whether
+	// the item was added.
+	Add(i string) bool
+
+	// Adds all of the given elements to the set.
+	// Returns the number of elements that were
+	// actually added, so duplicates are not counted.
+	AddAll(i ...string) int
+
+	// Returns the number of elements in the set.
+	Cardinality() int
+
+	// Removes all elements from the set, leaving
+	// the empty set.
+	Clear()
+
+	// Returns a clone of the set, duplicating all
+	// keys.
+	Clone() StringSet
+
+	// Returns whether the given items
+	// are all in the set.
+	Contains(i ...string) bool
+
+	// Returns the difference between this set
+	// and other. The returned set will contain
+	// all elements of this set that are not also
+	// elements of other.
+	Difference(other StringSet) StringSet
+
+	// Determines if two sets hold the same
+	// elements.
+	Equal(other StringSet) bool
+
+	// Returns a new set containing only the elements
+	// that exist only in both sets.
+	Intersect(other StringSet) StringSet
+
+	// Determines if every element in this set is in
+	// the other set but the two sets are not equal.
+	IsProperSubset(other StringSet) bool
+
+	// Determines if every element in the other set
+	// is in this set but the two sets are not
+	// equal.
+	IsProperSuperset(other StringSet) bool
+
+	// Determines if every element in this set is in
+	// the other set.
+	IsSubset(other StringSet) bool
+
+	// Determines if every element in the other set
+	// is in this set.
+	IsSuperset(other StringSet) bool
+
+	// Iterates over elements and executes the passed func against each element.
+	// If passed func returns true, stop iteration at the time.
+	Each(func(string) bool)
+
+	// Remove a single element from the set.
+	Remove(i string)
+
+	// Removes all of the given elements from the set.
+	// Returns the number of elements that were
+	// actually removed.
+	RemoveAll(i ...string) int
+
+	// Provides a convenient string representation
+	// of the current state of the set.
+	String() string
+
+	// Returns a new set with all elements which are
+	// in either this set or the other set but not in both.
+	SymmetricDifference(other StringSet) StringSet
+
+	// Returns a new set with all elements in both sets.
+	Union(other StringSet) StringSet
+
+	// Removes and returns an arbitrary item from the
+	// set. The boolean reports whether an item was
+	// removed, it is false when the set is empty.
+	Pop() (string, bool)
+
+	// Returns the members of the set as a slice.
+	ToSlice() []string
+
+	// Returns a Set holding the same elements.
+	ToSet() Set
+}
+
+// stringSet is a thread-safe StringSet, the string counterpart of threadSafeSet.
+type stringSet struct {
+	items map[string]struct{}
+	mutex sync.RWMutex
+}
+
+// NewStringSet creates and returns a reference to a set holding the given
+// strings. Operations on the resulting set are thread-safe.
+func NewStringSet(i ...string) StringSet {
+	set := newStringSet(len(i))
+	for _, item := range i {
+		set.items[item] = struct{}{}
+	}
+	return set
+}
+
+// NewStringSetFromSlice creates and returns a reference to a set from an
+// existing slice of strings. Operations on the resulting set are thread-safe.
+func NewStringSetFromSlice(s []string) StringSet {
+	return NewStringSet(s...)
+}
+
+func newStringSet(size int) *stringSet {
+	return &stringSet{items: make(map[string]struct{}, size)}
+}
+
+// rlockWith read-locks the set and other in the order used by rlockAll,
+// and returns a func releasing them.
+func (set *stringSet) rlockWith(other StringSet) (*stringSet, func()) {
+	o := set.coerce(other)
+	return o, rlockAll(&set.mutex, &o.mutex)
+}
+
+// coerce returns other if it is an *stringSet, and otherwise a private copy
+// of it, so that binary operations accept any StringSet.
+func (set *stringSet) coerce(other StringSet) *stringSet {
+	if o, ok := other.(*stringSet); ok {
+		return o
+	}
+
+	o := newStringSet(other.Cardinality())
+	other.Each(func(elem string) bool {
+		o.items[elem] = struct{}{}
+		return false
+	})
+	return o
+}
+
+// isSubset reports whether every element of set is in other, both must be
+// read-locked.
+func (set *stringSet) isSubset(other *stringSet) bool {
+	if len(set.items) > len(other.items) {
+		return false
+	}
+	for elem := range set.items {
+		if _, found := other.items[elem]; !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *stringSet) Add(i string) bool {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	if _, found := set.items[i]; found {
+		return false
+	}
+	set.items[i] = struct{}{}
+	return true
+}
+
+func (set *stringSet) AddAll(i ...string) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	added := 0
+	for _, item := range i {
+		if _, found := set.items[item]; !found {
+			set.items[item] = struct{}{}
+			added++
+		}
+	}
+	return added
+}
+
+func (set *stringSet) Cardinality() int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return len(set.items)
+}
+
+func (set *stringSet) Clear() {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	set.items = make(map[string]struct{})
+}
+
+func (set *stringSet) Clone() StringSet {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	clone := newStringSet(len(set.items))
+	for elem := range set.items {
+		clone.items[elem] = struct{}{}
+	}
+	return clone
+}
+
+func (set *stringSet) Contains(i ...string) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	for _, item := range i {
+		if _, found := set.items[item]; !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *stringSet) Difference(other StringSet) StringSet {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	difference := newStringSet(0)
+	for elem := range set.items {
+		if _, found := o.items[elem]; !found {
+			difference.items[elem] = struct{}{}
+		}
+	}
+	return difference
+}
+
+func (set *stringSet) Equal(other StringSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return len(set.items) == len(o.items) && set.isSubset(o)
+}
+
+func (set *stringSet) Intersect(other StringSet) StringSet {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	smaller, larger := set, o
+	if len(smaller.items) > len(larger.items) {
+		smaller, larger = larger, smaller
+	}
+
+	intersection := newStringSet(0)
+	for elem := range smaller.items {
+		if _, found := larger.items[elem]; found {
+			intersection.items[elem] = struct{}{}
+		}
+	}
+	return intersection
+}
+
+func (set *stringSet) IsProperSubset(other StringSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return len(set.items) < len(o.items) && set.isSubset(o)
+}
+
+func (set *stringSet) IsProperSuperset(other StringSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return len(o.items) < len(set.items) && o.isSubset(set)
+}
+
+func (set *stringSet) IsSubset(other StringSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.isSubset(o)
+}
+
+func (set *stringSet) IsSuperset(other StringSet) bool {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return o.isSubset(set)
+}
+
+func (set *stringSet) Each(callback func(string) bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	for elem := range set.items {
+		if callback(elem) {
+			break
+		}
+	}
+}
+
+func (set *stringSet) Remove(i string) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	delete(set.items, i)
+}
+
+func (set *stringSet) RemoveAll(i ...string) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	removed := 0
+	for _, item := range i {
+		if _, found := set.items[item]; found {
+			delete(set.items, item)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (set *stringSet) String() string {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	items := make([]string, 0, len(set.items))
+	for elem := range set.items {
+		items = append(items, elem)
+	}
+
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+func (set *stringSet) SymmetricDifference(other StringSet) StringSet {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	sd := newStringSet(0)
+	for elem := range set.items {
+		if _, found := o.items[elem]; !found {
+			sd.items[elem] = struct{}{}
+		}
+	}
+	for elem := range o.items {
+		if _, found := set.items[elem]; !found {
+			sd.items[elem] = struct{}{}
+		}
+	}
+	return sd
+}
+
+func (set *stringSet) Union(other StringSet) StringSet {
+	o, unlock := set.rlockWith(other)
+	defer unlock()
+
+	union := newStringSet(len(set.items) + len(o.items))
+	for elem := range set.items {
+		union.items[elem] = struct{}{}
+	}
+	for elem := range o.items {
+		union.items[elem] = struct{}{}
+	}
+	return union
+}
+
+func (set *stringSet) Pop() (string, bool) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	for elem := range set.items {
+		delete(set.items, elem)
+		return elem, true
+	}
+	return "", false
+}
+
+func (set *stringSet) ToSlice() []string {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	s := make([]string, 0, len(set.items))
+	for elem := range set.items {
+		s = append(s, elem)
+	}
+	return s
+}
+
+func (set *stringSet) ToSet() Set {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	s := newThreadSafeSetWithSize(len(set.items))
+	for elem := range set.items {
+		s.objects[elem] = struct{}{}
+	}
+	return &s
+}
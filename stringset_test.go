@@ -0,0 +1,68 @@
+package mapset
+
+import (
+	"sort"
+	"testing"
+)
+
+func Test_StringSetBasics(t *testing.T) {
+	s := NewStringSet("a", "b")
+	if s.Add("b") || !s.Add("c") {
+		t.Error("Add should only report new elements")
+	}
+	if n := s.AddAll("c", "d", "e"); n != 2 {
+		t.Errorf("AddAll should add 2 new elements, got %d", n)
+	}
+	if s.Cardinality() != 5 || !s.Contains("a", "e") || s.Contains("f") {
+		t.Errorf("unexpected elements in %v", s)
+	}
+
+	s.Remove("e")
+	if n := s.RemoveAll("d", "e"); n != 1 {
+		t.Errorf("RemoveAll should remove 1 element, got %d", n)
+	}
+
+	slice := s.ToSlice()
+	sort.Strings(slice)
+	if len(slice) != 3 || slice[0] != "a" || slice[1] != "b" || slice[2] != "c" {
+		t.Errorf("ToSlice should return [a b c], got %v", slice)
+	}
+
+	if elem, ok := NewStringSet("x").Pop(); !ok || elem != "x" {
+		t.Errorf("Pop should return x, got %q, %v", elem, ok)
+	}
+	if _, ok := NewStringSet().Pop(); ok {
+		t.Error("Pop on the empty set should report false")
+	}
+
+	if str := NewStringSetFromSlice([]string{"x"}).String(); str != "Set{x}" {
+		t.Errorf("unexpected string representation %q", str)
+	}
+}
+
+func Test_StringSetOperations(t *testing.T) {
+	a := NewStringSet("a", "b", "c")
+	b := NewStringSet("b", "c", "d")
+
+	if !a.Union(b).Equal(NewStringSet("a", "b", "c", "d")) {
+		t.Errorf("unexpected union %v", a.Union(b))
+	}
+	if !a.Intersect(b).Equal(NewStringSet("b", "c")) {
+		t.Errorf("unexpected intersection %v", a.Intersect(b))
+	}
+	if !a.Difference(b).Equal(NewStringSet("a")) {
+		t.Errorf("unexpected difference %v", a.Difference(b))
+	}
+	if !a.SymmetricDifference(b).Equal(NewStringSet("a", "d")) {
+		t.Errorf("unexpected symmetric difference %v", a.SymmetricDifference(b))
+	}
+
+	sub := NewStringSet("a", "b")
+	if !sub.IsProperSubset(a) || !a.IsProperSuperset(sub) || a.IsProperSubset(a) {
+		t.Error("{a, b} should be a proper subset of {a, b, c}")
+	}
+
+	if !a.ToSet().Equal(NewSetFromStrings([]string{"a", "b", "c"})) {
+		t.Errorf("ToSet should hold the same elements, got %v", a.ToSet())
+	}
+}
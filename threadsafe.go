@@ -25,7 +25,16 @@ SOFTWARE.
 
 package mapset
 
-import "sync"
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"unsafe"
+)
 
 type threadSafeSet struct {
 	objects threadUnsafeSet
@@ -36,6 +45,169 @@ func newThreadSafeSet() threadSafeSet {
 	return threadSafeSet{objects: newThreadUnsafeSet()}
 }
 
+func newThreadSafeSetWithSize(size int) threadSafeSet {
+	return threadSafeSet{objects: newThreadUnsafeSetWithSize(size)}
+}
+
+// lessAddress reports whether mutex a is stored at a lower address than
+// b. Operations that lock several sets acquire the locks in order of
+// increasing address, so two goroutines locking the same sets can never
+// wait on each other.
+func lessAddress(a, b *sync.RWMutex) bool {
+	return uintptr(unsafe.Pointer(a)) < uintptr(unsafe.Pointer(b))
+}
+
+// rlockAll read-locks each of the given mutexes once, in order of
+// increasing address, and returns a func that releases those locks.
+func rlockAll(mutexes ...*sync.RWMutex) func() {
+	sorted := make([]*sync.RWMutex, len(mutexes))
+	copy(sorted, mutexes)
+	sort.Slice(sorted, func(i, j int) bool {
+		return lessAddress(sorted[i], sorted[j])
+	})
+
+	locked := sorted[:0]
+	for i, m := range sorted {
+		if i > 0 && m == sorted[i-1] {
+			continue
+		}
+		m.RLock()
+		locked = append(locked, m)
+	}
+
+	return func() {
+		for _, m := range locked {
+			m.RUnlock()
+		}
+	}
+}
+
+// lockAll write-locks each of the given mutexes once, in the order used by
+// rlockAll, and returns a func that releases those locks.
+func lockAll(mutexes ...*sync.RWMutex) func() {
+	sorted := make([]*sync.RWMutex, len(mutexes))
+	copy(sorted, mutexes)
+	sort.Slice(sorted, func(i, j int) bool {
+		return lessAddress(sorted[i], sorted[j])
+	})
+
+	locked := sorted[:0]
+	for i, m := range sorted {
+		if i > 0 && m == sorted[i-1] {
+			continue
+		}
+		m.Lock()
+		locked = append(locked, m)
+	}
+
+	return func() {
+		for _, m := range locked {
+			m.Unlock()
+		}
+	}
+}
+
+// moveSide is how Move reaches an element in one of its sets: the mutex
+// guarding the element, nil for a thread-unsafe set, and the helpers
+// removing and adding it, which must be called with the mutex held.
+type moveSide struct {
+	mutex  *sync.RWMutex
+	remove func(elem interface{}) bool
+	insert func(elem interface{}) bool
+}
+
+// moveSideOf returns the moveSide of elem in set, or false for the
+// implementations Move cannot lock itself.
+func moveSideOf(set Set, elem interface{}) (moveSide, bool) {
+	unsafeSide := func(objects *threadUnsafeSet) moveSide {
+		return moveSide{
+			remove: func(elem interface{}) bool { return objects.RemoveAll(elem) == 1 },
+			insert: objects.Add,
+		}
+	}
+
+	switch s := set.(type) {
+	case *threadUnsafeSet:
+		return unsafeSide(s), true
+	case *threadSafeSet:
+		side := unsafeSide(&s.objects)
+		side.mutex = &s.mutex
+		return side, true
+	case *shardedSet:
+		shard := s.shard(elem)
+		side := unsafeSide(&shard.objects)
+		side.mutex = &shard.mutex
+		return side, true
+	case *orderedSet:
+		return moveSide{mutex: &s.mutex, remove: s.remove, insert: s.insert}, true
+	}
+	return moveSide{}, false
+}
+
+// rlockSets read-locks each of the given sets once, see rlockAll.
+func rlockSets(sets ...*threadSafeSet) func() {
+	mutexes := make([]*sync.RWMutex, len(sets))
+	for i, s := range sets {
+		mutexes[i] = &s.mutex
+	}
+	return rlockAll(mutexes...)
+}
+
+// unsafeObjects returns the thread-safe sets among others, whose locks
+// must be held, along with the sets to hand to the thread-unsafe
+// implementation: the underlying objects of thread-safe sets, and a
+// snapshot of any other implementation, see snapshotOf, taken before any
+// lock is held like the coerce of sharded and ordered sets.
+func unsafeObjects(others []Set) ([]*threadSafeSet, []Set) {
+	var safe []*threadSafeSet
+	objects := make([]Set, len(others))
+	for i, other := range others {
+		if o, ok := other.(*threadSafeSet); ok {
+			safe = append(safe, o)
+			objects[i] = &o.objects
+		} else {
+			objects[i] = snapshotOf(other)
+		}
+	}
+
+	return safe, objects
+}
+
+// snapshotOf returns other as is if it is a thread-unsafe set, and
+// otherwise a thread-unsafe copy of its elements. Thread-safe sets take
+// the snapshot of any other implementation than theirs before locking,
+// so that they never call into the locks of that implementation, which
+// may wrap this very set or take its write lock to read, as TTL sets do,
+// while holding their own.
+func snapshotOf(other Set) *threadUnsafeSet {
+	if o, ok := other.(*threadUnsafeSet); ok {
+		return o
+	}
+
+	items := other.ToSlice()
+	snapshot := newThreadUnsafeSetWithSize(len(items))
+	for _, item := range items {
+		snapshot[item] = struct{}{}
+	}
+	return &snapshot
+}
+
+// rlockWith read-locks set along with every thread-safe set among others
+// and returns the sets to hand to the thread-unsafe implementation, see
+// unsafeObjects, and a func releasing the locks.
+func (set *threadSafeSet) rlockWith(others ...Set) ([]Set, func()) {
+	safe, objects := unsafeObjects(others)
+	return objects, rlockSets(append(safe, set)...)
+}
+
+// rlockOthers read-locks every thread-safe set among others and returns
+// the sets to hand to the thread-unsafe implementation, see
+// unsafeObjects, and a func releasing the locks.
+func rlockOthers(others ...Set) ([]Set, func()) {
+	safe, objects := unsafeObjects(others)
+	return objects, rlockSets(safe...)
+}
+
 func (set *threadSafeSet) Add(i interface{}) bool {
 	set.mutex.Lock()
 	defer set.mutex.Unlock()
@@ -43,6 +215,26 @@ func (set *threadSafeSet) Add(i interface{}) bool {
 	return set.objects.Add(i)
 }
 
+func (set *threadSafeSet) AddAll(i ...interface{}) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.objects.AddAll(i...)
+}
+
+func (set *threadSafeSet) AddIfNotContains(candidate, guard interface{}) bool {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.objects.AddIfNotContains(candidate, guard)
+}
+
+// Freeze wraps the underlying thread-unsafe set, reads on the view never
+// take the lock and the sets the view derives are thread-unsafe.
+func (set *threadSafeSet) Freeze() Set {
+	return freeze(&set.objects)
+}
+
 func (set *threadSafeSet) Contains(i ...interface{}) bool {
 	set.mutex.RLock()
 	defer set.mutex.RUnlock()
@@ -50,81 +242,133 @@ func (set *threadSafeSet) Contains(i ...interface{}) bool {
 	return set.objects.Contains(i...)
 }
 
-func (set *threadSafeSet) IsSubset(other Set) bool {
-	o := other.(*threadSafeSet)
-
+func (set *threadSafeSet) Has(i interface{}) bool {
 	set.mutex.RLock()
 	defer set.mutex.RUnlock()
-	o.mutex.RLock()
-	defer o.mutex.RUnlock()
 
-	return set.objects.IsSubset(&o.objects)
+	return set.objects.Has(i)
 }
 
-func (set *threadSafeSet) IsProperSubset(other Set) bool {
-	o := other.(*threadSafeSet)
+func (set *threadSafeSet) ContainsAll(i ...interface{}) bool {
+	return set.Contains(i...)
+}
 
+func (set *threadSafeSet) ContainsAny(i ...interface{}) bool {
 	set.mutex.RLock()
 	defer set.mutex.RUnlock()
-	o.mutex.RLock()
-	defer o.mutex.RUnlock()
 
-	return set.objects.IsProperSubset(&o.objects)
+	return set.objects.ContainsAny(i...)
+}
+
+func (set *threadSafeSet) ContainsBy(value interface{}, keyFunc func(interface{}) interface{}) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.ContainsBy(value, keyFunc)
+}
+
+func (set *threadSafeSet) IsSubset(other Set) bool {
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.objects.IsSubset(objects[0])
+}
+
+func (set *threadSafeSet) IsProperSubset(other Set) bool {
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.objects.IsProperSubset(objects[0])
 }
 
 func (set *threadSafeSet) IsSuperset(other Set) bool {
-	return other.IsSubset(set)
+	return nonNil(other).IsSubset(set)
 }
 
 func (set *threadSafeSet) IsProperSuperset(other Set) bool {
-	return other.IsProperSubset(set)
+	return nonNil(other).IsProperSubset(set)
 }
 
 func (set *threadSafeSet) Union(other Set) Set {
-	o := other.(*threadSafeSet)
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
 
-	set.mutex.RLock()
-	defer set.mutex.RUnlock()
-	o.mutex.RLock()
-	defer o.mutex.RUnlock()
+	union := set.objects.Union(objects[0]).(*threadUnsafeSet)
+	return &threadSafeSet{objects: *union}
+}
+
+func (set *threadSafeSet) UnionAll(others ...Set) Set {
+	others = nonNilAll(others)
+	objects, unlock := set.rlockWith(others...)
+	defer unlock()
 
-	union := set.objects.Union(&o.objects).(*threadUnsafeSet)
+	union := set.objects.UnionAll(objects...).(*threadUnsafeSet)
 	return &threadSafeSet{objects: *union}
 }
 
 func (set *threadSafeSet) Intersect(other Set) Set {
-	o := other.(*threadSafeSet)
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
 
-	set.mutex.RLock()
-	defer set.mutex.RUnlock()
-	o.mutex.RLock()
-	defer o.mutex.RUnlock()
-
-	unsafeIntersection := set.objects.Intersect(&o.objects).(*threadUnsafeSet)
+	unsafeIntersection := set.objects.Intersect(objects[0]).(*threadUnsafeSet)
 	return &threadSafeSet{objects: *unsafeIntersection}
 }
 
+func (set *threadSafeSet) IntersectAll(others ...Set) Set {
+	others = nonNilAll(others)
+	objects, unlock := set.rlockWith(others...)
+	defer unlock()
+
+	intersection := set.objects.IntersectAll(objects...).(*threadUnsafeSet)
+	return &threadSafeSet{objects: *intersection}
+}
+
 func (set *threadSafeSet) Difference(other Set) Set {
-	o := other.(*threadSafeSet)
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
 
-	set.mutex.RLock()
-	defer set.mutex.RUnlock()
-	o.mutex.RLock()
-	defer o.mutex.RUnlock()
+	diff := set.objects.Difference(objects[0]).(*threadUnsafeSet)
+	return &threadSafeSet{objects: *diff}
+}
 
-	diff := set.objects.Difference(&o.objects).(*threadUnsafeSet)
+func (set *threadSafeSet) DifferenceAll(others ...Set) Set {
+	others = nonNilAll(others)
+	objects, unlock := set.rlockWith(others...)
+	defer unlock()
+
+	diff := set.objects.DifferenceAll(objects...).(*threadUnsafeSet)
 	return &threadSafeSet{objects: *diff}
 }
 
-func (set *threadSafeSet) SymmetricDifference(other Set) Set {
-	o := other.(*threadSafeSet)
+func (set *threadSafeSet) Diff(other Set) (added Set, removed Set) {
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
 
-	set.mutex.RLock()
-	defer set.mutex.RUnlock()
-	o.mutex.RLock()
-	defer o.mutex.RUnlock()
+	a, r := set.objects.Diff(objects[0])
+	return &threadSafeSet{objects: *a.(*threadUnsafeSet)}, &threadSafeSet{objects: *r.(*threadUnsafeSet)}
+}
 
-	diff := set.objects.SymmetricDifference(&o.objects).(*threadUnsafeSet)
+func (set *threadSafeSet) Merge(other Set) (union Set, common Set) {
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
+
+	u, c := set.objects.Merge(objects[0])
+	return &threadSafeSet{objects: *u.(*threadUnsafeSet)}, &threadSafeSet{objects: *c.(*threadUnsafeSet)}
+}
+
+func (set *threadSafeSet) SymmetricDifference(other Set) Set {
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
+
+	diff := set.objects.SymmetricDifference(objects[0]).(*threadUnsafeSet)
 	return &threadSafeSet{objects: *diff}
 }
 
@@ -135,6 +379,17 @@ func (set *threadSafeSet) Clear() {
 	set.objects = newThreadUnsafeSet()
 }
 
+func (set *threadSafeSet) ClearRetainingCapacity() {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	set.objects.clear()
+}
+
+func (set *threadSafeSet) ReplaceAll(i ...interface{}) {
+	set.reset(i)
+}
+
 func (set *threadSafeSet) Remove(i interface{}) {
 	set.mutex.Lock()
 	defer set.mutex.Unlock()
@@ -142,6 +397,100 @@ func (set *threadSafeSet) Remove(i interface{}) {
 	delete(set.objects, i)
 }
 
+func (set *threadSafeSet) RemoveAll(i ...interface{}) int {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.objects.RemoveAll(i...)
+}
+
+func (set *threadSafeSet) RetainAll(other Set) int {
+	other = nonNil(other)
+	o, ok := other.(*threadSafeSet)
+	if !ok {
+		snapshot := snapshotOf(other)
+
+		set.mutex.Lock()
+		defer set.mutex.Unlock()
+
+		return set.objects.RetainAll(snapshot)
+	}
+	if o == set {
+		return 0
+	}
+
+	if lessAddress(&set.mutex, &o.mutex) {
+		set.mutex.Lock()
+		o.mutex.RLock()
+	} else {
+		o.mutex.RLock()
+		set.mutex.Lock()
+	}
+	defer set.mutex.Unlock()
+	defer o.mutex.RUnlock()
+
+	return set.objects.RetainAll(&o.objects)
+}
+
+func (set *threadSafeSet) AddSet(other Set) int {
+	other = nonNil(other)
+	o, ok := other.(*threadSafeSet)
+	if !ok {
+		snapshot := snapshotOf(other)
+
+		set.mutex.Lock()
+		defer set.mutex.Unlock()
+
+		return set.objects.AddSet(snapshot)
+	}
+	if o == set {
+		return 0
+	}
+
+	if lessAddress(&set.mutex, &o.mutex) {
+		set.mutex.Lock()
+		o.mutex.RLock()
+	} else {
+		o.mutex.RLock()
+		set.mutex.Lock()
+	}
+	defer set.mutex.Unlock()
+	defer o.mutex.RUnlock()
+
+	return set.objects.AddSet(&o.objects)
+}
+
+func (set *threadSafeSet) SubtractSet(other Set) int {
+	other = nonNil(other)
+	o, ok := other.(*threadSafeSet)
+	if !ok {
+		snapshot := snapshotOf(other)
+
+		set.mutex.Lock()
+		defer set.mutex.Unlock()
+
+		return set.objects.SubtractSet(snapshot)
+	}
+	if o == set {
+		set.mutex.Lock()
+		defer set.mutex.Unlock()
+
+		return set.objects.SubtractSet(&set.objects)
+	}
+
+	if lessAddress(&set.mutex, &o.mutex) {
+		set.mutex.Lock()
+		o.mutex.RLock()
+	} else {
+		o.mutex.RLock()
+		set.mutex.Lock()
+	}
+	defer set.mutex.Unlock()
+	defer o.mutex.RUnlock()
+
+	return set.objects.SubtractSet(&o.objects)
+}
+
 func (set *threadSafeSet) Cardinality() int {
 	set.mutex.RLock()
 	defer set.mutex.RUnlock()
@@ -156,6 +505,51 @@ func (set *threadSafeSet) Length() int {
 	return len(set.objects)
 }
 
+func (set *threadSafeSet) Intersects(other Set) bool {
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.objects.Intersects(objects[0])
+}
+
+func (set *threadSafeSet) IntersectCardinality(other Set) int {
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.objects.IntersectCardinality(objects[0])
+}
+
+func (set *threadSafeSet) UnionCardinality(other Set) int {
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.objects.UnionCardinality(objects[0])
+}
+
+func (set *threadSafeSet) IsDisjoint(other Set) bool {
+	other = nonNil(other)
+	return !set.Intersects(other)
+}
+
+func (set *threadSafeSet) JaccardSimilarity(other Set) float64 {
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.objects.JaccardSimilarity(objects[0])
+}
+
+func (set *threadSafeSet) OverlapCoefficient(other Set) float64 {
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
+
+	return set.objects.OverlapCoefficient(objects[0])
+}
+
 func (set *threadSafeSet) Each(callback func(interface{}) bool) {
 	set.mutex.RLock()
 	defer set.mutex.RUnlock()
@@ -166,8 +560,112 @@ func (set *threadSafeSet) Each(callback func(interface{}) bool) {
 	}
 }
 
+func (set *threadSafeSet) ForEach(callback func(interface{})) {
+	set.Each(func(elem interface{}) bool {
+		callback(elem)
+		return false
+	})
+}
+
+func (set *threadSafeSet) ForEachUntil(callback func(interface{}) bool) {
+	set.Each(func(elem interface{}) bool {
+		return !callback(elem)
+	})
+}
+
+func (set *threadSafeSet) IterateIndexed(callback func(index int, elem interface{}) bool) {
+	index := 0
+	set.Each(func(elem interface{}) bool {
+		stop := !callback(index, elem)
+		index++
+		return stop
+	})
+}
+
+func (set *threadSafeSet) EachParallel(workers int, callback func(interface{})) {
+	eachParallel(set.ToSlice(), workers, func(elem interface{}) error {
+		callback(elem)
+		return nil
+	})
+}
+
+func (set *threadSafeSet) EachParallelErr(workers int, callback func(interface{}) error) []error {
+	return eachParallel(set.ToSlice(), workers, callback)
+}
+
+func (set *threadSafeSet) Map(transform func(interface{}) interface{}) Set {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	mapped := set.objects.Map(transform).(*threadUnsafeSet)
+	return &threadSafeSet{objects: *mapped}
+}
+
+func (set *threadSafeSet) Partition(predicate func(interface{}) bool) (Set, Set) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	matched, rest := set.objects.Partition(predicate)
+	return &threadSafeSet{objects: *matched.(*threadUnsafeSet)},
+		&threadSafeSet{objects: *rest.(*threadUnsafeSet)}
+}
+
+func (set *threadSafeSet) GroupBy(keyFunc func(interface{}) interface{}) map[interface{}]Set {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	groups := set.objects.GroupBy(keyFunc)
+	for key, group := range groups {
+		groups[key] = &threadSafeSet{objects: *group.(*threadUnsafeSet)}
+	}
+
+	return groups
+}
+
+func (set *threadSafeSet) Reduce(initial interface{}, accumulate func(acc, elem interface{}) interface{}) interface{} {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.Reduce(initial, accumulate)
+}
+
+func (set *threadSafeSet) Any(predicate func(interface{}) bool) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.Any(predicate)
+}
+
+func (set *threadSafeSet) All(predicate func(interface{}) bool) bool {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.All(predicate)
+}
+
+func (set *threadSafeSet) Min(less func(a, b interface{}) bool) (interface{}, bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.Min(less)
+}
+
+func (set *threadSafeSet) Max(less func(a, b interface{}) bool) (interface{}, bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.Max(less)
+}
+
+func (set *threadSafeSet) Count(predicate func(interface{}) bool) int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.Count(predicate)
+}
+
 func (set *threadSafeSet) Iter() <-chan interface{} {
-	ch := make(chan interface{})
+	ch := make(chan interface{}, iterBufferSize)
 	go func() {
 		set.mutex.RLock()
 		for elem := range set.objects {
@@ -180,6 +678,37 @@ func (set *threadSafeSet) Iter() <-chan interface{} {
 	return ch
 }
 
+func (set *threadSafeSet) IterContext(ctx context.Context) <-chan interface{} {
+	ch := make(chan interface{}, iterBufferSize)
+	go func() {
+		set.mutex.RLock()
+	L:
+		for elem := range set.objects {
+			select {
+			case <-ctx.Done():
+				break L
+			case ch <- elem:
+			}
+		}
+		close(ch)
+		set.mutex.RUnlock()
+	}()
+
+	return ch
+}
+
+func (set *threadSafeSet) IterSnapshot() <-chan interface{} {
+	return iterSlice(set.Elements())
+}
+
+func (set *threadSafeSet) IterSorted(less func(a, b interface{}) bool) <-chan interface{} {
+	return iterSlice(set.ToSortedSlice(less))
+}
+
+func (set *threadSafeSet) Tee() (<-chan interface{}, <-chan interface{}) {
+	return teeSlice(set.Elements())
+}
+
 func (set *threadSafeSet) Iterator() *Iterator {
 	iterator, ch, stopCh := newIterator()
 
@@ -200,15 +729,26 @@ func (set *threadSafeSet) Iterator() *Iterator {
 	return iterator
 }
 
+func (set *threadSafeSet) Elements() []interface{} {
+	return set.ToSlice()
+}
+
 func (set *threadSafeSet) Equal(other Set) bool {
-	o := other.(*threadSafeSet)
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
 
-	set.mutex.RLock()
-	defer set.mutex.RUnlock()
-	o.mutex.RLock()
-	defer o.mutex.RUnlock()
+	return set.objects.Equal(objects[0])
+}
 
-	return set.objects.Equal(&o.objects)
+func (set *threadSafeSet) EqualFunc(other Set, eq func(a, b interface{}) bool) bool {
+	other = nonNil(other)
+	return equalFunc(set.ToSlice(), other.ToSlice(), eq)
+}
+
+func (set *threadSafeSet) EqualUsing(other Set, eq func(a, b interface{}) bool) bool {
+	other = nonNil(other)
+	return equalUsing(set.ToSlice(), other.ToSlice(), eq)
 }
 
 func (set *threadSafeSet) Clone() Set {
@@ -219,6 +759,45 @@ func (set *threadSafeSet) Clone() Set {
 	return &threadSafeSet{objects: *clone}
 }
 
+func (set *threadSafeSet) Snapshot() Set {
+	return set.Clone()
+}
+
+func (set *threadSafeSet) reset(items []interface{}) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	set.objects.reset(items)
+}
+
+func (set *threadSafeSet) CopyTo(dest Set) {
+	switch d := dest.(type) {
+	case *threadSafeSet:
+		if d == set {
+			return
+		}
+
+		if lessAddress(&set.mutex, &d.mutex) {
+			set.mutex.RLock()
+			d.mutex.Lock()
+		} else {
+			d.mutex.Lock()
+			set.mutex.RLock()
+		}
+		defer set.mutex.RUnlock()
+		defer d.mutex.Unlock()
+
+		set.objects.CopyTo(&d.objects)
+	case *threadUnsafeSet:
+		set.mutex.RLock()
+		defer set.mutex.RUnlock()
+
+		set.objects.CopyTo(d)
+	default:
+		copyItems(dest, set.ToSlice())
+	}
+}
+
 func (set *threadSafeSet) String() string {
 	set.mutex.RLock()
 	defer set.mutex.RUnlock()
@@ -226,15 +805,48 @@ func (set *threadSafeSet) String() string {
 	return set.objects.String()
 }
 
+func (set *threadSafeSet) Hash() uint64 {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.Hash()
+}
+
+func (set *threadSafeSet) StringSorted() string {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.StringSorted()
+}
+
+func (set *threadSafeSet) Peek() (interface{}, bool) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.Peek()
+}
+
+func (set *threadSafeSet) Sample(n int) Set {
+	return set.SampleWithRand(n, nil)
+}
+
+func (set *threadSafeSet) SampleWithRand(n int, r *rand.Rand) Set {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	sample := set.objects.SampleWithRand(n, r).(*threadUnsafeSet)
+	return &threadSafeSet{objects: *sample}
+}
+
 func (set *threadSafeSet) PowerSet() Set {
 	set.mutex.RLock()
 	unsafePowerSet := set.objects.PowerSet().(*threadUnsafeSet)
 	set.mutex.RUnlock()
 
-	tss := &threadSafeSet{objects: newThreadUnsafeSet()}
-	for subset := range unsafePowerSet.Iter() {
+	tss := &threadSafeSet{objects: newThreadUnsafeSetWithSize(len(*unsafePowerSet))}
+	for subset := range *unsafePowerSet {
 		unsafeSubset := subset.(*threadUnsafeSet)
-		tss.Add(&threadSafeSet{objects: *unsafeSubset})
+		tss.objects[&threadSafeSet{objects: *unsafeSubset}] = struct{}{}
 	}
 
 	return tss
@@ -247,19 +859,38 @@ func (set *threadSafeSet) Pop() interface{} {
 	return set.objects.Pop()
 }
 
-func (set *threadSafeSet) CartesianProduct(other Set) Set {
-	o := other.(*threadSafeSet)
+func (set *threadSafeSet) TryPop() (interface{}, bool) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
 
-	set.mutex.RLock()
-	defer set.mutex.RUnlock()
-	o.mutex.RLock()
-	defer set.mutex.RUnlock()
+	return set.objects.TryPop()
+}
+
+func (set *threadSafeSet) PowerSetIterator() *Iterator {
+	return powerSetIterator(set.ToSlice(), func(items []interface{}) Set {
+		subset := newThreadUnsafeSetWithSize(len(items))
+		for _, item := range items {
+			subset[item] = struct{}{}
+		}
+		return &threadSafeSet{objects: subset}
+	})
+}
+
+func (set *threadSafeSet) CartesianProduct(other Set) Set {
+	other = nonNil(other)
+	objects, unlock := set.rlockWith(other)
+	defer unlock()
 
 	// unsafe cartesian product
-	ucp := set.objects.CartesianProduct(&o.objects).(*threadUnsafeSet)
+	ucp := set.objects.CartesianProduct(objects[0]).(*threadUnsafeSet)
 	return &threadSafeSet{objects: *ucp}
 }
 
+func (set *threadSafeSet) CartesianProductIterator(other Set) *PairIterator {
+	other = nonNil(other)
+	return cartesianProductIterator(set.ToSlice(), other.ToSlice())
+}
+
 func (set *threadSafeSet) ToSlice() []interface{} {
 	keys := make([]interface{}, 0, set.Cardinality())
 
@@ -273,6 +904,34 @@ func (set *threadSafeSet) ToSlice() []interface{} {
 	return keys
 }
 
+func (set *threadSafeSet) Chunk(size int) [][]interface{} {
+	return chunk(set.ToSlice(), size)
+}
+
+func (set *threadSafeSet) ToMap() map[interface{}]struct{} {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.ToMap()
+}
+
+func (set *threadSafeSet) Drain() []interface{} {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.objects.Drain()
+}
+
+func (set *threadSafeSet) ToSortedSlice(less func(a, b interface{}) bool) []interface{} {
+	// Only collecting the elements needs the lock, sorting the copy doesn't.
+	keys := set.ToSlice()
+	sort.Slice(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+
+	return keys
+}
+
 func (set *threadSafeSet) Strings() []string {
 	keys := make([]string, 0, set.Length())
 
@@ -289,6 +948,27 @@ func (set *threadSafeSet) Strings() []string {
 	return keys
 }
 
+func (set *threadSafeSet) StringsWithConversion() []string {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.StringsWithConversion()
+}
+
+func (set *threadSafeSet) Ints() []int {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.Ints()
+}
+
+func (set *threadSafeSet) Float64s() []float64 {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.Float64s()
+}
+
 func (set *threadSafeSet) MarshalJSON() ([]byte, error) {
 	set.mutex.RLock()
 	defer set.mutex.RUnlock()
@@ -296,9 +976,109 @@ func (set *threadSafeSet) MarshalJSON() ([]byte, error) {
 	return set.objects.MarshalJSON()
 }
 
-func (set *threadSafeSet) UnmarshalJSON(p []byte) error {
+func (set *threadSafeSet) MarshalJSONSorted() ([]byte, error) {
 	set.mutex.RLock()
 	defer set.mutex.RUnlock()
 
+	return set.objects.MarshalJSONSorted()
+}
+
+func (set *threadSafeSet) UnmarshalJSON(p []byte) error {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
 	return set.objects.UnmarshalJSON(p)
 }
+
+func (set *threadSafeSet) UnmarshalJSONWith(b []byte, convertNumber func(json.Number) interface{}) error {
+	items, err := unmarshalJSONElementsWith(b, convertNumber)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *threadSafeSet) UnmarshalJSONInto(b []byte, prototype func() interface{}) error {
+	items, err := unmarshalJSONElementsInto(b, prototype)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *threadSafeSet) GobEncode() ([]byte, error) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.GobEncode()
+}
+
+func (set *threadSafeSet) GobDecode(b []byte) error {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.objects.GobDecode(b)
+}
+
+func (set *threadSafeSet) MarshalBinary() ([]byte, error) {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.MarshalBinary()
+}
+
+func (set *threadSafeSet) UnmarshalBinary(data []byte) error {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	return set.objects.UnmarshalBinary(data)
+}
+
+func (set *threadSafeSet) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+
+	return set.objects.MarshalXML(e, start)
+}
+
+func (set *threadSafeSet) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	items, err := unmarshalXMLElements(d, start)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *threadSafeSet) MarshalText() ([]byte, error) {
+	return marshalTextElements(set.StringsWithConversion()), nil
+}
+
+func (set *threadSafeSet) UnmarshalText(text []byte) error {
+	items, err := unmarshalTextElements(text)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
+
+func (set *threadSafeSet) WriteCSV(w io.Writer) error {
+	return writeCSVElements(w, set.StringsWithConversion())
+}
+
+func (set *threadSafeSet) ReadCSV(r io.Reader) error {
+	items, err := readCSVElements(r)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+	return nil
+}
@@ -26,6 +26,9 @@ SOFTWARE.
 package mapset
 
 import (
+	"bytes"
+	"context"
+	"encoding/gob"
 	"encoding/json"
 	"fmt"
 	"math/rand"
@@ -33,10 +36,165 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 const N = 1000
 
+// runMixedConcurrent runs op concurrently both ways between a thread-safe
+// set and each kind of set wrapping one, failing if they deadlock. The sets
+// hold the same elements, or disjoint ones when disjoint is set.
+func runMixedConcurrent(t *testing.T, name string, disjoint bool, op func(a, b Set)) {
+	runtime.GOMAXPROCS(2)
+
+	wrappers := map[string]func() Set{
+		"bounded":  func() Set { return NewBoundedSet(1000) },
+		"nonNil":   NewNonNilSet,
+		"observed": func() Set { return NewObservedSet(NewSet(), &countingObserver{}) },
+		"ttl":      func() Set { return NewTTLSet(time.Hour) },
+	}
+	for wrapper, newWrapper := range wrappers {
+		// Sets are usually laid out in allocation order, so allocating
+		// them in turn either way tries both lock orders.
+		for trial := 0; trial < 4; trial++ {
+			var s, w Set
+			if trial%2 == 0 {
+				s, w = NewSet(), newWrapper()
+			} else {
+				w, s = newWrapper(), NewSet()
+			}
+			for i := 0; i < 100; i++ {
+				s.Add(i)
+				if disjoint {
+					w.Add(-i - 1)
+				} else {
+					w.Add(i)
+				}
+			}
+
+			done := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(8)
+			for g := 0; g < 8; g++ {
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 500; i++ {
+						if (g+i)%2 == 0 {
+							op(s, w)
+						} else {
+							op(w, s)
+						}
+					}
+				}(g)
+			}
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatalf("%s between a thread-safe set and a %s set deadlocked", name, wrapper)
+			}
+		}
+	}
+}
+
+func Test_RetainAllMixedConcurrent(t *testing.T) {
+	runMixedConcurrent(t, "RetainAll", false, func(a, b Set) { a.RetainAll(b) })
+}
+
+func Test_AddSetMixedConcurrent(t *testing.T) {
+	runMixedConcurrent(t, "AddSet", false, func(a, b Set) { a.AddSet(b) })
+}
+
+func Test_SubtractSetMixedConcurrent(t *testing.T) {
+	runMixedConcurrent(t, "SubtractSet", true, func(a, b Set) { a.SubtractSet(b) })
+}
+
+// runCrossKindConcurrent runs the read-only op concurrently both ways
+// between every two kinds of thread-safe sets while writers keep changing
+// both, failing if they deadlock.
+func runCrossKindConcurrent(t *testing.T, name string, op func(a, b Set)) {
+	runtime.GOMAXPROCS(2)
+
+	kinds := map[string]func() Set{
+		"thread-safe":      func() Set { return NewSet() },
+		"ordered":          func() Set { return NewOrderedSet() },
+		"sharded":          func() Set { return NewShardedSet(8) },
+		"lru":              func() Set { return NewLRUSet(1000) },
+		"observed ordered": func() Set { return NewObservedSet(NewOrderedSet(), &countingObserver{}) },
+	}
+	for an, newA := range kinds {
+		for bn, newB := range kinds {
+			if an >= bn {
+				continue
+			}
+			a, b := newA(), newB()
+			for i := 0; i < 100; i++ {
+				a.Add(i)
+				b.Add(i * 2)
+			}
+
+			done := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(8)
+			for g := 0; g < 8; g++ {
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 500; i++ {
+						switch {
+						case g < 2:
+							w := a
+							if g == 1 {
+								w = b
+							}
+							w.Add(1000 + i)
+							w.Remove(1000 + i)
+						case (g+i)%2 == 0:
+							op(a, b)
+						default:
+							op(b, a)
+						}
+					}
+				}(g)
+			}
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatalf("%s between a %s set and a %s set deadlocked", name, an, bn)
+			}
+		}
+	}
+}
+
+func Test_IntersectsCrossKindConcurrent(t *testing.T) {
+	runCrossKindConcurrent(t, "Intersects", func(a, b Set) {
+		a.Intersects(b)
+		a.IsDisjoint(b)
+	})
+}
+
+func Test_CardinalitiesCrossKindConcurrent(t *testing.T) {
+	runCrossKindConcurrent(t, "IntersectCardinality", func(a, b Set) {
+		a.IntersectCardinality(b)
+		a.UnionCardinality(b)
+	})
+}
+
+func Test_SimilarityCrossKindConcurrent(t *testing.T) {
+	runCrossKindConcurrent(t, "JaccardSimilarity", func(a, b Set) {
+		a.JaccardSimilarity(b)
+		a.OverlapCoefficient(b)
+	})
+}
+
 func Test_AddConcurrent(t *testing.T) {
 	runtime.GOMAXPROCS(2)
 
@@ -60,6 +218,67 @@ func Test_AddConcurrent(t *testing.T) {
 	}
 }
 
+func Test_MoveConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	for name, pair := range map[string][2]Set{
+		"safe":    {NewSet(), NewSet()},
+		"sharded": {NewShardedSet(4), NewSet()},
+		"ordered": {NewOrderedSet(), NewShardedSet(4)},
+	} {
+		a, b := pair[0], pair[1]
+		for i := 0; i < N; i++ {
+			a.Add(i)
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(2 * N)
+		for i := 0; i < N; i++ {
+			// Moving both ways at once must not deadlock.
+			go func(i int) {
+				Move(a, b, i)
+				wg.Done()
+			}(i)
+			go func(i int) {
+				Move(b, a, i)
+				wg.Done()
+			}(i)
+		}
+		wg.Wait()
+
+		if a.Cardinality()+b.Cardinality() != N || a.Intersects(b) {
+			t.Errorf("%s: every element should be in exactly one set, got %d and %d elements",
+				name, a.Cardinality(), b.Cardinality())
+		}
+	}
+}
+
+func Test_SwapConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	a, b := NewSet(1), NewSet(2, 3)
+
+	var wg sync.WaitGroup
+	wg.Add(2 * N)
+	for i := 0; i < N; i++ {
+		// Swapping both ways at once must not deadlock.
+		go func() {
+			Swap(a, b)
+			wg.Done()
+		}()
+		go func() {
+			Swap(b, a)
+			a.Union(b)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	if !a.Union(b).Equal(NewSet(1, 2, 3)) || a.Cardinality()+b.Cardinality() != 3 {
+		t.Errorf("Swap should only exchange the elements, got %v and %v", a, b)
+	}
+}
+
 func Test_CardinalityConcurrent(t *testing.T) {
 	runtime.GOMAXPROCS(2)
 
@@ -127,6 +346,99 @@ func Test_CloneConcurrent(t *testing.T) {
 	s.Clone()
 }
 
+func Test_SnapshotConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s := NewSet()
+	ints := rand.Perm(N)
+	for _, v := range ints {
+		s.Add(v)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(ints))
+	for _, v := range ints {
+		go func(v int) {
+			s.Add(v + N)
+			wg.Done()
+		}(v)
+	}
+
+	snapshot := s.Snapshot()
+	wg.Wait()
+
+	if snapshot.Cardinality() < N || snapshot.Cardinality() > 2*N {
+		t.Errorf("Snapshot has an inconsistent cardinality: %d", snapshot.Cardinality())
+	}
+
+	snapshot.Clear()
+	if s.Cardinality() != 2*N {
+		t.Error("Clearing the snapshot should leave the original set untouched")
+	}
+}
+
+func Test_CopyToConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	a, b := NewSet(), NewSet()
+	for _, v := range rand.Perm(N) {
+		a.Add(v)
+		b.Add(v + N)
+	}
+
+	// Copying in both directions at once must not deadlock.
+	var wg sync.WaitGroup
+	wg.Add(2 * N)
+	for i := 0; i < N; i++ {
+		go func() {
+			a.CopyTo(b)
+			wg.Done()
+		}()
+		go func() {
+			b.CopyTo(a)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	if !a.Equal(b) || a.Cardinality() != N {
+		t.Errorf("CopyTo should leave both sets with the same %d elements, got %d and %d", N, a.Cardinality(), b.Cardinality())
+	}
+}
+
+func Test_DrainConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s := NewSet()
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for i := 0; i < N; i++ {
+		go func(i int) {
+			s.Add(i)
+			wg.Done()
+		}(i)
+	}
+
+	var drained []interface{}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		drained = append(drained, s.Drain()...)
+	}
+
+	if len(drained) != N || NewSetFromSlice(drained).Cardinality() != N {
+		t.Errorf("every element should be drained exactly once, got %d elements", len(drained))
+	}
+}
+
 func Test_ContainsConcurrent(t *testing.T) {
 	runtime.GOMAXPROCS(2)
 
@@ -325,6 +637,246 @@ func Test_EachConcurrent(t *testing.T) {
 	}
 }
 
+func Test_IterHoldsReadLock(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s := NewSet()
+	for _, v := range rand.Perm(N) {
+		s.Add(v)
+	}
+
+	c := s.Iter()
+	<-c
+
+	added := make(chan struct{})
+	go func() {
+		s.Add(-1)
+		close(added)
+	}()
+
+	select {
+	case <-added:
+		t.Fatal("Add should block while Iter is still producing elements")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	count := 1
+	for range c {
+		count++
+	}
+	<-added
+
+	if count != N {
+		t.Errorf("Iter should yield all %d elements, got %d", N, count)
+	}
+}
+
+func Test_IterAbandoned(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	addWithin := func(s Set) bool {
+		added := make(chan struct{})
+		go func() {
+			s.Add(-1)
+			close(added)
+		}()
+
+		select {
+		case <-added:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}
+
+	// Breaking out of a range over Iter leaks the producer, which keeps
+	// the read lock of the set forever.
+	leaked := NewSet()
+	for _, v := range rand.Perm(N) {
+		leaked.Add(v)
+	}
+	for range leaked.Iter() {
+		break
+	}
+	if addWithin(leaked) {
+		t.Error("an abandoned Iter channel was expected to keep the read lock")
+	}
+
+	// Stopping an Iterator releases it.
+	s := NewSet()
+	for _, v := range rand.Perm(N) {
+		s.Add(v)
+	}
+	it := s.Iterator()
+	for range it.C {
+		break
+	}
+	it.Stop()
+	if !addWithin(s) {
+		t.Error("a stopped Iterator should release the read lock")
+	}
+}
+
+func Test_IterContextCancel(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	for name, newSet := range setConstructors {
+		s := newSet()
+		for _, v := range rand.Perm(N) {
+			s.Add(v)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		c := s.IterContext(ctx)
+		<-c
+		cancel()
+
+		closed := make(chan struct{})
+		go func() {
+			for range c {
+			}
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Fatalf("%s: the IterContext channel should close once ctx is cancelled", name)
+		}
+
+		added := make(chan struct{})
+		go func() {
+			s.Add(-1)
+			close(added)
+		}()
+		select {
+		case <-added:
+		case <-time.After(time.Second):
+			t.Fatalf("%s: cancelling IterContext should release the read lock", name)
+		}
+	}
+}
+
+func Test_IterSnapshotDoesNotBlockWriters(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	for name, newSet := range setConstructors {
+		if name == "unsafe" {
+			continue
+		}
+
+		s := newSet()
+		for _, v := range rand.Perm(N) {
+			s.Add(v)
+		}
+
+		// Read a single element, leaving the producer blocked on a full
+		// channel while a writer runs.
+		c := s.IterSnapshot()
+		<-c
+
+		added := make(chan struct{})
+		go func() {
+			s.Add(-1)
+			close(added)
+		}()
+		select {
+		case <-added:
+		case <-time.After(time.Second):
+			t.Fatalf("%s: a slow IterSnapshot consumer should not block writers", name)
+		}
+
+		count := 1
+		for range c {
+			count++
+		}
+		if count != N {
+			t.Errorf("%s: IterSnapshot should yield the %d elements of the snapshot, got %d", name, N, count)
+		}
+	}
+}
+
+func Test_IterSortedConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s := NewSet()
+	for _, v := range rand.Perm(N) {
+		s.Add(v)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		for i := N; i < 2*N; i++ {
+			s.Add(i)
+		}
+		wg.Done()
+	}()
+
+	// less runs without the lock, so it may use the set itself.
+	prev := -1
+	for elem := range s.IterSorted(func(a, b interface{}) bool {
+		s.Cardinality()
+		return a.(int) < b.(int)
+	}) {
+		if elem.(int) <= prev {
+			t.Fatalf("IterSorted should yield increasing elements, got %d after %d", elem, prev)
+		}
+		prev = elem.(int)
+	}
+	wg.Wait()
+}
+
+func Test_ReplaceAllConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	old, replacement := make([]interface{}, 10), make([]interface{}, 10)
+	for i := range old {
+		old[i], replacement[i] = i, i+10
+	}
+
+	for name, newSet := range setConstructors {
+		if name == "unsafe" {
+			continue
+		}
+
+		s := newSet(old...)
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				items := s.ToSlice()
+				low := 0
+				for _, item := range items {
+					if item.(int) < 10 {
+						low++
+					}
+				}
+				if len(items) != 10 || (low != 0 && low != 10) {
+					t.Errorf("%s: a reader should never see a partial set, got %v", name, items)
+					return
+				}
+			}
+		}()
+
+		for i := 0; i < N; i++ {
+			if i%2 == 0 {
+				s.ReplaceAll(replacement...)
+			} else {
+				s.ReplaceAll(old...)
+			}
+		}
+		close(done)
+		wg.Wait()
+	}
+}
+
 func Test_IterConcurrent(t *testing.T) {
 	runtime.GOMAXPROCS(2)
 
@@ -423,6 +975,145 @@ func Test_SymmetricDifferenceConcurrent(t *testing.T) {
 	wg.Wait()
 }
 
+func Test_UnionAllConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s, ss, sss := NewSet(), NewSet(), NewSet()
+	ints := rand.Perm(N)
+	for _, v := range ints {
+		s.Add(v)
+		ss.Add(v)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3 * len(ints))
+	for _, v := range ints {
+		go func() {
+			s.UnionAll(ss, sss)
+			wg.Done()
+		}()
+		go func() {
+			sss.UnionAll(ss, s)
+			wg.Done()
+		}()
+		go func(v int) {
+			sss.Add(v)
+			wg.Done()
+		}(v)
+	}
+	wg.Wait()
+}
+
+func Test_RetainAllConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s, ss := NewSet(), NewSet()
+	ints := rand.Perm(N)
+	for _, v := range ints {
+		s.Add(v)
+		ss.Add(v)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2 * len(ints))
+	for range ints {
+		// Opposite receivers exercise the lock ordering of both sets.
+		go func() {
+			s.RetainAll(ss)
+			wg.Done()
+		}()
+		go func() {
+			ss.RetainAll(s)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	assertEqual(s, ss, t)
+}
+
+func Test_AddIfNotContainsConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	for name, newSet := range setConstructors {
+		if name == "unsafe" {
+			continue
+		}
+
+		// Every pair of elements guards against the other, so exactly one
+		// of them can be added.
+		s := newSet()
+		var wg sync.WaitGroup
+		wg.Add(N)
+		for i := 0; i < N; i++ {
+			go func(i int) {
+				s.AddIfNotContains(i, i^1)
+				wg.Done()
+			}(i)
+		}
+		wg.Wait()
+
+		if s.Cardinality() != N/2 {
+			t.Errorf("%s: expected %d elements, got %d", name, N/2, s.Cardinality())
+		}
+	}
+}
+
+func Test_AddSetConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s, ss := NewSet(), NewSet()
+	ints := rand.Perm(N)
+
+	var wg sync.WaitGroup
+	wg.Add(2 * len(ints))
+	for _, v := range ints {
+		// Opposite receivers exercise the lock ordering of both sets.
+		go func(v int) {
+			s.Add(v)
+			s.AddSet(ss)
+			wg.Done()
+		}(v)
+		go func(v int) {
+			ss.Add(-v - 1)
+			ss.AddSet(s)
+			wg.Done()
+		}(v)
+	}
+	wg.Wait()
+
+	s.AddSet(ss)
+	if s.Cardinality() != 2*N {
+		t.Errorf("expected %d elements, got %d", 2*N, s.Cardinality())
+	}
+}
+
+func Test_CartesianProductConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s, ss := NewSet(), NewSet()
+	for i := 0; i < 10; i++ {
+		s.Add(i)
+		ss.Add(i)
+	}
+
+	s.CartesianProduct(ss)
+
+	done := make(chan struct{})
+	go func() {
+		// Both writes block forever if CartesianProduct leaked a read lock.
+		s.Add(N)
+		ss.Add(N)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("CartesianProduct did not release the read locks of both sets")
+	}
+}
+
 func Test_ToSlice(t *testing.T) {
 	runtime.GOMAXPROCS(2)
 
@@ -521,6 +1212,91 @@ func Test_UnmarshalJSON(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalJSONWith(t *testing.T) {
+	for name, newSet := range setConstructors {
+		ints := newSet(1, 2, -3)
+		b, err := json.Marshal(ints)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded := newSet()
+		if err := decoded.UnmarshalJSONWith(b, ConvertJSONNumber); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !decoded.Equal(ints) {
+			t.Errorf("%s: a set of ints should equal its decoded form, got %v", name, decoded)
+		}
+	}
+
+	s := NewSet()
+	err := s.UnmarshalJSONWith([]byte(`[1, 1.5, 2e3, -0.25, 12345678901234567890123, "1"]`), ConvertJSONNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := NewSet(1, 1.5, 2000.0, -0.25, json.Number("12345678901234567890123"), "1")
+	if !s.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, s)
+	}
+
+	strs := NewSet()
+	err = strs.UnmarshalJSONWith([]byte(`[1, 2]`), func(n json.Number) interface{} { return n.String() })
+	if err != nil || !strs.Equal(NewSet("1", "2")) {
+		t.Errorf("a custom conversion should be applied to every number, got %v", strs)
+	}
+}
+
+func Test_UnmarshalJSONInto(t *testing.T) {
+	pairs := NewSet([2]int{1, 2}, [2]int{3, 4})
+	b, err := json.Marshal(pairs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, newSet := range setConstructors {
+		decoded := newSet()
+		err := decoded.UnmarshalJSONInto(b, func() interface{} { return new([2]int) })
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !decoded.Equal(pairs) {
+			t.Errorf("%s: coordinate pairs should round-trip, got %v", name, decoded)
+		}
+	}
+
+	nested := NewSet()
+	if err := nested.UnmarshalJSONInto([]byte(`[["a","b"],["c"]]`), func() interface{} { return NewSet() }); err != nil {
+		t.Fatal(err)
+	}
+	found := 0
+	nested.Each(func(elem interface{}) bool {
+		if s := elem.(Set); s.Equal(NewSet("a", "b")) || s.Equal(NewSet("c")) {
+			found++
+		}
+		return false
+	})
+	if found != 2 {
+		t.Errorf("nested arrays should decode into sets, got %v", nested)
+	}
+
+	s := NewSet()
+	for _, prototype := range []func() interface{}{
+		func() interface{} { return new(map[string]int) },
+		func() interface{} { return [2]int{} },
+		func() interface{} { return (*int)(nil) },
+	} {
+		if err := s.UnmarshalJSONInto([]byte(`[{"a":1}]`), prototype); err == nil {
+			t.Error("UnmarshalJSONInto should fail for prototypes that cannot build set elements")
+		}
+	}
+	if err := s.UnmarshalJSONInto([]byte(`[1, "x"]`), func() interface{} { return new(int) }); err == nil {
+		t.Error("UnmarshalJSONInto should fail for elements that do not decode into the prototype")
+	}
+	if s.Cardinality() != 0 {
+		t.Errorf("a failed decode should not add anything, got %v", s)
+	}
+}
+
 func Test_MarshalJSON(t *testing.T) {
 	expected := NewSetFromSlice(
 		[]interface{}{
@@ -551,3 +1327,43 @@ func Test_MarshalJSON(t *testing.T) {
 		t.Errorf("Expected no difference, got: %v", expected.Difference(actual))
 	}
 }
+
+func Test_MarshalJSONSorted(t *testing.T) {
+	expected := `["a","b",1,2,true]`
+
+	for _, s := range []Set{
+		NewSet(2, "b", true, 1, "a"),
+		NewThreadUnsafeSetWith(2, "b", true, 1, "a"),
+	} {
+		b, err := s.MarshalJSONSorted()
+		if err != nil {
+			t.Errorf("Error should be nil: %v", err)
+		}
+
+		if string(b) != expected {
+			t.Errorf("Expected %s, got %s", expected, b)
+		}
+	}
+}
+
+func Test_Gob(t *testing.T) {
+	for _, pair := range [][2]Set{
+		{NewSet(1, 2, "a", "b"), NewSet()},
+		{NewThreadUnsafeSetWith(1, 2, "a", "b"), NewThreadUnsafeSet()},
+	} {
+		expected, actual := pair[0], pair[1]
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(expected); err != nil {
+			t.Errorf("Error should be nil: %v", err)
+		}
+
+		if err := gob.NewDecoder(&buf).Decode(actual); err != nil {
+			t.Errorf("Error should be nil: %v", err)
+		}
+
+		if !expected.Equal(actual) {
+			t.Errorf("Expected %v, got %v", expected, actual)
+		}
+	}
+}
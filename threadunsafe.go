@@ -27,9 +27,16 @@ package mapset
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/gob"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"math/rand"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -45,6 +52,41 @@ func newThreadUnsafeSet() threadUnsafeSet {
 	return make(threadUnsafeSet)
 }
 
+func newThreadUnsafeSetWithSize(size int) threadUnsafeSet {
+	// make panics on a negative size hint, while an empty set is a
+	// perfectly good answer to one.
+	if size < 0 {
+		size = 0
+	}
+	return make(threadUnsafeSet, size)
+}
+
+// nonNil returns other, or a new empty set if other is nil, so that the
+// operations taking another set treat a nil Set, such as the zero value
+// of a map of sets, as the empty set.
+func nonNil(other Set) Set {
+	if other == nil {
+		return &threadUnsafeSet{}
+	}
+	return other
+}
+
+// nonNilAll applies nonNil to each of others, copying others only when
+// one of them is nil.
+func nonNilAll(others []Set) []Set {
+	for i, other := range others {
+		if other == nil {
+			sets := make([]Set, len(others))
+			copy(sets, others[:i])
+			for j, other := range others[i:] {
+				sets[i+j] = nonNil(other)
+			}
+			return sets
+		}
+	}
+	return others
+}
+
 // Equal says whether two 2-tuples contain the same values in the same order.
 func (pair *OrderedPair) Equal(other OrderedPair) bool {
 	return pair.First == other.First && pair.Second == other.Second
@@ -61,6 +103,29 @@ func (set *threadUnsafeSet) Add(key interface{}) bool {
 	return true
 }
 
+func (set *threadUnsafeSet) AddAll(keys ...interface{}) int {
+	added := 0
+	for _, key := range keys {
+		if set.Add(key) {
+			added++
+		}
+	}
+
+	return added
+}
+
+func (set *threadUnsafeSet) AddIfNotContains(candidate, guard interface{}) bool {
+	if _, found := (*set)[guard]; found {
+		return false
+	}
+
+	return set.Add(candidate)
+}
+
+func (set *threadUnsafeSet) Freeze() Set {
+	return freeze(set)
+}
+
 func (set *threadUnsafeSet) Contains(keys ...interface{}) bool {
 	for _, key := range keys {
 		if _, ok := (*set)[key]; !ok {
@@ -71,8 +136,41 @@ func (set *threadUnsafeSet) Contains(keys ...interface{}) bool {
 	return true
 }
 
+func (set *threadUnsafeSet) Has(key interface{}) bool {
+	_, ok := (*set)[key]
+	return ok
+}
+
+func (set *threadUnsafeSet) ContainsAll(i ...interface{}) bool {
+	return set.Contains(i...)
+}
+
+func (set *threadUnsafeSet) ContainsAny(keys ...interface{}) bool {
+	for _, key := range keys {
+		if _, ok := (*set)[key]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (set *threadUnsafeSet) ContainsBy(value interface{}, keyFunc func(interface{}) interface{}) bool {
+	key := keyFunc(value)
+	for elem := range *set {
+		if keyFunc(elem) == key {
+			return true
+		}
+	}
+	return false
+}
+
 func (set *threadUnsafeSet) IsSubset(other Set) bool {
-	// _ = other.(*threadUnsafeSet)
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
+
 	if set.Cardinality() > other.Cardinality() {
 		return false
 	}
@@ -87,33 +185,84 @@ func (set *threadUnsafeSet) IsSubset(other Set) bool {
 }
 
 func (set *threadUnsafeSet) IsProperSubset(other Set) bool {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
+
 	return set.IsSubset(other) && !set.Equal(other)
 }
 
 func (set *threadUnsafeSet) IsSuperset(other Set) bool {
-	return other.IsSubset(set)
+	return nonNil(other).IsSubset(set)
 }
 
 func (set *threadUnsafeSet) IsProperSuperset(other Set) bool {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
+
 	return set.IsSuperset(other) && !set.Equal(other)
 }
 
+// addFrom adds every element of other to the set, reading other's map
+// directly when it is a thread-unsafe set as well.
+func (set *threadUnsafeSet) addFrom(other Set) {
+	if o, ok := other.(*threadUnsafeSet); ok {
+		for elem := range *o {
+			set.Add(elem)
+		}
+		return
+	}
+
+	other.Each(func(elem interface{}) bool {
+		set.Add(elem)
+		return false
+	})
+}
+
 func (set *threadUnsafeSet) Union(other Set) Set {
-	o := other.(*threadUnsafeSet)
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
 
 	union := newThreadUnsafeSet()
 	for elem := range *set {
 		union.Add(elem)
 	}
-	for elem := range *o {
+	union.addFrom(other)
+
+	return &union
+}
+
+func (set *threadUnsafeSet) UnionAll(others ...Set) Set {
+	others = nonNilAll(others)
+	others, unlock := rlockOthers(others...)
+	defer unlock()
+
+	size := len(*set)
+	for _, other := range others {
+		size += other.Cardinality()
+	}
+
+	union := newThreadUnsafeSetWithSize(size)
+	for elem := range *set {
 		union.Add(elem)
 	}
+	for _, other := range others {
+		union.addFrom(other)
+	}
 
 	return &union
 }
 
 func (set *threadUnsafeSet) Intersect(other Set) Set {
-	o := other.(*threadUnsafeSet)
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
 
 	intersection := newThreadUnsafeSet()
 	// loop over smaller set
@@ -123,19 +272,54 @@ func (set *threadUnsafeSet) Intersect(other Set) Set {
 				intersection.Add(elem)
 			}
 		}
-	} else {
+	} else if o, ok := other.(*threadUnsafeSet); ok {
 		for elem := range *o {
 			if set.Contains(elem) {
 				intersection.Add(elem)
 			}
 		}
+	} else {
+		other.Each(func(elem interface{}) bool {
+			if set.Contains(elem) {
+				intersection.Add(elem)
+			}
+			return false
+		})
+	}
+
+	return &intersection
+}
+
+func (set *threadUnsafeSet) IntersectAll(others ...Set) Set {
+	others = nonNilAll(others)
+	others, unlock := rlockOthers(others...)
+	defer unlock()
+
+	sets := make([]Set, 0, len(others)+1)
+	sets = append(sets, set)
+	sets = append(sets, others...)
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].Cardinality() < sets[j].Cardinality()
+	})
+
+	intersection := newThreadUnsafeSetWithSize(sets[0].Cardinality())
+	intersection.addFrom(sets[0])
+	for _, o := range sets[1:] {
+		if len(intersection) == 0 {
+			break
+		}
+		intersection.RetainAll(o)
 	}
 
 	return &intersection
 }
 
 func (set *threadUnsafeSet) Difference(other Set) Set {
-	// _ = other.(*threadUnsafeSet)
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
+
 	difference := newThreadUnsafeSet()
 	for elem := range *set {
 		if !other.Contains(elem) {
@@ -146,217 +330,1278 @@ func (set *threadUnsafeSet) Difference(other Set) Set {
 	return &difference
 }
 
+func (set *threadUnsafeSet) DifferenceAll(others ...Set) Set {
+	others = nonNilAll(others)
+	others, unlock := rlockOthers(others...)
+	defer unlock()
+
+	difference := newThreadUnsafeSet()
+L:
+	for elem := range *set {
+		for _, other := range others {
+			if other.Contains(elem) {
+				continue L
+			}
+		}
+		difference.Add(elem)
+	}
+
+	return &difference
+}
+
+func (set *threadUnsafeSet) Diff(other Set) (added Set, removed Set) {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
+
+	a, r := newThreadUnsafeSet(), newThreadUnsafeSet()
+	other.Each(func(elem interface{}) bool {
+		if _, found := (*set)[elem]; !found {
+			a.Add(elem)
+		}
+		return false
+	})
+	for elem := range *set {
+		if !other.Contains(elem) {
+			r.Add(elem)
+		}
+	}
+
+	return &a, &r
+}
+
+func (set *threadUnsafeSet) Merge(other Set) (union Set, common Set) {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
+
+	u, c := newThreadUnsafeSetWithSize(len(*set)), newThreadUnsafeSet()
+	for elem := range *set {
+		u.Add(elem)
+		if other.Contains(elem) {
+			c.Add(elem)
+		}
+	}
+	other.Each(func(elem interface{}) bool {
+		u.Add(elem)
+		return false
+	})
+
+	return &u, &c
+}
+
 func (set *threadUnsafeSet) SymmetricDifference(other Set) Set {
-	// _ = other.(*threadUnsafeSet)
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
 
-	sdo := set.Difference(other)
-	ods := other.Difference(set)
+	sd := newThreadUnsafeSet()
+	for elem := range *set {
+		if !other.Contains(elem) {
+			sd.Add(elem)
+		}
+	}
+	other.Each(func(elem interface{}) bool {
+		if !set.Contains(elem) {
+			sd.Add(elem)
+		}
+		return false
+	})
 
-	return sdo.Union(ods)
+	return &sd
 }
 
 func (set *threadUnsafeSet) Clear() {
 	*set = newThreadUnsafeSet()
 }
 
-func (set *threadUnsafeSet) Remove(i interface{}) {
-	delete(*set, i)
+func (set *threadUnsafeSet) ClearRetainingCapacity() {
+	set.clear()
 }
 
-func (set *threadUnsafeSet) Cardinality() int {
-	return len(*set)
+func (set *threadUnsafeSet) ReplaceAll(i ...interface{}) {
+	set.reset(i)
 }
 
-func (set *threadUnsafeSet) Length() int {
-	return len(*set)
+// clear empties the set in place, keeping the capacity of its map.
+func (set *threadUnsafeSet) clear() {
+	objects := *set
+	for elem := range objects {
+		delete(objects, elem)
+	}
 }
 
-func (set *threadUnsafeSet) Each(callback func(interface{}) bool) {
-	for elem := range *set {
-		if callback(elem) {
-			break
-		}
+// resetter is implemented by the sets of this package, which can replace
+// their contents in one step while keeping their allocated storage.
+type resetter interface {
+	reset(items []interface{})
+}
+
+// copyItems replaces the contents of dest with items.
+func copyItems(dest Set, items []interface{}) {
+	if r, ok := dest.(resetter); ok {
+		r.reset(items)
+		return
 	}
+
+	dest.Clear()
+	dest.AddAll(items...)
 }
 
-func (set *threadUnsafeSet) Iter() <-chan interface{} {
-	ch := make(chan interface{})
+func (set *threadUnsafeSet) reset(items []interface{}) {
+	set.clear()
+	for _, item := range items {
+		(*set)[item] = struct{}{}
+	}
+}
 
-	go func() {
+func (set *threadUnsafeSet) CopyTo(dest Set) {
+	switch d := dest.(type) {
+	case *threadUnsafeSet:
+		if d == set {
+			return
+		}
+		d.clear()
 		for elem := range *set {
-			ch <- elem
+			(*d)[elem] = struct{}{}
 		}
-		close(ch)
-	}()
+	case *threadSafeSet:
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
 
-	return ch
+		set.CopyTo(&d.objects)
+	default:
+		copyItems(dest, set.ToSlice())
+	}
 }
 
-func (set *threadUnsafeSet) Iterator() *Iterator {
-	iterator, ch, stopCh := newIterator()
+func (set *threadUnsafeSet) Remove(i interface{}) {
+	delete(*set, i)
+}
 
-	go func() {
-	L:
-		for elem := range *set {
-			select {
-			case <-stopCh:
-				break L
-			case ch <- elem:
-			}
+func (set *threadUnsafeSet) RemoveAll(keys ...interface{}) int {
+	removed := 0
+	for _, key := range keys {
+		if _, found := (*set)[key]; found {
+			delete(*set, key)
+			removed++
 		}
-		close(ch)
-	}()
+	}
 
-	return iterator
+	return removed
 }
 
-func (set *threadUnsafeSet) Equal(other Set) bool {
-	// _ = other.(*threadUnsafeSet)
-	if set.Cardinality() != other.Cardinality() {
-		return false
-	}
+func (set *threadUnsafeSet) RetainAll(other Set) int {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
 
+	removed := 0
 	for elem := range *set {
 		if !other.Contains(elem) {
-			return false
+			delete(*set, elem)
+			removed++
 		}
 	}
 
-	return true
+	return removed
 }
 
-func (set *threadUnsafeSet) Clone() Set {
-	clonedSet := newThreadUnsafeSet()
-	for elem := range *set {
-		clonedSet.Add(elem)
-	}
+func (set *threadUnsafeSet) AddSet(other Set) int {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
 
-	return &clonedSet
+	added := 0
+	other.Each(func(elem interface{}) bool {
+		if set.Add(elem) {
+			added++
+		}
+		return false
+	})
+
+	return added
 }
 
-func (set *threadUnsafeSet) String() string {
-	items := make([]string, 0, len(*set))
+func (set *threadUnsafeSet) SubtractSet(other Set) int {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
+
+	removed := 0
 	for elem := range *set {
-		items = append(items, fmt.Sprintf("%v", elem))
+		if other.Contains(elem) {
+			delete(*set, elem)
+			removed++
+		}
 	}
 
-	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+	return removed
 }
 
-// String outputs a 2-tuple in the form "(A, B)".
-func (pair OrderedPair) String() string {
-	return fmt.Sprintf("(%v, %v)", pair.First, pair.Second)
+func (set *threadUnsafeSet) Cardinality() int {
+	return len(*set)
 }
 
-func (set *threadUnsafeSet) Pop() interface{} {
-	for item := range *set {
-		delete(*set, item)
-		return item
-	}
-
-	return nil
+func (set *threadUnsafeSet) Length() int {
+	return len(*set)
 }
 
-func (set *threadUnsafeSet) PowerSet() Set {
-	powSet := NewThreadUnsafeSet()
-	nullset := newThreadUnsafeSet()
-	powSet.Add(&nullset)
+func (set *threadUnsafeSet) Intersects(other Set) bool {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
 
-	for item := range *set {
-		u := newThreadUnsafeSet()
-		j := powSet.Iter()
-		for er := range j {
-			p := newThreadUnsafeSet()
-			if reflect.TypeOf(er).Name() == "" {
-				k := er.(*threadUnsafeSet)
-				for ek := range *(k) {
-					p.Add(ek)
-				}
-			} else {
-				p.Add(er)
+	if o, ok := other.(*threadUnsafeSet); ok {
+		small, large := *set, *o
+		if len(small) > len(large) {
+			small, large = large, small
+		}
+		for elem := range small {
+			if _, found := large[elem]; found {
+				return true
 			}
-			p.Add(item)
-			u.Add(&p)
 		}
+		return false
+	}
 
-		powSet = powSet.Union(&u)
+	if set.Cardinality() > other.Cardinality() {
+		return other.Any(func(elem interface{}) bool {
+			return set.Contains(elem)
+		})
 	}
 
-	return powSet
+	for elem := range *set {
+		if other.Contains(elem) {
+			return true
+		}
+	}
+	return false
 }
 
-func (set *threadUnsafeSet) CartesianProduct(other Set) Set {
-	o := other.(*threadUnsafeSet)
-	cartProduct := NewThreadUnsafeSet()
+func (set *threadUnsafeSet) IntersectCardinality(other Set) int {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
 
-	for i := range *set {
-		for j := range *o {
-			elem := OrderedPair{First: i, Second: j}
-			cartProduct.Add(elem)
+	if o, ok := other.(*threadUnsafeSet); ok {
+		small, large := *set, *o
+		if len(small) > len(large) {
+			small, large = large, small
+		}
+		n := 0
+		for elem := range small {
+			if _, found := large[elem]; found {
+				n++
+			}
 		}
+		return n
 	}
 
-	return cartProduct
-}
+	if set.Cardinality() > other.Cardinality() {
+		return other.Count(func(elem interface{}) bool {
+			return set.Contains(elem)
+		})
+	}
 
-func (set *threadUnsafeSet) ToSlice() []interface{} {
-	keys := make([]interface{}, 0, set.Cardinality())
+	n := 0
 	for elem := range *set {
-		keys = append(keys, elem)
+		if other.Contains(elem) {
+			n++
+		}
 	}
+	return n
+}
 
-	return keys
+func (set *threadUnsafeSet) UnionCardinality(other Set) int {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
+
+	return set.Cardinality() + other.Cardinality() - set.IntersectCardinality(other)
 }
 
-func (set *threadUnsafeSet) Strings() []string {
-	keys := make([]string, 0, set.Length())
-	for elem := range *set {
-		// 是否需要对 int，float，error，bool 等可以转换为 string 的类型进行转换？
-		switch elem.(type) {
-		case string:
-			keys = append(keys, elem.(string))
+func (set *threadUnsafeSet) IsDisjoint(other Set) bool {
+	other = nonNil(other)
+	return !set.Intersects(other)
+}
+
+func (set *threadUnsafeSet) JaccardSimilarity(other Set) float64 {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+
+	return jaccard(set.overlap(objects[0]))
+}
+
+func (set *threadUnsafeSet) OverlapCoefficient(other Set) float64 {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+
+	return overlapCoefficient(set.overlap(objects[0]))
+}
+
+// overlap returns the number of elements the set has in common with
+// other, along with the cardinality of both, probing the larger set with
+// the elements of the smaller one. A thread-safe other must have been
+// unwrapped with rlockOthers.
+func (set *threadUnsafeSet) overlap(other Set) (common, size, otherSize int) {
+	o := snapshotOf(other)
+
+	small, large := *set, *o
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	for elem := range small {
+		if _, found := large[elem]; found {
+			common++
 		}
 	}
+	return common, len(*set), len(*o)
+}
 
-	return keys
+func overlapCoefficient(common, size, otherSize int) float64 {
+	if size == 0 && otherSize == 0 {
+		return 1
+	}
+	if otherSize < size {
+		size = otherSize
+	}
+	if size == 0 {
+		return 0
+	}
+	return float64(common) / float64(size)
 }
 
-// MarshalJSON creates a JSON array from the set, it marshals all elements
-func (set *threadUnsafeSet) MarshalJSON() ([]byte, error) {
-	items := make([]string, 0, set.Cardinality())
+func jaccard(common, size, otherSize int) float64 {
+	union := size + otherSize - common
+	if union == 0 {
+		return 1
+	}
+	return float64(common) / float64(union)
+}
 
+func (set *threadUnsafeSet) Each(callback func(interface{}) bool) {
 	for elem := range *set {
-		b, err := json.Marshal(elem)
-		if err != nil {
-			return nil, err
+		if callback(elem) {
+			break
 		}
-
-		items = append(items, string(b))
 	}
+}
 
-	return []byte(fmt.Sprintf("[%s]", strings.Join(items, ","))), nil
+func (set *threadUnsafeSet) ForEach(callback func(interface{})) {
+	set.Each(func(elem interface{}) bool {
+		callback(elem)
+		return false
+	})
 }
 
-// UnmarshalJSON recreates a set from a JSON array, it only decodes
-// primitive types. Numbers are decoded as json.Number.
-func (set *threadUnsafeSet) UnmarshalJSON(b []byte) error {
-	var i []interface{}
+func (set *threadUnsafeSet) ForEachUntil(callback func(interface{}) bool) {
+	set.Each(func(elem interface{}) bool {
+		return !callback(elem)
+	})
+}
 
-	d := json.NewDecoder(bytes.NewReader(b))
-	d.UseNumber()
-	err := d.Decode(&i)
+func (set *threadUnsafeSet) IterateIndexed(callback func(index int, elem interface{}) bool) {
+	index := 0
+	set.Each(func(elem interface{}) bool {
+		stop := !callback(index, elem)
+		index++
+		return stop
+	})
+}
+
+func (set *threadUnsafeSet) EachParallel(workers int, callback func(interface{})) {
+	eachParallel(set.ToSlice(), workers, func(elem interface{}) error {
+		callback(elem)
+		return nil
+	})
+}
+
+func (set *threadUnsafeSet) EachParallelErr(workers int, callback func(interface{}) error) []error {
+	return eachParallel(set.ToSlice(), workers, callback)
+}
+
+func (set *threadUnsafeSet) Map(transform func(interface{}) interface{}) Set {
+	mapped := newThreadUnsafeSetWithSize(len(*set))
+	for elem := range *set {
+		mapped.Add(transform(elem))
+	}
+
+	return &mapped
+}
+
+func (set *threadUnsafeSet) Partition(predicate func(interface{}) bool) (Set, Set) {
+	matched, rest := newThreadUnsafeSet(), newThreadUnsafeSet()
+	for elem := range *set {
+		if predicate(elem) {
+			matched.Add(elem)
+		} else {
+			rest.Add(elem)
+		}
+	}
+
+	return &matched, &rest
+}
+
+func (set *threadUnsafeSet) GroupBy(keyFunc func(interface{}) interface{}) map[interface{}]Set {
+	groups := make(map[interface{}]Set)
+	for elem := range *set {
+		key := keyFunc(elem)
+		group, found := groups[key]
+		if !found {
+			group = NewThreadUnsafeSet()
+			groups[key] = group
+		}
+		group.Add(elem)
+	}
+
+	return groups
+}
+
+func (set *threadUnsafeSet) Reduce(initial interface{}, accumulate func(acc, elem interface{}) interface{}) interface{} {
+	acc := initial
+	for elem := range *set {
+		acc = accumulate(acc, elem)
+	}
+
+	return acc
+}
+
+func (set *threadUnsafeSet) Any(predicate func(interface{}) bool) bool {
+	for elem := range *set {
+		if predicate(elem) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (set *threadUnsafeSet) All(predicate func(interface{}) bool) bool {
+	for elem := range *set {
+		if !predicate(elem) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (set *threadUnsafeSet) Min(less func(a, b interface{}) bool) (interface{}, bool) {
+	return minimum(less, set.Each)
+}
+
+func (set *threadUnsafeSet) Max(less func(a, b interface{}) bool) (interface{}, bool) {
+	return minimum(func(a, b interface{}) bool { return less(b, a) }, set.Each)
+}
+
+func (set *threadUnsafeSet) Count(predicate func(interface{}) bool) int {
+	count := 0
+	for elem := range *set {
+		if predicate(elem) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (set *threadUnsafeSet) Iter() <-chan interface{} {
+	ch := make(chan interface{}, iterBufferSize)
+
+	go func() {
+		for elem := range *set {
+			ch <- elem
+		}
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (set *threadUnsafeSet) IterContext(ctx context.Context) <-chan interface{} {
+	ch := make(chan interface{}, iterBufferSize)
+
+	go func() {
+	L:
+		for elem := range *set {
+			select {
+			case <-ctx.Done():
+				break L
+			case ch <- elem:
+			}
+		}
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (set *threadUnsafeSet) IterSnapshot() <-chan interface{} {
+	return iterSlice(set.Elements())
+}
+
+func (set *threadUnsafeSet) IterSorted(less func(a, b interface{}) bool) <-chan interface{} {
+	return iterSlice(set.ToSortedSlice(less))
+}
+
+func (set *threadUnsafeSet) Tee() (<-chan interface{}, <-chan interface{}) {
+	return teeSlice(set.Elements())
+}
+
+func (set *threadUnsafeSet) Iterator() *Iterator {
+	iterator, ch, stopCh := newIterator()
+
+	go func() {
+	L:
+		for elem := range *set {
+			select {
+			case <-stopCh:
+				break L
+			case ch <- elem:
+			}
+		}
+		close(ch)
+	}()
+
+	return iterator
+}
+
+func (set *threadUnsafeSet) Elements() []interface{} {
+	return set.ToSlice()
+}
+
+func (set *threadUnsafeSet) Equal(other Set) bool {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
+
+	if set.Cardinality() != other.Cardinality() {
+		return false
+	}
+
+	for elem := range *set {
+		if !other.Contains(elem) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (set *threadUnsafeSet) EqualFunc(other Set, eq func(a, b interface{}) bool) bool {
+	other = nonNil(other)
+	return equalFunc(set.ToSlice(), other.ToSlice(), eq)
+}
+
+func (set *threadUnsafeSet) EqualUsing(other Set, eq func(a, b interface{}) bool) bool {
+	other = nonNil(other)
+	return equalUsing(set.ToSlice(), other.ToSlice(), eq)
+}
+
+// equalFunc reports whether a and b have the same length and every element
+// of a is eq to an element of b.
+func equalFunc(a, b []interface{}, eq func(a, b interface{}) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, i := range a {
+		found := false
+		for _, j := range b {
+			if eq(i, j) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equalUsing reports whether a and b have the same length and every element
+// of a is eq to a distinct element of b.
+func equalUsing(a, b []interface{}, eq func(a, b interface{}) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	// Every element of b matches at most one element of a, otherwise two
+	// elements of a that are eq to each other could both match the same
+	// element of b and leave another one unmatched.
+	matched := make([]bool, len(b))
+	for _, i := range a {
+		found := false
+		for k, j := range b {
+			if !matched[k] && eq(i, j) {
+				matched[k] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (set *threadUnsafeSet) Clone() Set {
+	clonedSet := newThreadUnsafeSetWithSize(len(*set))
+	for elem := range *set {
+		clonedSet.Add(elem)
+	}
+
+	return &clonedSet
+}
+
+func (set *threadUnsafeSet) Snapshot() Set {
+	return set.Clone()
+}
+
+func (set *threadUnsafeSet) String() string {
+	items := make([]string, 0, len(*set))
+	for elem := range *set {
+		items = append(items, fmt.Sprintf("%v", elem))
+	}
+
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+// String outputs a 2-tuple in the form "(A, B)".
+func (pair OrderedPair) String() string {
+	return fmt.Sprintf("(%v, %v)", pair.First, pair.Second)
+}
+
+func (set *threadUnsafeSet) Pop() interface{} {
+	for item := range *set {
+		delete(*set, item)
+		return item
+	}
+
+	return nil
+}
+
+func (set *threadUnsafeSet) TryPop() (interface{}, bool) {
+	for item := range *set {
+		delete(*set, item)
+		return item, true
+	}
+
+	return nil, false
+}
+
+func (set *threadUnsafeSet) Peek() (interface{}, bool) {
+	for item := range *set {
+		return item, true
+	}
+
+	return nil, false
+}
+
+func (set *threadUnsafeSet) Sample(n int) Set {
+	return set.SampleWithRand(n, nil)
+}
+
+func (set *threadUnsafeSet) SampleWithRand(n int, r *rand.Rand) Set {
+	picked := reservoir(n, r, set.Each)
+
+	sample := newThreadUnsafeSetWithSize(len(picked))
+	for _, elem := range picked {
+		sample.Add(elem)
+	}
+	return &sample
+}
+
+// minimum returns the first of the smallest elements visited by each
+// according to less, and whether each visited any element.
+func minimum(less func(a, b interface{}) bool, each func(func(interface{}) bool)) (interface{}, bool) {
+	var min interface{}
+	found := false
+	each(func(elem interface{}) bool {
+		if !found || less(elem, min) {
+			min, found = elem, true
+		}
+		return false
+	})
+
+	return min, found
+}
+
+// reservoir picks up to n of the elements visited by each uniformly at
+// random in a single pass, using Algorithm R. A nil r draws from the
+// global source of math/rand.
+func reservoir(n int, r *rand.Rand, each func(func(interface{}) bool)) []interface{} {
+	if n < 0 {
+		n = 0
+	}
+	intn := rand.Intn
+	if r != nil {
+		intn = r.Intn
+	}
+
+	var picked []interface{}
+	seen := 0
+	each(func(elem interface{}) bool {
+		if seen < n {
+			picked = append(picked, elem)
+		} else if j := intn(seen + 1); j < n {
+			picked[j] = elem
+		}
+		seen++
+		return false
+	})
+	return picked
+}
+
+func (set *threadUnsafeSet) PowerSet() Set {
+	// Every item doubles the subsets found so far: each of them is kept,
+	// and extended with the item. Keeping them in a typed slice avoids
+	// unboxing them from interface{} values.
+	nullset := newThreadUnsafeSet()
+	subsets := make([]*threadUnsafeSet, 1, 1<<uint(len(*set)))
+	subsets[0] = &nullset
+
+	for item := range *set {
+		for _, subset := range subsets {
+			extended := newThreadUnsafeSetWithSize(len(*subset) + 1)
+			for elem := range *subset {
+				extended[elem] = struct{}{}
+			}
+			extended[item] = struct{}{}
+			subsets = append(subsets, &extended)
+		}
+	}
+
+	powSet := newThreadUnsafeSetWithSize(len(subsets))
+	for _, subset := range subsets {
+		powSet[subset] = struct{}{}
+	}
+
+	return &powSet
+}
+
+func (set *threadUnsafeSet) PowerSetIterator() *Iterator {
+	return powerSetIterator(set.ToSlice(), func(items []interface{}) Set {
+		subset := newThreadUnsafeSetWithSize(len(items))
+		for _, item := range items {
+			subset[item] = struct{}{}
+		}
+		return &subset
+	})
+}
+
+func (set *threadUnsafeSet) CartesianProduct(other Set) Set {
+	other = nonNil(other)
+	objects, unlock := rlockOthers(other)
+	defer unlock()
+	other = objects[0]
+
+	cartProduct := NewThreadUnsafeSet()
+
+	for i := range *set {
+		other.Each(func(j interface{}) bool {
+			elem := OrderedPair{First: i, Second: j}
+			cartProduct.Add(elem)
+			return false
+		})
+	}
+
+	return cartProduct
+}
+
+func (set *threadUnsafeSet) CartesianProductIterator(other Set) *PairIterator {
+	other = nonNil(other)
+	return cartesianProductIterator(set.ToSlice(), other.ToSlice())
+}
+
+func (set *threadUnsafeSet) ToSlice() []interface{} {
+	keys := make([]interface{}, 0, set.Cardinality())
+	for elem := range *set {
+		keys = append(keys, elem)
+	}
+
+	return keys
+}
+
+func (set *threadUnsafeSet) Chunk(size int) [][]interface{} {
+	return chunk(set.ToSlice(), size)
+}
+
+// chunk splits items into slices of at most size items, sharing the
+// backing array of items.
+func chunk(items []interface{}, size int) [][]interface{} {
+	if size < 1 {
+		size = 1
+	}
+
+	chunks := make([][]interface{}, 0, (len(items)+size-1)/size)
+	for len(items) > size {
+		chunks = append(chunks, items[:size:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		chunks = append(chunks, items)
+	}
+	return chunks
+}
+
+func (set *threadUnsafeSet) ToMap() map[interface{}]struct{} {
+	m := make(map[interface{}]struct{}, len(*set))
+	for elem := range *set {
+		m[elem] = struct{}{}
+	}
+
+	return m
+}
+
+func (set *threadUnsafeSet) Drain() []interface{} {
+	items := set.ToSlice()
+	*set = newThreadUnsafeSet()
+
+	return items
+}
+
+func (set *threadUnsafeSet) ToSortedSlice(less func(a, b interface{}) bool) []interface{} {
+	keys := set.ToSlice()
+	sort.Slice(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+
+	return keys
+}
+
+func (set *threadUnsafeSet) Hash() uint64 {
+	var h uint64
+	for elem := range *set {
+		h ^= mixHash(hashElement(elem))
+	}
+
+	return h
+}
+
+// mixHash scrambles the bits of an element hash, using the finalizer of
+// SplitMix64, before it is combined with the other hashes of a set.
+func mixHash(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+func (set *threadUnsafeSet) StringSorted() string {
+	items := set.StringsWithConversion()
+	sort.Strings(items)
+
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+func (set *threadUnsafeSet) Strings() []string {
+	keys := make([]string, 0, set.Length())
+	for elem := range *set {
+		// Only string elements are kept, StringsWithConversion converts the others.
+		switch elem.(type) {
+		case string:
+			keys = append(keys, elem.(string))
+		}
+	}
+
+	return keys
+}
+
+func (set *threadUnsafeSet) StringsWithConversion() []string {
+	keys := make([]string, 0, set.Length())
+	for elem := range *set {
+		keys = append(keys, fmt.Sprintf("%v", elem))
+	}
+
+	return keys
+}
+
+func (set *threadUnsafeSet) Ints() []int {
+	keys := make([]int, 0, set.Length())
+	for elem := range *set {
+		if i, ok := elem.(int); ok {
+			keys = append(keys, i)
+		}
+	}
+
+	return keys
+}
+
+func (set *threadUnsafeSet) Float64s() []float64 {
+	keys := make([]float64, 0, set.Length())
+	for elem := range *set {
+		if f, ok := elem.(float64); ok {
+			keys = append(keys, f)
+		}
+	}
+
+	return keys
+}
+
+// marshalElements returns the JSON encoding of every element.
+func (set *threadUnsafeSet) marshalElements() ([]string, error) {
+	items := make([]string, 0, set.Cardinality())
+
+	for elem := range *set {
+		b, err := json.Marshal(elem)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, string(b))
+	}
+
+	return items, nil
+}
+
+// MarshalJSON creates a JSON array from the set, it marshals all elements
+func (set *threadUnsafeSet) MarshalJSON() ([]byte, error) {
+	items, err := set.marshalElements()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(fmt.Sprintf("[%s]", strings.Join(items, ","))), nil
+}
+
+// MarshalJSONSorted creates a JSON array from the set with its elements
+// sorted by their encoded bytes, which gives stable output even for sets
+// of mixed types.
+func (set *threadUnsafeSet) MarshalJSONSorted() ([]byte, error) {
+	items, err := set.marshalElements()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(items)
+
+	return []byte(fmt.Sprintf("[%s]", strings.Join(items, ","))), nil
+}
+
+// UnmarshalJSON recreates a set from a JSON array, it only decodes
+// primitive types and skips nested arrays and objects, which
+// UnmarshalJSONInto can decode. Numbers are decoded as json.Number.
+func (set *threadUnsafeSet) UnmarshalJSON(b []byte) error {
+	items, err := unmarshalJSONElements(b)
 	if err != nil {
 		return err
 	}
 
+	set.AddAll(items...)
+
+	return nil
+}
+
+// unmarshalJSONElements decodes the primitive items of a JSON array in
+// the order they appear.
+func unmarshalJSONElements(b []byte) ([]interface{}, error) {
+	var i []interface{}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	err := d.Decode(&i)
+	if err != nil {
+		return nil, err
+	}
+
+	items := i[:0]
 	for _, v := range i {
 		switch t := v.(type) {
 		case []interface{}, map[string]interface{}:
 			continue
 		default:
-			set.Add(t)
+			items = append(items, t)
+		}
+	}
+
+	return items, nil
+}
+
+// UnmarshalJSONWith adds the elements of a JSON array, converting numbers
+// with convertNumber.
+func (set *threadUnsafeSet) UnmarshalJSONWith(b []byte, convertNumber func(json.Number) interface{}) error {
+	items, err := unmarshalJSONElementsWith(b, convertNumber)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+
+	return nil
+}
+
+// unmarshalJSONElementsWith decodes the primitive items of a JSON array
+// like unmarshalJSONElements, passing numbers through convertNumber.
+func unmarshalJSONElementsWith(b []byte, convertNumber func(json.Number) interface{}) ([]interface{}, error) {
+	items, err := unmarshalJSONElements(b)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range items {
+		if n, ok := item.(json.Number); ok {
+			items[i] = convertNumber(n)
+		}
+	}
+
+	return items, nil
+}
+
+// UnmarshalJSONInto adds the elements of a JSON array, each decoded into
+// a value built by prototype.
+func (set *threadUnsafeSet) UnmarshalJSONInto(b []byte, prototype func() interface{}) error {
+	items, err := unmarshalJSONElementsInto(b, prototype)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+
+	return nil
+}
+
+// unmarshalJSONElementsInto decodes every item of a JSON array into a new
+// value returned by prototype, see Set.UnmarshalJSONInto.
+func unmarshalJSONElementsInto(b []byte, prototype func() interface{}) ([]interface{}, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	items := make([]interface{}, 0, len(raw))
+	for _, r := range raw {
+		p := prototype()
+		v := reflect.ValueOf(p)
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			return nil, newTypeMismatchError("UnmarshalJSONInto", "a non-nil pointer from prototype", p)
+		}
+		if err := json.Unmarshal(r, p); err != nil {
+			return nil, err
+		}
+
+		if s, ok := p.(Set); ok {
+			items = append(items, s)
+			continue
+		}
+		if !v.Elem().Type().Comparable() {
+			return nil, fmt.Errorf("mapset: cannot add elements of type %s to a set", v.Elem().Type())
+		}
+		items = append(items, v.Elem().Interface())
+	}
+
+	return items, nil
+}
+
+// GobEncode encodes the elements of the set as a gob stream. Element
+// types are preserved, but like any value stored in an interface,
+// non-builtin element types must be registered with gob.Register.
+func (set *threadUnsafeSet) GobEncode() ([]byte, error) {
+	return gobEncodeElements(set.ToSlice())
+}
+
+// GobDecode adds the elements of a gob stream produced by GobEncode
+// to the set.
+func (set *threadUnsafeSet) GobDecode(b []byte) error {
+	items, err := gobDecodeElements(b)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+
+	return nil
+}
+
+// MarshalBinary encodes the elements of the set in a compact binary form,
+// a count followed by every element tagged with its type. Builtin element
+// types are encoded directly, other types are gob encoded and, as with
+// GobEncode, must be registered with gob.Register.
+func (set *threadUnsafeSet) MarshalBinary() ([]byte, error) {
+	return marshalBinaryElements(set.ToSlice())
+}
+
+// UnmarshalBinary adds the elements of the binary form produced by
+// MarshalBinary to the set.
+func (set *threadUnsafeSet) UnmarshalBinary(data []byte) error {
+	items, err := unmarshalBinaryElements(data)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+
+	return nil
+}
+
+// MarshalXML encodes the set as a set element holding an item element per
+// element, see xml.go for the details. Only elements of a builtin
+// boolean, numeric or string type can be encoded.
+func (set *threadUnsafeSet) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalXMLElements(e, start, set.ToSlice())
+}
+
+// UnmarshalXML adds the elements of the XML form produced by MarshalXML
+// to the set.
+func (set *threadUnsafeSet) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	items, err := unmarshalXMLElements(d, start)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+
+	return nil
+}
+
+func gobEncodeElements(items []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(items); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func gobDecodeElements(b []byte) ([]interface{}, error) {
+	var items []interface{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (set *threadUnsafeSet) MarshalText() ([]byte, error) {
+	return marshalTextElements(set.StringsWithConversion()), nil
+}
+
+func (set *threadUnsafeSet) UnmarshalText(text []byte) error {
+	items, err := unmarshalTextElements(text)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+
+	return nil
+}
+
+const (
+	textSeparator = '|'
+	textEscape    = '\\'
+)
+
+// marshalTextElements escapes, sorts and joins items, see Set.MarshalText.
+func marshalTextElements(items []string) []byte {
+	for i, item := range items {
+		var b strings.Builder
+		for _, r := range item {
+			if r == textSeparator || r == textEscape {
+				b.WriteRune(textEscape)
+			}
+			b.WriteRune(r)
+		}
+		items[i] = b.String()
+	}
+	sort.Strings(items)
+
+	return []byte(strings.Join(items, string(textSeparator)))
+}
+
+// unmarshalTextElements splits text produced by marshalTextElements back
+// into its unescaped items.
+func unmarshalTextElements(text []byte) ([]interface{}, error) {
+	if len(text) == 0 {
+		return nil, nil
+	}
+
+	var items []interface{}
+	var item []byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case textEscape:
+			i++
+			if i == len(text) || (text[i] != textSeparator && text[i] != textEscape) {
+				return nil, fmt.Errorf("mapset: invalid escape at offset %d of set text %q", i-1, text)
+			}
+			item = append(item, text[i])
+		case textSeparator:
+			items = append(items, string(item))
+			item = item[:0]
+		default:
+			item = append(item, c)
 		}
 	}
 
+	return append(items, string(item)), nil
+}
+
+// WriteCSV writes the elements of the set as single-column CSV rows.
+func (set *threadUnsafeSet) WriteCSV(w io.Writer) error {
+	return writeCSVElements(w, set.StringsWithConversion())
+}
+
+// ReadCSV adds every field of the CSV rows read from r to the set.
+func (set *threadUnsafeSet) ReadCSV(r io.Reader) error {
+	items, err := readCSVElements(r)
+	if err != nil {
+		return err
+	}
+
+	set.AddAll(items...)
+
 	return nil
 }
+
+func writeCSVElements(w io.Writer, items []string) error {
+	cw := csv.NewWriter(w)
+	for _, item := range items {
+		if err := cw.Write([]string{item}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// readCSVElements returns every field of the CSV rows read from r, rows
+// may have any number of fields.
+func readCSVElements(r io.Reader) ([]interface{}, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	var items []interface{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, field := range record {
+			items = append(items, field)
+		}
+	}
+}
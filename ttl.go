@@ -0,0 +1,509 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"container/heap"
+	"encoding/xml"
+	"time"
+)
+
+// TTLSet is a Set whose elements expire, see NewTTLSet.
+type TTLSet interface {
+	Set
+
+	// Adds an element that expires ttl from now,
+	// instead of after the default TTL of the set.
+	// A ttl of 0 or less means that the element
+	// never expires. Adding an element that is
+	// already in the set returns false and resets
+	// its expiry.
+	AddWithTTL(elem interface{}, ttl time.Duration) bool
+
+	// Removes the expired elements from the set
+	// and returns how many were removed.
+	Purge() int
+}
+
+// NewTTLSet creates and returns a reference to an empty set whose
+// elements expire defaultTTL after they were added, or after the TTL
+// given to AddWithTTL; a TTL of 0 or less means that elements never
+// expire. Adding an element that is already in the set resets its
+// expiry. Operations on the resulting set are thread-safe.
+//
+// Expired elements are removed lazily, without a background goroutine:
+// Contains, ContainsAll and ContainsAny report them as missing, and the
+// operations adding or removing elements, ContainsBy, Any, All, Count,
+// Cardinality, Length, Each, Iter, Iterator, ToSlice, Elements, String,
+// Equal, Union, Clone and the encoders remove them first. Other
+// operations, such as Intersect or IsSubset, see the elements that
+// expired since they last ran; call Purge first when that matters.
+//
+// Clone and Snapshot return a TTL set keeping the expiry of every
+// element, other operations deriving a new set return ordinary
+// thread-safe sets, whose elements do not expire.
+func NewTTLSet(defaultTTL time.Duration) TTLSet {
+	return newTTLSet(defaultTTL)
+}
+
+// ttlSet is the thread-safe set returned by NewTTLSet. It embeds the
+// thread-safe set holding its elements, and records the expiry of the
+// elements that expire both in expires, by element, and in deadlines, a
+// min-heap of the same expiries, all guarded by the same lock. Purging
+// pops the expired entries off the heap, so it costs nothing until an
+// element expires, and then only as much as the elements that expired.
+type ttlSet struct {
+	setWrapper
+	objects   *threadSafeSet
+	ttl       time.Duration
+	expires   map[interface{}]*expiry
+	deadlines expiryHeap
+	now       func() time.Time
+}
+
+// expiry is the deadline of an element, and its index in the heap of
+// deadlines of its set.
+type expiry struct {
+	item     interface{}
+	deadline time.Time
+	index    int
+}
+
+// expiryHeap implements heap.Interface, keeping the earliest deadline
+// first.
+type expiryHeap []*expiry
+
+func (h expiryHeap) Len() int { return len(h) }
+
+func (h expiryHeap) Less(i, j int) bool { return h[i].deadline.Before(h[j].deadline) }
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	e := x.(*expiry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+func newTTLSet(ttl time.Duration) *ttlSet {
+	objects := newThreadSafeSet()
+	set := &ttlSet{
+		objects: &objects,
+		ttl:     ttl,
+		expires: make(map[interface{}]*expiry),
+		now:     time.Now,
+	}
+	set.setWrapper = setWrapper{Set: &objects, self: set}
+	return set
+}
+
+// insert adds items expiring ttl from now and returns how many were not
+// in the set yet, callers must hold the write lock.
+func (set *ttlSet) insert(ttl time.Duration, items ...interface{}) int {
+	now := set.now()
+	set.purge(now)
+
+	added := 0
+	for _, item := range items {
+		if set.objects.objects.Add(item) {
+			added++
+		}
+		if ttl <= 0 {
+			set.unexpire(item)
+			continue
+		}
+		set.expire(item, now.Add(ttl))
+	}
+	return added
+}
+
+// expire sets the deadline of item, callers must hold the write lock.
+func (set *ttlSet) expire(item interface{}, deadline time.Time) {
+	if e, found := set.expires[item]; found {
+		e.deadline = deadline
+		heap.Fix(&set.deadlines, e.index)
+		return
+	}
+
+	e := &expiry{item: item, deadline: deadline}
+	set.expires[item] = e
+	heap.Push(&set.deadlines, e)
+}
+
+// unexpire drops the deadline of item, if it has one, callers must hold
+// the write lock.
+func (set *ttlSet) unexpire(item interface{}) {
+	if e, found := set.expires[item]; found {
+		heap.Remove(&set.deadlines, e.index)
+		delete(set.expires, item)
+	}
+}
+
+// forget drops every deadline, callers must hold the write lock.
+func (set *ttlSet) forget() {
+	set.expires = make(map[interface{}]*expiry)
+	set.deadlines = nil
+}
+
+// purge removes the elements expired at now and returns how many were
+// removed, callers must hold the write lock.
+func (set *ttlSet) purge(now time.Time) int {
+	removed := 0
+	for len(set.deadlines) > 0 && !now.Before(set.deadlines[0].deadline) {
+		e := heap.Pop(&set.deadlines).(*expiry)
+		delete(set.expires, e.item)
+
+		// The entry of an element removed since it was added is left
+		// behind until it expires.
+		if _, found := set.objects.objects[e.item]; found {
+			delete(set.objects.objects, e.item)
+			removed++
+		}
+	}
+	return removed
+}
+
+// live says whether item is in the set and has not expired, callers must
+// hold the read lock.
+func (set *ttlSet) live(item interface{}, now time.Time) bool {
+	if !set.objects.objects.Contains(item) {
+		return false
+	}
+	e, expires := set.expires[item]
+	return !expires || now.Before(e.deadline)
+}
+
+func (set *ttlSet) Purge() int {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.purge(set.now())
+}
+
+func (set *ttlSet) Add(i interface{}) bool {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.insert(set.ttl, i) == 1
+}
+
+func (set *ttlSet) AddWithTTL(elem interface{}, ttl time.Duration) bool {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.insert(ttl, elem) == 1
+}
+
+func (set *ttlSet) AddAll(i ...interface{}) int {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	return set.insert(set.ttl, i...)
+}
+
+func (set *ttlSet) AddIfNotContains(candidate, guard interface{}) bool {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	if set.live(guard, set.now()) {
+		return false
+	}
+	return set.insert(set.ttl, candidate) == 1
+}
+
+func (set *ttlSet) Contains(i ...interface{}) bool {
+	set.objects.mutex.RLock()
+	defer set.objects.mutex.RUnlock()
+
+	now := set.now()
+	for _, item := range i {
+		if !set.live(item, now) {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *ttlSet) Has(i interface{}) bool {
+	set.objects.mutex.RLock()
+	defer set.objects.mutex.RUnlock()
+
+	return set.live(i, set.now())
+}
+
+func (set *ttlSet) ContainsAll(i ...interface{}) bool {
+	return set.Contains(i...)
+}
+
+func (set *ttlSet) ContainsAny(i ...interface{}) bool {
+	set.objects.mutex.RLock()
+	defer set.objects.mutex.RUnlock()
+
+	now := set.now()
+	for _, item := range i {
+		if set.live(item, now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (set *ttlSet) ContainsBy(value interface{}, keyFunc func(interface{}) interface{}) bool {
+	set.Purge()
+	return set.Set.ContainsBy(value, keyFunc)
+}
+
+func (set *ttlSet) Any(predicate func(interface{}) bool) bool {
+	set.Purge()
+	return set.Set.Any(predicate)
+}
+
+func (set *ttlSet) All(predicate func(interface{}) bool) bool {
+	set.Purge()
+	return set.Set.All(predicate)
+}
+
+func (set *ttlSet) Count(predicate func(interface{}) bool) int {
+	set.Purge()
+	return set.Set.Count(predicate)
+}
+
+func (set *ttlSet) Remove(i interface{}) {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.purge(set.now())
+	set.objects.objects.Remove(i)
+}
+
+func (set *ttlSet) RemoveAll(i ...interface{}) int {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.purge(set.now())
+	return set.objects.objects.RemoveAll(i...)
+}
+
+// RetainAll and SubtractSet copy other before taking the lock, as it may
+// be another TTL set, whose reads take its own write lock to purge it.
+func (set *ttlSet) RetainAll(other Set) int {
+	other = nonNil(other)
+	if other == Set(set) {
+		set.Purge()
+		return 0
+	}
+	snapshot := snapshotOf(other)
+
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.purge(set.now())
+	return set.objects.objects.RetainAll(snapshot)
+}
+
+func (set *ttlSet) SubtractSet(other Set) int {
+	other = nonNil(other)
+	if other == Set(set) {
+		return len(set.Drain())
+	}
+	snapshot := snapshotOf(other)
+
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.purge(set.now())
+	return set.objects.objects.SubtractSet(snapshot)
+}
+
+func (set *ttlSet) Clear() {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.objects.objects.clear()
+	set.forget()
+}
+
+func (set *ttlSet) ClearRetainingCapacity() {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.objects.objects.clear()
+	for item := range set.expires {
+		delete(set.expires, item)
+	}
+	for i := range set.deadlines {
+		set.deadlines[i] = nil
+	}
+	set.deadlines = set.deadlines[:0]
+}
+
+func (set *ttlSet) reset(items []interface{}) {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.objects.objects.clear()
+	set.forget()
+	set.insert(set.ttl, items...)
+}
+
+func (set *ttlSet) ReplaceAll(i ...interface{}) {
+	set.reset(i)
+}
+
+func (set *ttlSet) Pop() interface{} {
+	item, _ := set.TryPop()
+	return item
+}
+
+func (set *ttlSet) TryPop() (interface{}, bool) {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.purge(set.now())
+	return set.objects.objects.TryPop()
+}
+
+func (set *ttlSet) Drain() []interface{} {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.purge(set.now())
+	items := set.objects.objects.ToSlice()
+	set.objects.objects.clear()
+	set.forget()
+	return items
+}
+
+func (set *ttlSet) Cardinality() int {
+	set.Purge()
+	return set.Set.Cardinality()
+}
+
+func (set *ttlSet) Length() int {
+	return set.Cardinality()
+}
+
+func (set *ttlSet) Each(f func(interface{}) bool) {
+	set.Purge()
+	set.Set.Each(f)
+}
+
+func (set *ttlSet) Iter() <-chan interface{} {
+	set.Purge()
+	return set.Set.Iter()
+}
+
+func (set *ttlSet) Iterator() *Iterator {
+	set.Purge()
+	return set.Set.Iterator()
+}
+
+func (set *ttlSet) ToSlice() []interface{} {
+	set.Purge()
+	return set.Set.ToSlice()
+}
+
+func (set *ttlSet) Elements() []interface{} {
+	return set.ToSlice()
+}
+
+func (set *ttlSet) String() string {
+	set.Purge()
+	return set.Set.String()
+}
+
+// argument hands the thread-safe set of set to it in place of set itself,
+// which it handles being its own argument, rather than copying set.
+func (set *ttlSet) argument(other Set) Set {
+	if other == Set(set) {
+		return set.objects
+	}
+	return other
+}
+
+func (set *ttlSet) Equal(other Set) bool {
+	set.Purge()
+	return set.Set.Equal(set.argument(other))
+}
+
+func (set *ttlSet) Union(other Set) Set {
+	set.Purge()
+	return set.Set.Union(set.argument(other))
+}
+
+func (set *ttlSet) Clone() Set {
+	set.objects.mutex.Lock()
+	defer set.objects.mutex.Unlock()
+
+	set.purge(set.now())
+	clone := newTTLSet(set.ttl)
+	clone.now = set.now
+	clone.objects.objects = *set.objects.objects.Clone().(*threadUnsafeSet)
+	// Copying the heap as is keeps it ordered.
+	clone.deadlines = make(expiryHeap, len(set.deadlines))
+	for i, e := range set.deadlines {
+		c := *e
+		clone.deadlines[i] = &c
+		clone.expires[c.item] = &c
+	}
+	return clone
+}
+
+func (set *ttlSet) Snapshot() Set {
+	return set.Clone()
+}
+
+func (set *ttlSet) MarshalJSON() ([]byte, error) {
+	set.Purge()
+	return set.objects.MarshalJSON()
+}
+
+func (set *ttlSet) GobEncode() ([]byte, error) {
+	set.Purge()
+	return set.objects.GobEncode()
+}
+
+func (set *ttlSet) MarshalBinary() ([]byte, error) {
+	set.Purge()
+	return set.objects.MarshalBinary()
+}
+
+func (set *ttlSet) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	set.Purge()
+	return set.objects.MarshalXML(e, start)
+}
@@ -0,0 +1,211 @@
+package mapset
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestTTLSet returns a TTL set reading the time from a fake clock, and
+// a function advancing that clock.
+func newTestTTLSet(ttl time.Duration) (*ttlSet, func(time.Duration)) {
+	now := time.Unix(0, 0)
+	var mutex sync.Mutex
+
+	set := newTTLSet(ttl)
+	set.now = func() time.Time {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return now
+	}
+	return set, func(d time.Duration) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		now = now.Add(d)
+	}
+}
+
+func Test_TTLSetExpiry(t *testing.T) {
+	a, advance := newTestTTLSet(time.Minute)
+
+	a.AddAll(1, 2)
+	a.AddWithTTL(3, time.Hour)
+	a.AddWithTTL(4, 0)
+	if a.Cardinality() != 4 || !a.Contains(1, 2, 3, 4) {
+		t.Fatalf("every element should be live, got %v", a)
+	}
+
+	advance(30 * time.Second)
+	if a.Add(1) {
+		t.Error("adding a live element should return false")
+	}
+
+	advance(45 * time.Second)
+	if a.Contains(2) || a.ContainsAny(2, 5) || !a.Contains(1, 3, 4) || a.Has(2) || !a.Has(1) {
+		t.Errorf("2 should have expired, and re-adding 1 should have reset its expiry, got %v", a)
+	}
+	if a.Cardinality() != 3 || !a.Equal(NewSet(1, 3, 4)) {
+		t.Errorf("Cardinality should only count live elements, got %v", a)
+	}
+
+	advance(time.Minute)
+	if n := a.Purge(); n != 1 || a.Contains(1) {
+		t.Errorf("Purge should remove 1, removed %d", n)
+	}
+
+	advance(time.Hour)
+	if items := a.ToSlice(); len(items) != 1 || items[0] != 4 {
+		t.Errorf("only the element without a TTL should remain, got %v", items)
+	}
+
+	if !a.Add(2) || a.AddIfNotContains(5, 2) {
+		t.Error("an expired element should be added again")
+	}
+}
+
+func Test_TTLSetStaggeredExpiry(t *testing.T) {
+	a, advance := newTestTTLSet(time.Minute)
+
+	for _, i := range rand.Perm(100) {
+		a.AddWithTTL(i, time.Duration(i+1)*time.Second)
+	}
+	a.AddWithTTL(50, 0)
+	a.AddWithTTL(10, time.Hour)
+	a.AddWithTTL(10, 200*time.Second)
+	if len(a.deadlines) != 99 || len(a.expires) != 99 {
+		t.Fatalf("every element but 50 should have a single deadline, got %d and %d", len(a.deadlines), len(a.expires))
+	}
+
+	live := 100
+	for i := 0; i < 100; i++ {
+		advance(time.Second)
+		want := 1
+		if i == 10 || i == 50 {
+			want = 0
+		}
+		if n := a.Purge(); n != want || a.Contains(i) != (want == 0) {
+			t.Fatalf("after %d seconds, Purge should remove %d element, removed %d", i+1, want, n)
+		}
+		live -= want
+	}
+	if a.Cardinality() != live || !a.Equal(NewSet(10, 50)) {
+		t.Errorf("only the postponed and the unexpiring elements should remain, got %v", a)
+	}
+
+	advance(200 * time.Second)
+	if !a.Equal(NewSet(50)) || len(a.deadlines) != 0 {
+		t.Errorf("only the unexpiring element should remain, got %v", a)
+	}
+}
+
+func Test_TTLSetOperations(t *testing.T) {
+	a, advance := newTestTTLSet(time.Minute)
+	a.AddAll(1, 2, 3)
+
+	a.Remove(1)
+	advance(time.Minute)
+	if n := a.Purge(); n != 2 {
+		t.Errorf("Purge should not count removed elements, removed %d", n)
+	}
+
+	a.AddAll(1, 2)
+	clone := a.Clone()
+	advance(time.Minute)
+	if clone.Cardinality() != 0 {
+		t.Errorf("the clone of a TTL set should keep the expiry of its elements, got %v", clone)
+	}
+
+	a.AddAll(1, 2)
+	a.ReplaceAll(3)
+	if !a.Equal(NewSet(3)) {
+		t.Errorf("ReplaceAll should leave only the given elements, got %v", a)
+	}
+	advance(time.Minute)
+	if items := a.Drain(); len(items) != 0 {
+		t.Errorf("Drain should not return expired elements, got %v", items)
+	}
+
+	NewSet(4, 5).CopyTo(a)
+	advance(time.Minute)
+	if _, ok := a.TryPop(); ok {
+		t.Error("elements copied into a TTL set should expire")
+	}
+
+	a.AddWithTTL(6, time.Hour)
+	a.ClearRetainingCapacity()
+	if a.Add(6); a.Purge() != 0 || !a.Contains(6) {
+		t.Error("ClearRetainingCapacity should drop the expiry of the cleared elements")
+	}
+
+	b := NewTTLSet(0)
+	b.Add(1)
+	if !b.Contains(1) {
+		t.Error("with a TTL of 0 elements should never expire")
+	}
+}
+
+func Test_TTLSetReadsSkipExpired(t *testing.T) {
+	a, advance := newTestTTLSet(time.Minute)
+	a.Add(1)
+	a.AddWithTTL(2, time.Hour)
+	advance(time.Minute)
+
+	isOne := func(i interface{}) bool { return i == 1 }
+	if a.ContainsBy(1, func(i interface{}) interface{} { return i }) || a.Any(isOne) || a.Count(isOne) != 0 {
+		t.Error("ContainsBy, Any and Count should not see expired elements")
+	}
+	a.Add(1)
+	advance(time.Minute)
+	if !a.All(func(i interface{}) bool { return i == 2 }) {
+		t.Error("All should not see expired elements")
+	}
+	a.Add(1)
+	advance(time.Minute)
+	if union := a.Union(NewSet(3)); !union.Equal(NewSet(2, 3)) {
+		t.Errorf("Union should not see expired elements, got %v", union)
+	}
+
+	a.Add(1)
+	advance(time.Minute)
+	if v, ok := a.TryPop(); !ok || v != 2 {
+		t.Errorf("TryPop should not pop an expired element, got %v", v)
+	}
+}
+
+func Test_TTLSetSelfArgument(t *testing.T) {
+	assertSelfArgument(t, "ttl", func() Set { return NewTTLSet(time.Hour) })
+
+	a, b := NewTTLSet(time.Hour), NewTTLSet(time.Hour)
+	a.AddAll(1, 2)
+	b.AddAll(2, 3)
+	if a.RetainAll(b) != 1 || !a.Equal(b.Intersect(a)) {
+		t.Errorf("RetainAll with another TTL set should keep the common elements, got %v", a)
+	}
+	if a.SubtractSet(b) != 1 || a.Cardinality() != 0 {
+		t.Errorf("SubtractSet with another TTL set should remove the common elements, got %v", a)
+	}
+}
+
+func Test_TTLSetConcurrent(t *testing.T) {
+	runtime.GOMAXPROCS(2)
+
+	s := NewTTLSet(time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < N; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Add(i)
+			s.Contains(i)
+			s.Cardinality()
+		}(i)
+	}
+	wg.Wait()
+
+	time.Sleep(2 * time.Millisecond)
+	if s.Cardinality() != 0 {
+		t.Errorf("every element should have expired, got %d", s.Cardinality())
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+)
+
+// setWrapper is embedded by the sets wrapping another one in place of the
+// wrapped Set itself. It passes reads and the encoders through to the
+// wrapped set and implements AddSet and the decoders on top of self, the
+// wrapping set: the elements are read or decoded first and only then
+// handed to its AddAll, so they go through its checks, such as a bound,
+// without any lock held meanwhile.
+//
+// It also gives the wrapped set itself as the argument of RetainAll and
+// SubtractSet when the wrapping set is given itself. The wrapped set
+// handles being its own argument, while reading the wrapping set under
+// the lock of the wrapped one would deadlock. Wrappers overriding those
+// two operations must take care of this themselves.
+type setWrapper struct {
+	Set
+	self Set
+}
+
+func (set *setWrapper) AddSet(other Set) int {
+	return set.self.AddAll(nonNil(other).ToSlice()...)
+}
+
+func (set *setWrapper) RetainAll(other Set) int {
+	if other == set.self {
+		other = set.Set
+	}
+	return set.Set.RetainAll(other)
+}
+
+func (set *setWrapper) SubtractSet(other Set) int {
+	if other == set.self {
+		other = set.Set
+	}
+	return set.Set.SubtractSet(other)
+}
+
+// addDecoded adds items to self unless decoding them failed.
+func (set *setWrapper) addDecoded(items []interface{}, err error) error {
+	if err != nil {
+		return err
+	}
+
+	set.self.AddAll(items...)
+	return nil
+}
+
+func (set *setWrapper) UnmarshalText(text []byte) error {
+	return set.addDecoded(unmarshalTextElements(text))
+}
+
+func (set *setWrapper) ReadCSV(r io.Reader) error {
+	return set.addDecoded(readCSVElements(r))
+}
+
+func (set *setWrapper) MarshalJSON() ([]byte, error) {
+	return json.Marshal(set.Set)
+}
+
+func (set *setWrapper) UnmarshalJSON(p []byte) error {
+	return set.addDecoded(unmarshalJSONElements(p))
+}
+
+func (set *setWrapper) UnmarshalJSONWith(b []byte, convertNumber func(json.Number) interface{}) error {
+	return set.addDecoded(unmarshalJSONElementsWith(b, convertNumber))
+}
+
+func (set *setWrapper) UnmarshalJSONInto(b []byte, prototype func() interface{}) error {
+	return set.addDecoded(unmarshalJSONElementsInto(b, prototype))
+}
+
+func (set *setWrapper) GobEncode() ([]byte, error) {
+	encoder, ok := set.Set.(gob.GobEncoder)
+	if !ok {
+		return nil, newTypeMismatchError("GobEncode", "a gob.GobEncoder", set.Set)
+	}
+	return encoder.GobEncode()
+}
+
+func (set *setWrapper) GobDecode(b []byte) error {
+	return set.addDecoded(gobDecodeElements(b))
+}
+
+func (set *setWrapper) MarshalBinary() ([]byte, error) {
+	marshaler, ok := set.Set.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, newTypeMismatchError("MarshalBinary", "an encoding.BinaryMarshaler", set.Set)
+	}
+	return marshaler.MarshalBinary()
+}
+
+func (set *setWrapper) UnmarshalBinary(data []byte) error {
+	return set.addDecoded(unmarshalBinaryElements(data))
+}
+
+func (set *setWrapper) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	marshaler, ok := set.Set.(xml.Marshaler)
+	if !ok {
+		return newTypeMismatchError("MarshalXML", "an xml.Marshaler", set.Set)
+	}
+	return marshaler.MarshalXML(e, start)
+}
+
+func (set *setWrapper) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return set.addDecoded(unmarshalXMLElements(d, start))
+}
@@ -0,0 +1,72 @@
+package mapset
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+// decodeAll decodes a set holding "a", "b" and "c" into each decoder of a
+// new set, returned by newSet, and returns the sets by decoder.
+func decodeAll(t *testing.T, newSet func() Set) map[string]Set {
+	t.Helper()
+
+	source := NewSet("a", "b", "c")
+	jsonBytes, _ := json.Marshal(source)
+	text, _ := source.MarshalText()
+	var csv bytes.Buffer
+	source.WriteCSV(&csv)
+	gobBytes, _ := source.(gob.GobEncoder).GobEncode()
+	binary, _ := source.(encoding.BinaryMarshaler).MarshalBinary()
+	xmlBytes, _ := xml.Marshal(source)
+
+	decoders := map[string]func(Set) error{
+		"UnmarshalJSON":     func(s Set) error { return json.Unmarshal(jsonBytes, s) },
+		"UnmarshalJSONWith": func(s Set) error { return s.UnmarshalJSONWith(jsonBytes, ConvertJSONNumber) },
+		"UnmarshalJSONInto": func(s Set) error {
+			return s.UnmarshalJSONInto(jsonBytes, func() interface{} { return new(string) })
+		},
+		"UnmarshalText":   func(s Set) error { return s.UnmarshalText(text) },
+		"ReadCSV":         func(s Set) error { return s.ReadCSV(bytes.NewReader(csv.Bytes())) },
+		"GobDecode":       func(s Set) error { return s.(gob.GobDecoder).GobDecode(gobBytes) },
+		"UnmarshalBinary": func(s Set) error { return s.(encoding.BinaryUnmarshaler).UnmarshalBinary(binary) },
+		"UnmarshalXML":    func(s Set) error { return xml.Unmarshal(xmlBytes, s) },
+	}
+
+	sets := make(map[string]Set)
+	for name, decode := range decoders {
+		s := newSet()
+		if err := decode(s); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		sets[name] = s
+	}
+	return sets
+}
+
+func Test_WrapperDecodersAddThroughWrapper(t *testing.T) {
+	for name, s := range decodeAll(t, func() Set { return NewLRUSet(2) }) {
+		if s.Cardinality() != 2 {
+			t.Errorf("%s on an LRU set should evict beyond its max, got %v", name, s)
+		}
+	}
+
+	clocks := make(map[Set]func(time.Duration))
+	for name, s := range decodeAll(t, func() Set {
+		set, advance := newTestTTLSet(time.Minute)
+		clocks[set] = advance
+		return set
+	}) {
+		if s.Cardinality() != 3 {
+			t.Errorf("%s on a TTL set should add every element, got %v", name, s)
+		}
+		clocks[s](time.Hour)
+		if s.Cardinality() != 0 {
+			t.Errorf("%s on a TTL set should add elements that expire, got %v", name, s)
+		}
+	}
+}
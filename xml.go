@@ -0,0 +1,202 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+// The XML form of a set, written by MarshalXML, is a set element holding
+// an item element per element of the set:
+//
+//	<set><item>a</item><item type="int">1</item></set>
+//
+// Only elements of a builtin boolean, numeric or string type can be
+// encoded. Strings are written as is, other elements carry their Go type
+// in a type attribute, so that they decode to a value of the same type.
+// When a set is a field of a struct, the element is named after the field
+// instead, and the item elements are the same.
+
+// xmlTypeNames are the names of the set types, which encoding/xml uses to
+// name the element of a set encoded on its own.
+var xmlTypeNames = map[string]bool{
+	"threadSafeSet":   true,
+	"threadUnsafeSet": true,
+	"shardedSet":      true,
+	"orderedSet":      true,
+	"frozenSet":       true,
+	"boundedSet":      true,
+	"lruSet":          true,
+	"keyedSet":        true,
+	"observedSet":     true,
+	"ttlSet":          true,
+	"hashedSet":       true,
+	"nonNilSet":       true,
+}
+
+type xmlItem struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+func marshalXMLElements(e *xml.Encoder, start xml.StartElement, items []interface{}) error {
+	if start.Name.Space == "" && xmlTypeNames[start.Name.Local] {
+		start.Name.Local = "set"
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	itemStart := xml.StartElement{Name: xml.Name{Local: "item"}}
+	for _, elem := range items {
+		item, err := newXMLItem(elem)
+		if err != nil {
+			return err
+		}
+		if err := e.EncodeElement(item, itemStart); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+func newXMLItem(elem interface{}) (xmlItem, error) {
+	switch v := elem.(type) {
+	case string:
+		return xmlItem{Value: v}, nil
+	case bool:
+		return xmlItem{Type: "bool", Value: strconv.FormatBool(v)}, nil
+	case int:
+		return xmlItem{Type: "int", Value: strconv.FormatInt(int64(v), 10)}, nil
+	case int8:
+		return xmlItem{Type: "int8", Value: strconv.FormatInt(int64(v), 10)}, nil
+	case int16:
+		return xmlItem{Type: "int16", Value: strconv.FormatInt(int64(v), 10)}, nil
+	case int32:
+		return xmlItem{Type: "int32", Value: strconv.FormatInt(int64(v), 10)}, nil
+	case int64:
+		return xmlItem{Type: "int64", Value: strconv.FormatInt(v, 10)}, nil
+	case uint:
+		return xmlItem{Type: "uint", Value: strconv.FormatUint(uint64(v), 10)}, nil
+	case uint8:
+		return xmlItem{Type: "uint8", Value: strconv.FormatUint(uint64(v), 10)}, nil
+	case uint16:
+		return xmlItem{Type: "uint16", Value: strconv.FormatUint(uint64(v), 10)}, nil
+	case uint32:
+		return xmlItem{Type: "uint32", Value: strconv.FormatUint(uint64(v), 10)}, nil
+	case uint64:
+		return xmlItem{Type: "uint64", Value: strconv.FormatUint(v, 10)}, nil
+	case float32:
+		return xmlItem{Type: "float32", Value: strconv.FormatFloat(float64(v), 'g', -1, 32)}, nil
+	case float64:
+		return xmlItem{Type: "float64", Value: strconv.FormatFloat(v, 'g', -1, 64)}, nil
+	}
+
+	return xmlItem{}, newTypeMismatchError("MarshalXML", "a boolean, numeric or string element", elem)
+}
+
+// value converts the item back to an element of the type it names.
+func (item xmlItem) value() (interface{}, error) {
+	switch item.Type {
+	case "", "string":
+		return item.Value, nil
+	case "bool":
+		return strconv.ParseBool(item.Value)
+	case "int":
+		v, err := strconv.ParseInt(item.Value, 10, strconv.IntSize)
+		return int(v), err
+	case "int8":
+		v, err := strconv.ParseInt(item.Value, 10, 8)
+		return int8(v), err
+	case "int16":
+		v, err := strconv.ParseInt(item.Value, 10, 16)
+		return int16(v), err
+	case "int32":
+		v, err := strconv.ParseInt(item.Value, 10, 32)
+		return int32(v), err
+	case "int64":
+		return strconv.ParseInt(item.Value, 10, 64)
+	case "uint":
+		v, err := strconv.ParseUint(item.Value, 10, strconv.IntSize)
+		return uint(v), err
+	case "uint8":
+		v, err := strconv.ParseUint(item.Value, 10, 8)
+		return uint8(v), err
+	case "uint16":
+		v, err := strconv.ParseUint(item.Value, 10, 16)
+		return uint16(v), err
+	case "uint32":
+		v, err := strconv.ParseUint(item.Value, 10, 32)
+		return uint32(v), err
+	case "uint64":
+		return strconv.ParseUint(item.Value, 10, 64)
+	case "float32":
+		v, err := strconv.ParseFloat(item.Value, 32)
+		return float32(v), err
+	case "float64":
+		return strconv.ParseFloat(item.Value, 64)
+	}
+
+	return nil, fmt.Errorf("mapset: unknown XML item type %q", item.Type)
+}
+
+// unmarshalXMLElements decodes the item elements inside start, skipping
+// any other element.
+func unmarshalXMLElements(d *xml.Decoder, start xml.StartElement) ([]interface{}, error) {
+	var items []interface{}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "item" {
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			var item xmlItem
+			if err := d.DecodeElement(&item, &t); err != nil {
+				return nil, err
+			}
+			elem, err := item.value()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, elem)
+		case xml.EndElement:
+			return items, nil
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package mapset
+
+import (
+	"encoding/xml"
+	"math"
+	"testing"
+)
+
+func Test_XML(t *testing.T) {
+	constructors := map[string]func(...interface{}) Set{
+		"bounded": func(i ...interface{}) Set { s := NewBoundedSet(100); s.AddAll(i...); return s },
+		"lru":     func(i ...interface{}) Set { s := NewLRUSet(100); s.AddAll(i...); return s },
+	}
+	for name, newSet := range setConstructors {
+		constructors[name] = newSet
+	}
+
+	for name, newSet := range constructors {
+		for _, items := range [][]interface{}{
+			{},
+			{"a", "", "<b & c>"},
+			{true, 1, int8(-8), int16(16), int32(-32), int64(math.MinInt64), uint(1), uint8(8),
+				uint16(16), uint32(32), uint64(math.MaxUint64), float32(1.1), 0.1, "1"},
+		} {
+			expected := newSet(items...)
+			b, err := xml.Marshal(expected)
+			if err != nil {
+				t.Fatalf("%s: Marshal(%v): %v", name, items, err)
+			}
+
+			actual := newSet()
+			if err := xml.Unmarshal(b, actual); err != nil {
+				t.Fatalf("%s: Unmarshal(%s): %v", name, b, err)
+			}
+			if !actual.Equal(expected) {
+				t.Errorf("%s: expected %v, got %v from %s", name, expected, actual, b)
+			}
+		}
+	}
+}
+
+func Test_XMLForm(t *testing.T) {
+	b, err := xml.Marshal(NewOrderedSet("a", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<set><item>a</item><item type="int">1</item></set>`; string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+
+	b, err = xml.Marshal(NewSet().Freeze())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<set></set>`; string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+
+	type config struct {
+		XMLName xml.Name `xml:"config"`
+		Hosts   Set      `xml:"hosts"`
+	}
+	b, err = xml.Marshal(config{Hosts: NewOrderedSet("a", "b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<config><hosts><item>a</item><item>b</item></hosts></config>`; string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+
+	decoded := config{Hosts: NewSet()}
+	if err := xml.Unmarshal(b, &decoded); err != nil || !decoded.Hosts.Equal(NewSet("a", "b")) {
+		t.Errorf("a set field should decode, got %v and error %v", decoded.Hosts, err)
+	}
+}
+
+func Test_XMLInvalid(t *testing.T) {
+	if _, err := xml.Marshal(NewSet(complex(1, 2))); err == nil {
+		t.Error("encoding a non-primitive element should fail")
+	}
+
+	for _, text := range []string{
+		`<set><item type="int">a</item></set>`,
+		`<set><item type="int8">300</item></set>`,
+		`<set><item type="map">a</item></set>`,
+		`<set><item>a</item>`,
+	} {
+		s := NewSet()
+		if err := xml.Unmarshal([]byte(text), s); err == nil {
+			t.Errorf("Unmarshal(%s) should fail, got %v", text, s)
+		}
+	}
+}